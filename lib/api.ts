@@ -4,6 +4,7 @@ const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080"
 export interface PackResult {
   packSize: number
   quantity: number
+  label?: string
 }
 
 export interface OptimizationResult {
@@ -12,6 +13,98 @@ export interface OptimizationResult {
   totalPacks: number
   packs: PackResult[]
   waste: number
+  shortfall?: number
+  bumpedTo?: number
+  totalCost?: number
+  totalVolume?: number
+  totalSlots?: number
+  currency?: string
+  score?: number
+  savings?: Savings
+  diagnostics?: Diagnostics
+  upsell?: Upsell
+  tolerances?: ToleranceResult[]
+  alternatives?: OptimizationResult[]
+  alternativesTruncated?: boolean
+  upgradePath?: UpgradeStep[]
+  objectives?: Objectives
+  bindingConstraint?: "none" | "maxPacks" | "maxWastePerSize" | "maxWaste" | "maxQuantity" | "maxSlots" | "budget"
+  alreadyShipped?: number
+  overShipped?: number
+  uniformity?: Uniformity
+  warnings?: string[]
+  request?: Record<string, unknown>
+  lesson?: Lesson
+  ranges?: ItemRange[]
+  permalink?: string
+  serverTime?: string
+}
+
+export interface ItemRange {
+  packSize: number
+  startIndex: number
+  endIndex: number
+}
+
+export interface UpgradeStep {
+  quantity: number
+  result?: OptimizationResult
+  error?: string
+  changed: boolean
+}
+
+export interface Uniformity {
+  dominantSize: number
+  dominantShare: number
+}
+
+export interface Lesson {
+  greedy: LessonStep[]
+  optimal: LessonStep[]
+  divergesAt: number
+  greedyPacks: number
+  greedyWaste: number
+}
+
+export interface LessonStep {
+  packSize: number
+  quantity: number
+  filled: number
+  remaining: number
+}
+
+export interface Objectives {
+  minWaste: OptimizationResult
+  minPacks: OptimizationResult
+  equivalent: boolean
+}
+
+export interface ToleranceResult {
+  tolerance: number
+  maxWaste: number
+  result: OptimizationResult | null
+}
+
+export interface Upsell {
+  quantity: number
+  delta: number
+  totalPacks: number
+}
+
+export interface Diagnostics {
+  seed?: number
+  coOptimal?: number
+  windowOffset?: number
+  windowMax?: number
+  windowEdge?: boolean
+}
+
+export interface Savings {
+  naivePacks: number
+  naiveWaste: number
+  wasteAvoided: number
+  packsSaved: number
+  costSaved?: number
 }
 
 export class ApiError extends Error {
@@ -24,6 +117,20 @@ export class ApiError extends Error {
   }
 }
 
+// Extract the message from the API's JSON error envelope, falling back to the raw body
+async function readErrorMessage(response: Response): Promise<string> {
+  const text = await response.text()
+  try {
+    const body = JSON.parse(text)
+    if (typeof body?.error === "string") {
+      return body.error
+    }
+  } catch {
+    // Not an error envelope
+  }
+  return text
+}
+
 // Check if Go API server is running
 export async function checkApiHealth(): Promise<boolean> {
   try {
@@ -51,7 +158,7 @@ export async function optimizePacks(quantity: number): Promise<OptimizationResul
     })
 
     if (!response.ok) {
-      const errorText = await response.text()
+      const errorText = await readErrorMessage(response)
       throw new ApiError(`API Error: ${errorText}`, response.status)
     }
 
@@ -105,7 +212,7 @@ export async function setPackSizes(packSizes: number[]): Promise<boolean> {
     });
 
     if (!response.ok) {
-      const errorText = await response.text();
+      const errorText = await readErrorMessage(response);
       throw new ApiError(`API Error: ${errorText}`, response.status);
     }
 
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// accessLog writes one structured line per request to stdout.
+var accessLog = newAccessLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil)), envInt("LOG_SAMPLE_RATE", 1)).
+	withNoLogSources(envNetworks("NO_LOG_TRUSTED_SOURCES"))
+
+// noLogHeader asks for a request to be left out of the access log. It is
+// honored only from the logger's trusted sources.
+const noLogHeader = "X-No-Log"
+
+// accessLogger logs requests as structured records. Successful requests are
+// sampled at 1 in sampleRate, and skipped entirely when a trusted source
+// sends noLogHeader; error responses (status >= 400) are always logged.
+type accessLogger struct {
+	logger       *slog.Logger
+	sampleRate   int
+	seen         atomic.Uint64
+	noLogSources []*net.IPNet
+}
+
+func newAccessLogger(logger *slog.Logger, sampleRate int) *accessLogger {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	return &accessLogger{logger: logger, sampleRate: sampleRate}
+}
+
+// withNoLogSources sets the networks allowed to opt out of logging.
+func (l *accessLogger) withNoLogSources(networks []*net.IPNet) *accessLogger {
+	l.noLogSources = networks
+	return l
+}
+
+func (l *accessLogger) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status < http.StatusBadRequest && l.optedOut(r) {
+			return
+		}
+		if !l.sample(rec.status) {
+			return
+		}
+		l.logger.Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"durationMs", time.Since(start).Milliseconds(),
+			"remote", clientIP(r),
+		)
+	})
+}
+
+// sample reports whether a response with the given status should be logged.
+func (l *accessLogger) sample(status int) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	return (l.seen.Add(1)-1)%uint64(l.sampleRate) == 0
+}
+
+// optedOut reports whether r asks not to be logged from a trusted source.
+func (l *accessLogger) optedOut(r *http.Request) bool {
+	if r.Header.Get(noLogHeader) != "true" {
+		return false
+	}
+	ip := net.ParseIP(clientIP(r))
+	for _, network := range l.noLogSources {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRecorder captures the status code and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
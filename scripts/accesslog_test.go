@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLoggerSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil)), 10)
+	handler := logger.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+
+	for i := 0; i < 1000; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	}
+	if got := strings.Count(buf.String(), `"status":200`); got < 90 || got > 110 {
+		t.Errorf("logged %d of 1000 successful requests at rate 1/10, want about 100", got)
+	}
+
+	buf.Reset()
+	for i := 0; i < 50; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	}
+	if got := strings.Count(buf.String(), `"status":500`); got != 50 {
+		t.Errorf("logged %d of 50 error responses, want all 50", got)
+	}
+}
+
+func TestAccessLoggerRecordsFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil)), 1)
+	handler := logger.wrap(http.HandlerFunc(optimizeHandler))
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, want := range []string{`"method":"POST"`, `"path":"/optimize"`, `"status":200`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log line %q missing %s", buf.String(), want)
+		}
+	}
+}
+
+func TestAccessLoggerNoLogHeader(t *testing.T) {
+	t.Setenv("TEST_NO_LOG_SOURCES", "10.0.0.0/8, 192.168.1.5, bogus")
+	var buf bytes.Buffer
+	logger := newAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil)), 1).
+		withNoLogSources(envNetworks("TEST_NO_LOG_SOURCES"))
+	handler := logger.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+
+	testCases := []struct {
+		description string
+		remote      string
+		path        string
+		header      string
+		wantLogged  bool
+	}{
+		{"trusted network opts out", "10.1.2.3:1234", "/ok", "true", false},
+		{"trusted address opts out", "192.168.1.5:1234", "/ok", "true", false},
+		{"untrusted source is logged", "192.168.1.6:1234", "/ok", "true", true},
+		{"trusted source without header is logged", "10.1.2.3:1234", "/ok", "", true},
+		{"errors are always logged", "10.1.2.3:1234", "/fail", "true", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.RemoteAddr = tc.remote
+			if tc.header != "" {
+				req.Header.Set(noLogHeader, tc.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if logged := buf.Len() > 0; logged != tc.wantLogged {
+				t.Errorf("logged = %v, want %v: %q", logged, tc.wantLogged, buf.String())
+			}
+		})
+	}
+}
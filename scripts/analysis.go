@@ -0,0 +1,698 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// exactSubsetSearchLimit is the largest candidate pool searched exhaustively
+// by minimalZeroWasteCatalog; larger pools fall back to a greedy search.
+const exactSubsetSearchLimit = 12
+
+// maxZeroWasteCandidates bounds the candidate pool of a zero-waste search,
+// whose greedy fallback builds a table per candidate for every size it adds.
+const maxZeroWasteCandidates = 64
+
+// reachableTable reports, for every amount 0..maxSize, whether some
+// combination of sizes sums to it exactly.
+func reachableTable(sizes []int, maxSize int) []bool {
+	reachable, _ := fillReachableTable(context.Background(), sizes, maxSize)
+	return reachable
+}
+
+// fillReachableTable is reachableTable, giving up once ctx is done.
+func fillReachableTable(ctx context.Context, sizes []int, maxSize int) ([]bool, error) {
+	reachable := make([]bool, maxSize+1)
+	reachable[0] = true
+	for i := 0; i <= maxSize; i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := stopped(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if !reachable[i] {
+			continue
+		}
+		for _, size := range sizes {
+			if i+size <= maxSize {
+				reachable[i+size] = true
+			}
+		}
+	}
+	return reachable, nil
+}
+
+// fillsExactly reports whether every quantity is reachable with sizes.
+func fillsExactly(ctx context.Context, sizes []int, quantities []int, maxQuantity int) (bool, error) {
+	reachable, err := fillReachableTable(ctx, sizes, maxQuantity)
+	if err != nil {
+		return false, err
+	}
+	for _, q := range quantities {
+		if !reachable[q] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ZeroWasteCatalog is the answer to a zero-waste catalog search.
+type ZeroWasteCatalog struct {
+	Found     bool   `json:"found"`
+	PackSizes []int  `json:"packSizes"`
+	Exact     bool   `json:"exact"`
+	Note      string `json:"note,omitempty"`
+}
+
+// minimalZeroWasteCatalog finds a smallest subset of candidates that fills
+// every quantity with zero waste. Pools of up to exactSubsetSearchLimit sizes
+// are searched exhaustively by subset size; larger pools are built greedily by
+// repeatedly adding the candidate that makes the most quantities exactly
+// fillable, which may not be minimal. The search gives up once ctx is done.
+func minimalZeroWasteCatalog(ctx context.Context, quantities, candidates []int) (ZeroWasteCatalog, error) {
+	maxQuantity := slices.Max(quantities)
+	pool := slices.Clone(candidates)
+	slices.Sort(pool)
+
+	if len(pool) <= exactSubsetSearchLimit {
+		for k := 1; k <= len(pool); k++ {
+			var found []int
+			var err error
+			forEachSubset(len(pool), k, func(indexes []int) {
+				if found != nil || err != nil {
+					return
+				}
+				subset := make([]int, len(indexes))
+				for i, idx := range indexes {
+					subset[i] = pool[idx]
+				}
+				var fills bool
+				if fills, err = fillsExactly(ctx, subset, quantities, maxQuantity); fills {
+					found = subset
+				}
+			})
+			if err != nil {
+				return ZeroWasteCatalog{}, err
+			}
+			if found != nil {
+				return ZeroWasteCatalog{Found: true, PackSizes: found, Exact: true}, nil
+			}
+		}
+		return ZeroWasteCatalog{Exact: true, Note: "no subset of the candidates fills every quantity exactly"}, nil
+	}
+
+	var chosen []int
+	for {
+		bestCount, bestSize := -1, 0
+		for _, size := range pool {
+			if slices.Contains(chosen, size) {
+				continue
+			}
+			reachable, err := fillReachableTable(ctx, append(slices.Clone(chosen), size), maxQuantity)
+			if err != nil {
+				return ZeroWasteCatalog{}, err
+			}
+			count := 0
+			for _, q := range quantities {
+				if reachable[q] {
+					count++
+				}
+			}
+			if count > bestCount {
+				bestCount, bestSize = count, size
+			}
+		}
+		if bestCount < 0 {
+			break
+		}
+		chosen = append(chosen, bestSize)
+		if bestCount == len(quantities) {
+			slices.Sort(chosen)
+			return ZeroWasteCatalog{
+				Found:     true,
+				PackSizes: chosen,
+				Note:      fmt.Sprintf("greedy search over %d candidates; the subset may not be minimal", len(pool)),
+			}, nil
+		}
+	}
+	return ZeroWasteCatalog{Note: "no subset of the candidates fills every quantity exactly"}, nil
+}
+
+// HTTP handler searching for a minimal zero-waste catalog
+func zeroWasteHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Quantities []int `json:"quantities"`
+		Candidates []int `json:"candidates"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateQuantities(request.Quantities); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(request.Candidates) == 0 {
+		writeError(w, "candidates must contain at least one pack size", http.StatusBadRequest)
+		return
+	}
+	if len(request.Candidates) > maxZeroWasteCandidates {
+		writeError(w, fmt.Sprintf("At most %d candidates can be searched", maxZeroWasteCandidates), http.StatusBadRequest)
+		return
+	}
+	if err := validatePackSizes(request.Candidates); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if largest := slices.Max(request.Quantities); largest >= maxDPSize {
+		writeError(w, fmt.Sprintf("%v: %d items needed, limit is %d", ErrWindowTooLarge, uint(largest)+1, maxDPSize), http.StatusBadRequest)
+		return
+	}
+
+	catalog, err := minimalZeroWasteCatalog(r.Context(), request.Quantities, request.Candidates)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalog)
+}
+
+// validateQuantities checks the representative order quantities of an
+// analysis request.
+func validateQuantities(quantities []int) error {
+	if len(quantities) == 0 {
+		return fmt.Errorf("quantities must contain at least one quantity")
+	}
+	for _, q := range quantities {
+		if q <= 0 {
+			return fmt.Errorf("Quantities must be positive")
+		}
+	}
+	return nil
+}
+
+// PackSizeImpact is the waste a set of orders would gain if one pack size
+// were retired from the catalog.
+type PackSizeImpact struct {
+	PackSize      int    `json:"packSize"`
+	Label         string `json:"label,omitempty"`
+	TotalWaste    int    `json:"totalWaste"`
+	WasteIncrease int    `json:"wasteIncrease"`
+}
+
+// CatalogImpact is the answer to a pack size impact analysis.
+type CatalogImpact struct {
+	BaselineWaste int              `json:"baselineWaste"`
+	Impacts       []PackSizeImpact `json:"impacts"`
+}
+
+// overfillWaste sums the waste of filling every quantity with sizes, which
+// must be sorted in descending order, using one DP table for all of them.
+func overfillWaste(ctx context.Context, quantities, sizes []int) (int, error) {
+	largest := slices.Max(quantities)
+	maxSize := largest + sizes[0]
+	if maxSize > maxDPSize {
+		return 0, windowTooLarge(largest, maxSize)
+	}
+	dp, err := fillPackTable(ctx, sizes, maxSize)
+	if err != nil {
+		return 0, err
+	}
+	waste := 0
+	for _, q := range quantities {
+		waste += selectAmount(dp, q, PolicyOverfill, TiebreakOver) - q
+	}
+	return waste, nil
+}
+
+// catalogImpact reruns the quantities without each of sizes in turn and
+// reports how much total waste each removal adds. Waste is measured under the
+// overfill policy so every quantity stays fillable.
+func catalogImpact(ctx context.Context, quantities, sizes []int, labels map[int]string) (CatalogImpact, error) {
+	sizes = sortedDescending(sizes)
+	baseline, err := overfillWaste(ctx, quantities, sizes)
+	if err != nil {
+		return CatalogImpact{}, err
+	}
+
+	impact := CatalogImpact{BaselineWaste: baseline, Impacts: []PackSizeImpact{}}
+	for i, size := range sizes {
+		remaining := slices.Delete(slices.Clone(sizes), i, i+1)
+		waste, err := overfillWaste(ctx, quantities, remaining)
+		if err != nil {
+			return CatalogImpact{}, err
+		}
+		impact.Impacts = append(impact.Impacts, PackSizeImpact{
+			PackSize:      size,
+			Label:         labels[size],
+			TotalWaste:    waste,
+			WasteIncrease: waste - baseline,
+		})
+	}
+	return impact, nil
+}
+
+// HTTP handler reporting the waste impact of retiring each pack size
+func impactHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Quantities []int `json:"quantities"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateQuantities(request.Quantities); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	catalog, _ := namedCatalogs.lookup(defaultCatalogName)
+	if len(catalog.PackSizes) < 2 {
+		writeError(w, "Impact analysis needs at least two configured pack sizes", http.StatusBadRequest)
+		return
+	}
+
+	impact, err := catalogImpact(r.Context(), request.Quantities, catalog.PackSizes, catalog.Labels)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(impact)
+}
+
+// maxRecommendQuantities bounds the distinct quantities clustered by one
+// recommendation, which takes O(k·n²) time.
+const maxRecommendQuantities = 2000
+
+// CatalogRecommendation is the answer to a catalog recommendation: the
+// recommended sizes and the waste they and the current catalog leave on the
+// given quantities.
+type CatalogRecommendation struct {
+	PackSizes    []int `json:"packSizes"`
+	Waste        int   `json:"waste"`
+	CurrentWaste int   `json:"currentWaste"`
+}
+
+// recommendSizes picks k pack sizes minimizing the waste of serving every
+// quantity with a single pack: each quantity takes the smallest chosen size
+// at or above it. That is k-median clustering on the line with one-sided
+// distances, solved exactly by dynamic programming over the sorted distinct
+// quantities; the best size for a cluster is its largest quantity. Fewer
+// than k distinct quantities are returned as they are.
+func recommendSizes(quantities []int, k int) []int {
+	counts := make(map[int]int)
+	for _, q := range quantities {
+		counts[q]++
+	}
+	values := make([]int, 0, len(counts))
+	for q := range counts {
+		values = append(values, q)
+	}
+	slices.Sort(values)
+	n := len(values)
+	if k >= n {
+		return values
+	}
+
+	// weight[i] and sum[i] are the quantity counts and items of values[:i].
+	weight := make([]int, n+1)
+	sum := make([]int, n+1)
+	for i, v := range values {
+		weight[i+1] = weight[i] + counts[v]
+		sum[i+1] = sum[i] + counts[v]*v
+	}
+	// cost is the waste of serving values[i..j] with a pack of values[j].
+	cost := func(i, j int) int {
+		return values[j]*(weight[j+1]-weight[i]) - (sum[j+1] - sum[i])
+	}
+
+	// best[c][j] is the least waste serving values[..j] with c sizes, the
+	// largest being values[j]; cut[c][j] is where its cluster starts.
+	best := make([][]int, k+1)
+	cut := make([][]int, k+1)
+	for c := 1; c <= k; c++ {
+		best[c] = make([]int, n)
+		cut[c] = make([]int, n)
+		for j := c - 1; j < n; j++ {
+			if c == 1 {
+				best[c][j] = cost(0, j)
+				continue
+			}
+			best[c][j] = math.MaxInt
+			for i := c - 1; i <= j; i++ {
+				if waste := best[c-1][i-1] + cost(i, j); waste < best[c][j] {
+					best[c][j], cut[c][j] = waste, i
+				}
+			}
+		}
+	}
+
+	sizes := make([]int, k)
+	for c, j := k, n-1; c >= 1; c-- {
+		sizes[c-1] = values[j]
+		j = cut[c][j] - 1
+	}
+	return sizes
+}
+
+// HTTP handler recommending a catalog of k sizes for historical orders
+func recommendHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Quantities []int `json:"quantities"`
+		K          int   `json:"k"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateQuantities(request.Quantities); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.K <= 0 {
+		writeError(w, "k must be positive", http.StatusBadRequest)
+		return
+	}
+	distinct := make(map[int]bool)
+	for _, q := range request.Quantities {
+		distinct[q] = true
+	}
+	if len(distinct) > maxRecommendQuantities {
+		writeError(w, fmt.Sprintf("At most %d distinct quantities can be clustered", maxRecommendQuantities), http.StatusBadRequest)
+		return
+	}
+
+	recommendation := CatalogRecommendation{PackSizes: recommendSizes(request.Quantities, request.K)}
+	var err error
+	if recommendation.Waste, err = overfillWaste(r.Context(), request.Quantities, sortedDescending(recommendation.PackSizes)); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	current, _ := namedCatalogs.lookup(defaultCatalogName)
+	if recommendation.CurrentWaste, err = overfillWaste(r.Context(), request.Quantities, sortedDescending(current.PackSizes)); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recommendation)
+}
+
+// minimumWaste returns the least waste any mix of sizes, which must be sorted
+// in descending order, can leave on orderQuantity: the distance to the
+// smallest reachable total at or above it. Only reachability is tracked, so
+// this is cheaper than a full solve.
+func minimumWaste(ctx context.Context, orderQuantity int, sizes []int) (int, error) {
+	maxSize, err := searchWindow(orderQuantity, sizes, OptimizeOptions{})
+	if err != nil {
+		return 0, err
+	}
+	reachable, err := fillReachableTable(ctx, sizes, maxSize)
+	if err != nil {
+		return 0, err
+	}
+	for total := orderQuantity; total <= maxSize; total++ {
+		if reachable[total] {
+			return total - orderQuantity, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: order %d has no reachable total", ErrInfeasible, orderQuantity)
+}
+
+// HTTP handler returning the minimum possible waste for an order
+func minWasteHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	quantity, err := strconv.Atoi(r.URL.Query().Get("quantity"))
+	if err != nil || quantity <= 0 {
+		writeError(w, "quantity must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	catalogName := r.URL.Query().Get("catalog")
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", catalogName), http.StatusNotFound)
+		return
+	}
+	if len(catalog.PackSizes) == 0 {
+		writeError(w, "catalog has no pack sizes", http.StatusBadRequest)
+		return
+	}
+
+	waste, err := minimumWaste(r.Context(), quantity, sortedDescending(catalog.PackSizes))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := struct {
+		Quantity int `json:"quantity"`
+		MinWaste int `json:"minWaste"`
+	}{
+		Quantity: quantity,
+		MinWaste: waste,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CatalogCoverage is how many order quantities a catalog fills exactly;
+// Coverage is the share as a percentage.
+type CatalogCoverage struct {
+	Max      int     `json:"max"`
+	Fillable int     `json:"fillable"`
+	Coverage float64 `json:"coverage"`
+}
+
+// zeroWasteCoverage counts the quantities in [1, upTo] that sizes fill with
+// zero waste, using the same reachability table as minimumWaste.
+func zeroWasteCoverage(ctx context.Context, sizes []int, upTo int) (CatalogCoverage, error) {
+	reachable, err := fillReachableTable(ctx, sizes, upTo)
+	if err != nil {
+		return CatalogCoverage{}, err
+	}
+	fillable := 0
+	for q := 1; q <= upTo; q++ {
+		if reachable[q] {
+			fillable++
+		}
+	}
+	return CatalogCoverage{Max: upTo, Fillable: fillable, Coverage: percentage(fillable, upTo)}, nil
+}
+
+// HTTP handler reporting the share of quantities a catalog fills exactly
+func coverageHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	upTo, err := strconv.Atoi(r.URL.Query().Get("max"))
+	if err != nil || upTo <= 0 {
+		writeError(w, "max must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if upTo > maxDPSize {
+		writeError(w, fmt.Sprintf("%v: %d items needed, limit is %d", ErrWindowTooLarge, upTo, maxDPSize), http.StatusBadRequest)
+		return
+	}
+	catalogName := r.URL.Query().Get("catalog")
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", catalogName), http.StatusNotFound)
+		return
+	}
+	if len(catalog.PackSizes) == 0 {
+		writeError(w, "catalog has no pack sizes", http.StatusBadRequest)
+		return
+	}
+
+	coverage, err := zeroWasteCoverage(r.Context(), catalog.PackSizes, upTo)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coverage)
+}
+
+// CatalogWorstWaste is the order quantity a catalog serves worst: the
+// largest one, up to Max, whose least-waste fill wastes the most, and how
+// many quantities in the range waste as much.
+type CatalogWorstWaste struct {
+	Max         int `json:"max"`
+	Quantity    int `json:"quantity"`
+	Waste       int `json:"waste"`
+	Occurrences int `json:"occurrences"`
+}
+
+// worstWaste finds the largest quantity in [1, upTo] with the largest
+// minimum waste against sizes. Adding the smallest size to any reachable
+// total reaches another, so waste never exceeds the smallest size minus one
+// and quantity 1 always reaches it; the largest such quantity shows how far
+// up the range the weak spot persists. One reachability table covers the
+// whole range: each quantity's least-waste total is the next reachable one,
+// found by scanning down from upTo plus the largest size.
+func worstWaste(ctx context.Context, sizes []int, upTo int) (CatalogWorstWaste, error) {
+	maxSize := upTo + slices.Max(sizes)
+	reachable, err := fillReachableTable(ctx, sizes, maxSize)
+	if err != nil {
+		return CatalogWorstWaste{}, err
+	}
+	next := maxSize
+	worst := CatalogWorstWaste{Max: upTo}
+	for q := maxSize; q >= 1; q-- {
+		if reachable[q] {
+			next = q
+		}
+		if q > upTo {
+			continue
+		}
+		switch waste := next - q; {
+		case worst.Occurrences == 0 || waste > worst.Waste:
+			worst.Quantity, worst.Waste, worst.Occurrences = q, waste, 1
+		case waste == worst.Waste:
+			worst.Occurrences++
+		}
+	}
+	return worst, nil
+}
+
+// HTTP handler reporting the quantity a catalog fills with the most waste
+func worstWasteHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	upTo, err := strconv.Atoi(r.URL.Query().Get("max"))
+	if err != nil || upTo <= 0 {
+		writeError(w, "max must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	catalogName := r.URL.Query().Get("catalog")
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", catalogName), http.StatusNotFound)
+		return
+	}
+	if len(catalog.PackSizes) == 0 {
+		writeError(w, "catalog has no pack sizes", http.StatusBadRequest)
+		return
+	}
+	if maxSize := upTo + slices.Max(catalog.PackSizes); maxSize > maxDPSize {
+		writeError(w, windowTooLarge(upTo, maxSize).Error(), http.StatusBadRequest)
+		return
+	}
+
+	worst, err := worstWaste(r.Context(), catalog.PackSizes, upTo)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(worst)
+}
+
+// wasteRedundantSizes returns the sizes that other sizes of the catalog can
+// sum to exactly. Every size is the fewest-pack answer for its own quantity,
+// so none is dominated outright, but these never lower waste: any total they
+// reach is reachable without them, so they only save packs.
+func wasteRedundantSizes(sizes []int) []int {
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+	var redundant []int
+	for i, size := range sorted {
+		if i > 0 && reachableTable(sorted[:i], size)[size] {
+			redundant = append(redundant, size)
+		}
+	}
+	return redundant
+}
+
+// catalogWarnings describes sizes of a catalog an operator may want to
+// retire. They are advisory; the catalog is still valid.
+func catalogWarnings(sizes []int) []string {
+	var warnings []string
+	if len(sizes) == 1 {
+		warnings = append(warnings, singleSizeWarning(sizes[0]))
+	}
+	for _, size := range wasteRedundantSizes(sizes) {
+		warnings = append(warnings, fmt.Sprintf("Pack size %d is dominated for waste: smaller sizes fill every total it does, so it only saves packs", size))
+	}
+	return warnings
+}
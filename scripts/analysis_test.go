@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMinimalZeroWasteCatalog(t *testing.T) {
+	testCases := []struct {
+		quantities  []int
+		candidates  []int
+		want        ZeroWasteCatalog
+		description string
+	}{
+		{
+			[]int{500, 750, 1250}, []int{100, 250, 500, 1000},
+			ZeroWasteCatalog{Found: true, PackSizes: []int{250}, Exact: true},
+			"Single size covers all",
+		},
+		{
+			[]int{300, 700, 1200}, []int{200, 300, 400, 500},
+			ZeroWasteCatalog{Found: true, PackSizes: []int{200, 300}, Exact: true},
+			"Pair needed",
+		},
+		{
+			[]int{7}, []int{2, 4},
+			ZeroWasteCatalog{Exact: true, Note: "no subset of the candidates fills every quantity exactly"},
+			"Impossible",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := minimalZeroWasteCatalog(context.Background(), tc.quantities, tc.candidates)
+			if err != nil {
+				t.Fatalf("minimalZeroWasteCatalog(%v, %v) returned error: %v", tc.quantities, tc.candidates, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("minimalZeroWasteCatalog(%v, %v) = %+v, want %+v", tc.quantities, tc.candidates, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMinimalZeroWasteCatalogGreedyForLargePools(t *testing.T) {
+	candidates := make([]int, 0, exactSubsetSearchLimit+3)
+	for size := 7; len(candidates) < cap(candidates); size++ {
+		candidates = append(candidates, size)
+	}
+
+	got, err := minimalZeroWasteCatalog(context.Background(), []int{14, 21, 35}, candidates)
+	if err != nil {
+		t.Fatalf("greedy search returned error: %v", err)
+	}
+	if !got.Found || got.Exact || !reflect.DeepEqual(got.PackSizes, []int{7}) {
+		t.Errorf("greedy search = %+v, want an approximate [7]", got)
+	}
+	if got.Note == "" {
+		t.Errorf("greedy search should explain that it is approximate")
+	}
+}
+
+func TestMinimalZeroWasteCatalogStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, pool := range []int{exactSubsetSearchLimit, exactSubsetSearchLimit + 3} {
+		candidates := make([]int, 0, pool)
+		for size := 7; len(candidates) < pool; size++ {
+			candidates = append(candidates, size)
+		}
+		if _, err := minimalZeroWasteCatalog(ctx, []int{1_000_000}, candidates); !errors.Is(err, context.Canceled) {
+			t.Errorf("search over %d candidates with a cancelled context error = %v, want %v", pool, err, context.Canceled)
+		}
+	}
+}
+
+func TestZeroWasteHandler(t *testing.T) {
+	body := `{"quantities": [300, 700, 1200], "candidates": [200, 300, 400, 500]}`
+	req := httptest.NewRequest(http.MethodPost, "/packages/zero-waste", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	zeroWasteHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages/zero-waste status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got ZeroWasteCatalog
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !reflect.DeepEqual(got.PackSizes, []int{200, 300}) {
+		t.Errorf("POST /packages/zero-waste sizes = %v, want [200 300]", got.PackSizes)
+	}
+
+	tooMany := make([]string, maxZeroWasteCandidates+1)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i + 1)
+	}
+	for _, bad := range []string{
+		`{"quantities": [], "candidates": [1]}`,
+		`{"quantities": [5], "candidates": [0]}`,
+		`{"quantities": [2000000000], "candidates": [1]}`,
+		`{"quantities": [5], "candidates": [` + strings.Join(tooMany, ", ") + `]}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/packages/zero-waste", strings.NewReader(bad))
+		rec := httptest.NewRecorder()
+		zeroWasteHandler(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /packages/zero-waste %s status = %d, want %d", bad, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCatalogImpact(t *testing.T) {
+	// 250 fills every order exactly; 1000 is only ever two 500s, so retiring
+	// it costs packs but no waste.
+	got, err := catalogImpact(context.Background(), []int{250, 750, 1250}, []int{250, 500, 1000}, map[int]string{250: "Small"})
+	if err != nil {
+		t.Fatalf("catalogImpact returned error: %v", err)
+	}
+	if got.BaselineWaste != 0 {
+		t.Errorf("baseline waste = %d, want 0", got.BaselineWaste)
+	}
+
+	increase := map[int]int{}
+	for _, impact := range got.Impacts {
+		increase[impact.PackSize] = impact.WasteIncrease
+	}
+	want := map[int]int{1000: 0, 500: 0, 250: 750}
+	if !reflect.DeepEqual(increase, want) {
+		t.Errorf("waste increases = %v, want %v", increase, want)
+	}
+	if increase[250] <= increase[1000] {
+		t.Errorf("removing the busy 250 (+%d) should cost more than the redundant 1000 (+%d)", increase[250], increase[1000])
+	}
+	if last := got.Impacts[len(got.Impacts)-1]; last.PackSize != 250 || last.Label != "Small" {
+		t.Errorf("last impact = %+v, want the labeled 250 size", last)
+	}
+}
+
+func TestImpactHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/impact", strings.NewReader(`{"quantities": [251, 1000]}`))
+	rec := httptest.NewRecorder()
+	impactHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages/impact status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got CatalogImpact
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got.BaselineWaste != 249 || len(got.Impacts) != 3 {
+		t.Errorf("POST /packages/impact = %+v, want baseline 249 over 3 sizes", got)
+	}
+
+	withPackSizes(t, []int{250})
+	req = httptest.NewRequest(http.MethodPost, "/packages/impact", strings.NewReader(`{"quantities": [251]}`))
+	rec = httptest.NewRecorder()
+	impactHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /packages/impact with one size status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRecommendSizes(t *testing.T) {
+	testCases := []struct {
+		description string
+		quantities  []int
+		k           int
+		want        []int
+	}{
+		{"three clusters", []int{480, 490, 500, 980, 1000, 2400}, 3, []int{500, 1000, 2400}},
+		{"one size covers all", []int{100, 200, 300}, 1, []int{300}},
+		{"weights pull the split", []int{100, 100, 100, 190, 200}, 2, []int{100, 200}},
+		{"fewer distinct than k", []int{250, 250, 500}, 4, []int{250, 500}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := recommendSizes(tc.quantities, tc.k); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("recommendSizes(%v, %d) = %v, want %v", tc.quantities, tc.k, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecommendHandlerBeatsDefaultCatalog(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	body := `{"quantities": [480, 490, 500, 980, 1000, 2400, 2400], "k": 3}`
+	req := httptest.NewRequest(http.MethodPost, "/packages/recommend", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	recommendHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages/recommend status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got CatalogRecommendation
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got.PackSizes) != 3 {
+		t.Errorf("recommended %v, want 3 sizes", got.PackSizes)
+	}
+	if got.CurrentWaste != 250 || got.Waste >= got.CurrentWaste {
+		t.Errorf("recommended waste %d vs current %d, want less than the default's 250", got.Waste, got.CurrentWaste)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/packages/recommend", strings.NewReader(`{"quantities": [5], "k": 0}`))
+	rec = httptest.NewRecorder()
+	recommendHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /packages/recommend with k 0 status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMinWasteMatchesFullSolve(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	withNamedCatalogs(t)
+
+	for _, quantity := range []int{1, 249, 250, 251, 501, 999, 4999, 5001, 12001, 23456} {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/optimize/min-waste?quantity=%d", quantity), nil)
+		rec := httptest.NewRecorder()
+		minWasteHandler(rec, req)
+
+		var got struct {
+			MinWaste int `json:"minWaste"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+		}
+		full, err := OptimizePacks(quantity)
+		if err != nil {
+			t.Fatalf("OptimizePacks(%d) returned error: %v", quantity, err)
+		}
+		if got.MinWaste != full.Waste {
+			t.Errorf("GET /optimize/min-waste?quantity=%d = %d, want the full result's %d", quantity, got.MinWaste, full.Waste)
+		}
+	}
+
+	for _, query := range []string{"", "quantity=0", "quantity=abc", "quantity=5&catalog=missing"} {
+		req := httptest.NewRequest(http.MethodGet, "/optimize/min-waste?"+query, nil)
+		rec := httptest.NewRecorder()
+		minWasteHandler(rec, req)
+		if rec.Code == http.StatusOK {
+			t.Errorf("GET /optimize/min-waste?%s status = %d, want an error", query, rec.Code)
+		}
+	}
+}
+
+func TestPackageHandlerWarnsAboutDominatedSizes(t *testing.T) {
+	withPackSizes(t, []int{500})
+
+	rec := postPackages(t, `{"packSizes": [250, 300, 550, 700]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "Pack size 550 is dominated") {
+		t.Errorf("POST /packages warnings = %q, want one for 550 = 250 + 300", got.Warnings)
+	}
+	if !reflect.DeepEqual(PackSizes, []int{250, 300, 550, 700}) {
+		t.Errorf("PackSizes = %v, want the catalog saved despite the warning", PackSizes)
+	}
+
+	rec = postPackages(t, `{"packSizes": [250, 300]}`)
+	if strings.Contains(rec.Body.String(), "warnings") {
+		t.Errorf("POST /packages without dominated sizes returned warnings: %s", rec.Body.String())
+	}
+
+	// The memory ceiling is enforced before the warnings' tables are built.
+	rec = postPackages(t, `{"packSizes": [1, 2000000000]}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "memory ceiling") {
+		t.Errorf("POST /packages above the ceiling = %d %s, want 400 naming the ceiling", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCoverageHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	namedCatalogs.put("odd", Catalog{PackSizes: []int{3, 5}})
+
+	testCases := []struct {
+		query string
+		want  CatalogCoverage
+	}{
+		// Only 250, 500, 750 and 1000 are fillable.
+		{"max=1000", CatalogCoverage{Max: 1000, Fillable: 4, Coverage: 0.4}},
+		{"max=249", CatalogCoverage{Max: 249, Fillable: 0, Coverage: 0}},
+		{"max=250", CatalogCoverage{Max: 250, Fillable: 1, Coverage: 0.4}},
+		// 3, 5, 6, 8, 9 and 10: everything from 8 up, plus 3, 5 and 6.
+		{"max=10&catalog=odd", CatalogCoverage{Max: 10, Fillable: 6, Coverage: 60}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodGet, "/packages/coverage?"+tc.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /packages/coverage?%s status = %d: %s", tc.query, rec.Code, rec.Body.String())
+			}
+			var got CatalogCoverage
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if got != tc.want {
+				t.Errorf("GET /packages/coverage?%s = %+v, want %+v", tc.query, got, tc.want)
+			}
+		})
+	}
+
+	for _, query := range []string{"", "max=0", "max=abc", "max=5&catalog=missing", fmt.Sprintf("max=%d", maxDPSize+1)} {
+		if rec := serveJSON(t, http.MethodGet, "/packages/coverage?"+query, ""); rec.Code == http.StatusOK {
+			t.Errorf("GET /packages/coverage?%s status = %d, want an error", query, rec.Code)
+		}
+	}
+}
+
+func TestWorstWasteHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	namedCatalogs.put("odd", Catalog{PackSizes: []int{4, 7}})
+	namedCatalogs.put("ones", Catalog{PackSizes: []int{1, 3}})
+
+	testCases := []struct {
+		query string
+		want  CatalogWorstWaste
+	}{
+		// 1, 251, 501 and 751 each waste 249; 1001 is out of range.
+		{"max=1000", CatalogWorstWaste{Max: 1000, Quantity: 751, Waste: 249, Occurrences: 4}},
+		{"max=751", CatalogWorstWaste{Max: 751, Quantity: 751, Waste: 249, Occurrences: 4}},
+		{"max=750", CatalogWorstWaste{Max: 750, Quantity: 501, Waste: 249, Occurrences: 3}},
+		{"max=10", CatalogWorstWaste{Max: 10, Quantity: 1, Waste: 249, Occurrences: 1}},
+		// Only 1 wastes 3; everything from 18 up is reachable.
+		{"max=30&catalog=odd", CatalogWorstWaste{Max: 30, Quantity: 1, Waste: 3, Occurrences: 1}},
+		{"max=5&catalog=ones", CatalogWorstWaste{Max: 5, Quantity: 5, Waste: 0, Occurrences: 5}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodGet, "/packages/worst-waste?"+tc.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /packages/worst-waste?%s status = %d: %s", tc.query, rec.Code, rec.Body.String())
+			}
+			var got CatalogWorstWaste
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if got != tc.want {
+				t.Errorf("GET /packages/worst-waste?%s = %+v, want %+v", tc.query, got, tc.want)
+			}
+		})
+	}
+
+	for _, query := range []string{"", "max=0", "max=abc", "max=5&catalog=missing", fmt.Sprintf("max=%d", maxDPSize)} {
+		if rec := serveJSON(t, http.MethodGet, "/packages/worst-waste?"+query, ""); rec.Code == http.StatusOK {
+			t.Errorf("GET /packages/worst-waste?%s status = %d, want an error", query, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// maxBatchSize bounds the quantities accepted by one batch request.
+var maxBatchSize = envInt("MAX_BATCH_SIZE", 10000)
+
+// BatchItem is the answer for one quantity of a batch: a result, or the
+// reason that quantity could not be optimized.
+type BatchItem struct {
+	Quantity int                 `json:"quantity"`
+	Result   *OptimizationResult `json:"result,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// BatchResult is the response of POST /optimize/batch, in request order.
+type BatchResult struct {
+	Results []BatchItem `json:"results"`
+	Meta    *BatchMeta  `json:"meta,omitempty"`
+}
+
+// BatchMeta reports how much of a batch repeated quantities: Distinct
+// quantities were solved, and DedupeRatio is the percentage of Quantities
+// answered from another position's solve.
+type BatchMeta struct {
+	Quantities  int     `json:"quantities"`
+	Distinct    int     `json:"distinct"`
+	DedupeRatio float64 `json:"dedupeRatio"`
+}
+
+// OptimizeBatch optimizes every quantity against catalog. The DP table does
+// not depend on the order, so it is built once for the largest quantity and
+// each answer is read from it by backtracking. Errors that concern a single
+// quantity are reported on its item; errors that concern the whole request
+// are returned.
+func OptimizeBatch(quantities []int, catalog Catalog, opts OptimizeOptions) ([]BatchItem, error) {
+	items, _, err := optimizeBatch(quantities, catalog, opts)
+	return items, err
+}
+
+// optimizeBatch is OptimizeBatch, also reporting how many distinct
+// quantities it solved. A repeated quantity is solved once and every
+// position holding it shares the answer, result included, so callers must
+// not modify results in place.
+func optimizeBatch(quantities []int, catalog Catalog, opts OptimizeOptions) ([]BatchItem, BatchMeta, error) {
+	meta := BatchMeta{Quantities: len(quantities)}
+	if err := validateOptions(catalog, opts); err != nil {
+		return nil, meta, err
+	}
+	sizes := sortedDescending(catalog.PackSizes)
+
+	var dp []dpEntry
+	if largest := slices.Max(quantities); largest > 0 {
+		target := max(largest, opts.MinOrderQuantity)
+		observeWindow(target, sizes, opts)
+		if needsPackTable(sizes, opts) {
+			maxSize, err := searchWindow(target, sizes, opts)
+			if err != nil {
+				return nil, meta, err
+			}
+			if dp, err = fillPackTable(solveContext(opts), sizes, maxSize); err != nil {
+				return nil, meta, err
+			}
+		}
+	}
+
+	items := make([]BatchItem, len(quantities))
+	solved := make(map[int]BatchItem)
+	for i, quantity := range quantities {
+		if item, ok := solved[quantity]; ok {
+			items[i] = item
+			continue
+		}
+		if err := stopped(solveContext(opts)); err != nil {
+			return nil, meta, err
+		}
+		items[i] = batchItem(quantity, sizes, catalog, opts, dp)
+		solved[quantity] = items[i]
+	}
+	meta.Distinct = len(solved)
+	meta.DedupeRatio = percentage(meta.Quantities-meta.Distinct, meta.Quantities)
+	return items, meta, nil
+}
+
+// batchItem answers one quantity of a batch from dp.
+func batchItem(quantity int, sizes []int, catalog Catalog, opts OptimizeOptions, dp []dpEntry) BatchItem {
+	item := BatchItem{Quantity: quantity}
+	if quantity == 0 && opts.TreatZeroAsEmpty {
+		item.Result = emptyResult(opts)
+		return item
+	}
+	if quantity <= 0 {
+		item.Error = "order quantity must be positive"
+		return item
+	}
+	target := max(quantity, opts.MinOrderQuantity)
+	if opts.MaxWaste > 0 && target > quantity+opts.MaxWaste {
+		item.Error = fmt.Sprintf("the minimum order of %d wastes more than maxWaste %d", target, opts.MaxWaste)
+		return item
+	}
+	result, err := solveWithTable(target, sizes, limitWaste(quantity, opts), dp)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	if target != quantity {
+		rebaseOrder(result, quantity)
+		result.BumpedTo = target
+	}
+	applyLabels(result, catalog.Labels)
+	item.Result = result
+	return item
+}
+
+// HTTP handler for batch pack optimization
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Quantities []int     `json:"quantities"`
+		Mode       string    `json:"mode"`
+		Costs      costTable `json:"costs"`
+		Policy     string    `json:"policy"`
+		Catalog    string    `json:"catalog"`
+
+		TreatZeroAsEmpty *bool `json:"treatZeroAsEmpty"`
+		MinOrderQuantity *int  `json:"minOrderQuantity"`
+
+		Meta bool `json:"meta"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Quantities) == 0 {
+		writeError(w, "quantities must contain at least one quantity", http.StatusBadRequest)
+		return
+	}
+	if maxBatchSize > 0 && len(request.Quantities) > maxBatchSize {
+		writeError(w, fmt.Sprintf("A batch may hold at most %d quantities", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	catalogName := request.Catalog
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", request.Catalog), http.StatusNotFound)
+		return
+	}
+
+	costs, currency, err := request.Costs.split()
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minOrder, err := resolveMinOrder(request.MinOrderQuantity)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, meta, err := optimizeBatch(request.Quantities, catalog, catalog.Defaults.apply(OptimizeOptions{
+		Mode:     request.Mode,
+		Costs:    costs,
+		Currency: currency,
+		Policy:   request.Policy,
+
+		TreatZeroAsEmpty: zeroAsEmpty(request.TreatZeroAsEmpty),
+		MinOrderQuantity: minOrder,
+		Context:          r.Context(),
+	}))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := BatchResult{Results: items}
+	if request.Meta {
+		response.Meta = &meta
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
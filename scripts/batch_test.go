@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptimizeBatchMatchesSingleSolves(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	quantities := []int{1, 251, 12001, 499, 5001, 750}
+	for _, mode := range []string{ModeFewestPacks, ModeFewestLines} {
+		items, err := OptimizeBatch(quantities, catalog, OptimizeOptions{Mode: mode})
+		if err != nil {
+			t.Fatalf("OptimizeBatch(%s) returned error: %v", mode, err)
+		}
+		for i, quantity := range quantities {
+			want, err := OptimizePacksWithOptions(quantity, OptimizeOptions{Mode: mode})
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(%d) returned error: %v", quantity, err)
+			}
+			if items[i].Quantity != quantity || !reflect.DeepEqual(items[i].Result, want) {
+				t.Errorf("batch item %d (%s) = %+v, want %+v", i, mode, items[i].Result, want)
+			}
+		}
+	}
+}
+
+func TestOptimizeBatchReportsItemErrors(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500}}
+
+	items, err := OptimizeBatch([]int{500, 0, 300}, catalog, OptimizeOptions{Policy: PolicyExact})
+	if err != nil {
+		t.Fatalf("OptimizeBatch returned error: %v", err)
+	}
+	if items[0].Result == nil || items[0].Error != "" {
+		t.Errorf("item 0 = %+v, want a result", items[0])
+	}
+	if items[1].Result != nil || items[1].Error != "order quantity must be positive" {
+		t.Errorf("item 1 = %+v, want a positive-quantity error", items[1])
+	}
+	if items[2].Result != nil || !strings.Contains(items[2].Error, "cannot be filled exactly") {
+		t.Errorf("item 2 = %+v, want an infeasible error", items[2])
+	}
+
+	if _, err := OptimizeBatch([]int{500}, catalog, OptimizeOptions{Mode: "bogus"}); err == nil {
+		t.Error("OptimizeBatch should reject an unknown mode for the whole batch")
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize/batch", strings.NewReader(`{"quantities": [1, 251, 12001]}`))
+	rec := httptest.NewRecorder()
+	batchHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/batch status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got BatchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	totals := []int{}
+	for _, item := range got.Results {
+		totals = append(totals, item.Result.TotalItems)
+	}
+	if want := []int{250, 500, 12250}; !reflect.DeepEqual(totals, want) {
+		t.Errorf("POST /optimize/batch totals = %v, want %v", totals, want)
+	}
+
+	for _, bad := range []string{`{"quantities": []}`, `{"quantities": [1], "catalog": "missing"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/optimize/batch", strings.NewReader(bad))
+		rec := httptest.NewRecorder()
+		batchHandler(rec, req)
+		if rec.Code == http.StatusOK {
+			t.Errorf("POST /optimize/batch %s status = %d, want an error", bad, rec.Code)
+		}
+	}
+}
+
+func TestOptimizeBatchDeduplicatesQuantities(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	quantities := []int{501, 12001, 501, 501, 0, 12001, 501, 251, 0, 501}
+
+	items, meta, err := optimizeBatch(quantities, catalog, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("optimizeBatch returned error: %v", err)
+	}
+	if want := (BatchMeta{Quantities: 10, Distinct: 4, DedupeRatio: 60}); meta != want {
+		t.Errorf("meta = %+v, want %+v", meta, want)
+	}
+	if len(items) != len(quantities) {
+		t.Fatalf("optimizeBatch returned %d items for %d quantities", len(items), len(quantities))
+	}
+	for i, quantity := range quantities {
+		if items[i].Quantity != quantity {
+			t.Fatalf("item %d quantity = %d, want %d", i, items[i].Quantity, quantity)
+		}
+		if quantity == 0 {
+			if items[i].Result != nil || items[i].Error == "" {
+				t.Errorf("item %d = %+v, want an error", i, items[i])
+			}
+			continue
+		}
+		want, err := OptimizeCatalog(quantity, catalog, OptimizeOptions{})
+		if err != nil {
+			t.Fatalf("OptimizeCatalog(%d) returned error: %v", quantity, err)
+		}
+		if !reflect.DeepEqual(items[i].Result, want) {
+			t.Errorf("item %d = %+v, want %+v", i, items[i].Result, want)
+		}
+	}
+}
+
+func TestBatchHandlerMeta(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := serveJSON(t, http.MethodPost, "/optimize/batch", `{"quantities": [1, 1, 1, 251], "meta": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/batch status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got BatchResult
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got.Meta == nil || *got.Meta != (BatchMeta{Quantities: 4, Distinct: 2, DedupeRatio: 50}) {
+		t.Errorf("meta = %+v, want 2 distinct of 4 quantities", got.Meta)
+	}
+	if len(got.Results) != 4 || got.Results[2].Result.TotalItems != 250 || got.Results[3].Result.TotalItems != 500 {
+		t.Errorf("results = %+v, want each position answered", got.Results)
+	}
+
+	rec = serveJSON(t, http.MethodPost, "/optimize/batch", `{"quantities": [1, 1]}`)
+	if strings.Contains(rec.Body.String(), `"meta"`) {
+		t.Errorf("response without meta requested = %s", rec.Body.String())
+	}
+}
+
+// batchQuantities is a deterministic 10k-item batch of orders up to 50k.
+func batchQuantities() []int {
+	rng := rand.New(rand.NewSource(1))
+	quantities := make([]int, 10000)
+	for i := range quantities {
+		quantities[i] = 1 + rng.Intn(50000)
+	}
+	return quantities
+}
+
+func BenchmarkOptimizeBatchSharedTable(b *testing.B) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	quantities := batchQuantities()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OptimizeBatch(quantities, catalog, OptimizeOptions{})
+	}
+}
+
+func BenchmarkOptimizeBatchNaive(b *testing.B) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	quantities := batchQuantities()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, quantity := range quantities {
+			OptimizeCatalog(quantity, catalog, OptimizeOptions{})
+		}
+	}
+}
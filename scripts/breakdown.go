@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// breakdownTotal finds the fewest packs of catalog summing to exactly
+// totalItems. Unlike an optimize request, totalItems is the shipment itself
+// rather than a minimum order: a total the catalog cannot reach fails with
+// ErrInfeasible unless nearest is set, in which case the nearest reachable
+// total is broken down instead and its distance from totalItems is reported
+// as Waste or Shortfall.
+func breakdownTotal(totalItems int, catalog Catalog, nearest bool) (*OptimizationResult, error) {
+	opts := OptimizeOptions{Policy: PolicyExact}
+	if nearest {
+		opts.Policy = PolicyAllowUnderfill
+	}
+	return OptimizeCatalog(totalItems, catalog, opts)
+}
+
+// HTTP handler for breaking an exact total into packs
+func breakdownHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		TotalItems int    `json:"totalItems"`
+		Catalog    string `json:"catalog"`
+		Nearest    bool   `json:"nearest"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.TotalItems <= 0 {
+		writeError(w, "totalItems must be positive", http.StatusBadRequest)
+		return
+	}
+
+	catalogName := request.Catalog
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", request.Catalog), http.StatusNotFound)
+		return
+	}
+
+	result, err := breakdownTotal(request.TotalItems, catalog, request.Nearest)
+	if errors.Is(err, ErrInfeasible) {
+		writeError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
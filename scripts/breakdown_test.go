@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBreakdownHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	withNamedCatalogs(t)
+	namedCatalogs.put("coins", Catalog{PackSizes: []int{1, 5, 6}})
+
+	testCases := []struct {
+		description string
+		body        string
+		wantStatus  int
+		want        OptimizationResult
+	}{
+		{
+			"reachable total",
+			`{"totalItems": 750}`,
+			http.StatusOK,
+			OptimizationResult{OrderQuantity: 750, TotalItems: 750, TotalPacks: 2, Packs: []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}},
+		},
+		{
+			"fewest packs rather than the largest first",
+			`{"totalItems": 10, "catalog": "coins"}`,
+			http.StatusOK,
+			OptimizationResult{OrderQuantity: 10, TotalItems: 10, TotalPacks: 2, Packs: []PackResult{{PackSize: 5, Quantity: 2}}},
+		},
+		{
+			"unreachable total",
+			`{"totalItems": 751}`,
+			http.StatusUnprocessableEntity,
+			OptimizationResult{},
+		},
+		{
+			"nearest below an unreachable total",
+			`{"totalItems": 751, "nearest": true}`,
+			http.StatusOK,
+			OptimizationResult{OrderQuantity: 751, TotalItems: 750, TotalPacks: 2, Packs: []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, Shortfall: 1},
+		},
+		{
+			"nearest above an unreachable total",
+			`{"totalItems": 999, "nearest": true}`,
+			http.StatusOK,
+			OptimizationResult{OrderQuantity: 999, TotalItems: 1000, TotalPacks: 1, Packs: []PackResult{{PackSize: 1000, Quantity: 1}}, Waste: 1},
+		},
+		{"non-positive total", `{"totalItems": 0}`, http.StatusBadRequest, OptimizationResult{}},
+		{"unknown catalog", `{"totalItems": 10, "catalog": "missing"}`, http.StatusNotFound, OptimizationResult{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodPost, "/breakdown", tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /breakdown %s status = %d, want %d: %s", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("POST /breakdown %s = %+v, want %+v", tc.body, got, tc.want)
+			}
+		})
+	}
+}
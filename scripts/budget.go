@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BindingBudget is the BindingConstraint of a result moved away from the
+// unconstrained optimum to stay within the budget.
+const BindingBudget = "budget"
+
+// validateBudget checks that opts.Budget is a usable budget: it prices
+// packs with Costs and is not combined with the other limits on the mix.
+func validateBudget(opts OptimizeOptions) error {
+	if opts.Budget < 0 || math.IsNaN(opts.Budget) || math.IsInf(opts.Budget, 0) {
+		return fmt.Errorf("budget must be a non-negative number")
+	}
+	if opts.Budget == 0 {
+		return nil
+	}
+	if opts.Costs == nil {
+		return fmt.Errorf("budget requires costs")
+	}
+	if opts.Mode == ModeMinVolume {
+		return fmt.Errorf("budget is not supported with mode %q", opts.Mode)
+	}
+	if opts.MaxPacks > 0 || opts.MaxWastePerSize != nil || opts.MaxSlots > 0 {
+		return fmt.Errorf("budget cannot be combined with maxPacks, maxWastePerSize or maxSlots")
+	}
+	return nil
+}
+
+// withinBudget reports whether cost does not meaningfully exceed budget.
+func withinBudget(cost, budget float64) bool {
+	return !costLess(budget, cost)
+}
+
+// budgetBreakdown finds the least total in [lo, hi] some mix reaches within
+// budget, with its cheapest mix. It returns -1 when none is affordable,
+// with the lowest cost of any total in the range; that is math.Inf(1) when
+// none is reachable. With non-negative costs the window of the order plus
+// the largest pack holds the cheapest total at or above the order, since
+// dropping a pack from a larger total never costs more. sizes must be
+// sorted in descending order and hi must not exceed maxSize.
+func budgetBreakdown(sizes []int, lo, hi, maxSize int, costs map[int]float64, budget float64) (int, map[int]int, float64) {
+	type costEntry struct {
+		cost float64
+		pack int
+	}
+
+	dp := make([]costEntry, maxSize+1)
+	for i := range dp {
+		dp[i].cost = math.Inf(1)
+	}
+	dp[0] = costEntry{}
+
+	for i := 0; i <= maxSize; i++ {
+		if math.IsInf(dp[i].cost, 1) {
+			continue
+		}
+		for _, pack := range sizes {
+			next := i + pack
+			if next > maxSize {
+				continue
+			}
+			if cost := dp[i].cost + costs[pack]; math.IsInf(dp[next].cost, 1) || costLess(cost, dp[next].cost) {
+				dp[next] = costEntry{cost: cost, pack: pack}
+			}
+		}
+	}
+
+	cheapest := math.Inf(1)
+	for i := lo; i <= hi; i++ {
+		if math.IsInf(dp[i].cost, 1) {
+			continue
+		}
+		if !withinBudget(dp[i].cost, budget) {
+			cheapest = math.Min(cheapest, dp[i].cost)
+			continue
+		}
+		counts := make(map[int]int)
+		for cur := i; cur > 0; cur -= dp[cur].pack {
+			counts[dp[cur].pack]++
+		}
+		return i, counts, dp[i].cost
+	}
+	return -1, nil, cheapest
+}
+
+// overBudget explains an order no mix fills within budget, with the least
+// any mix costs; cheapest is infinite when no total is reachable at all.
+func overBudget(orderQuantity int, cheapest, budget float64, currency string) error {
+	if math.IsInf(cheapest, 1) {
+		return fmt.Errorf("%w: order %d cannot be filled exactly", ErrInfeasible, orderQuantity)
+	}
+	return fmt.Errorf("%w: order %d costs at least %g, above the budget of %g",
+		ErrInfeasible, orderQuantity, roundCost(cheapest, currency), budget)
+}
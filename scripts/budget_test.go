@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{300, 1000}}
+	costs := map[int]float64{300: 1, 1000: 10}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+		wantWaste   int
+		wantCost    float64
+		wantBinding string
+	}{
+		{"optimum within budget", 1000, OptimizeOptions{Costs: costs, Budget: 10},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, 0, 10, BindingNone},
+		{"tight budget forces cheaper packs and more waste", 1000, OptimizeOptions{Costs: costs, Budget: 5},
+			[]PackResult{{PackSize: 300, Quantity: 4}}, 200, 4, BindingBudget},
+		{"exact fill within budget", 1300, OptimizeOptions{Costs: costs, Budget: 11},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 300, Quantity: 1}}, 0, 11, BindingNone},
+		{"cheapest mode within budget", 1000, OptimizeOptions{Mode: ModeCheapest, Costs: costs, Budget: 4},
+			[]PackResult{{PackSize: 300, Quantity: 4}}, 200, 4, BindingNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, catalog, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.Waste != tc.wantWaste {
+				t.Errorf("waste = %d, want %d", result.Waste, tc.wantWaste)
+			}
+			if result.TotalCost == nil || *result.TotalCost != tc.wantCost {
+				t.Errorf("totalCost = %v, want %g", result.TotalCost, tc.wantCost)
+			}
+			if result.BindingConstraint != tc.wantBinding {
+				t.Errorf("bindingConstraint = %q, want %q", result.BindingConstraint, tc.wantBinding)
+			}
+		})
+	}
+}
+
+func TestBudgetInfeasibleAndInvalid(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{300, 1000}}
+	costs := map[int]float64{300: 1, 1000: 10}
+
+	testCases := []struct {
+		description string
+		opts        OptimizeOptions
+		infeasible  bool
+	}{
+		{"no mix affordable", OptimizeOptions{Costs: costs, Budget: 3}, true},
+		{"cheapest mode over budget", OptimizeOptions{Mode: ModeCheapest, Costs: costs, Budget: 3}, true},
+		{"exact policy over budget", OptimizeOptions{Costs: costs, Budget: 5, Policy: PolicyExact}, true},
+		{"budget without costs", OptimizeOptions{Budget: 5}, false},
+		{"negative budget", OptimizeOptions{Costs: costs, Budget: -1}, false},
+		{"with maxPacks", OptimizeOptions{Costs: costs, Budget: 5, MaxPacks: 2}, false},
+		{"underfill over budget", OptimizeOptions{Costs: costs, Budget: 5, Policy: PolicyAllowUnderfill}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := OptimizeCatalog(1000, catalog, tc.opts)
+			if err == nil {
+				t.Fatalf("OptimizeCatalog(1000) returned no error")
+			}
+			if errors.Is(err, ErrInfeasible) != tc.infeasible {
+				t.Errorf("OptimizeCatalog(1000) error = %v, want infeasible %v", err, tc.infeasible)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerBudget(t *testing.T) {
+	withPackSizes(t, []int{300, 1000})
+
+	rec := postOptimize(t, `{"quantity": 1000, "costs": {"300": 1, "1000": 10}, "budget": 5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.TotalItems != 1200 || result.BindingConstraint != BindingBudget {
+		t.Errorf("result = %d items, bindingConstraint %q; want 1200 items, %q", result.TotalItems, result.BindingConstraint, BindingBudget)
+	}
+
+	rec = postOptimize(t, `{"quantity": 1000, "costs": {"300": 1, "1000": 10}, "budget": 3}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unaffordable order status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if message := errorMessage(t, rec); !strings.Contains(message, "costs at least 4") {
+		t.Errorf("error = %q, want the least cost", message)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// capabilities describes what this server instance supports so clients can
+// detect features instead of probing for them.
+type capabilities struct {
+	Modes       []string        `json:"modes"`
+	Policies    []string        `json:"policies"`
+	Options     []string        `json:"options"`
+	Constraints []string        `json:"constraints"`
+	Formats     []string        `json:"formats"`
+	Features    map[string]bool `json:"features"`
+}
+
+// currentCapabilities reports the capabilities enabled by this build and its
+// configuration. Keep it in step with the optimize request fields.
+func currentCapabilities() capabilities {
+	return capabilities{
+		Modes:       supportedModes,
+		Policies:    supportedPolicies,
+		Options:     []string{"mode", "costs", "volumes", "slots", "policy", "underfillTiebreak", "penalizeSmallPacks", "preferEvenCounts", "wasteDelta", "searchMargin", "catalog", "scaleSizes", "exclude", "masterCartonSize", "assignment", "weights", "wastePenalty", "spreadTies", "seed", "includeUnused", "compareNaive", "suggestUpsell", "bothObjectives", "tolerances", "topK", "upgradePath", "echoRequest", "diagnostics", "treatZeroAsEmpty", "minOrderQuantity", "strictSmallOrders", "alreadyShipped", "orderRef"},
+		Constraints: []string{"minQuantity", "maxQuantity", "maxWastePerSize", "maxPacks", "maxWaste", "maxSlots", "budget"},
+		Formats:     []string{"application/json", protobufContentType, htmlContentType, manifestContentType, compactContentType},
+		Features: map[string]bool{
+			"dedupe":            optimizeDedupe.window > 0,
+			"accessLogSampling": accessLog.sampleRate > 1,
+			"concurrencyLimit":  optimizeLimiter.slots != nil,
+			"readOnlyCatalog":   catalogLock.locked,
+		},
+	}
+}
+
+// Capabilities discovery endpoint
+func capabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(currentCapabilities())
+}
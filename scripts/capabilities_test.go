@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestCapabilitiesHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+	rec := httptest.NewRecorder()
+	capabilitiesHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /capabilities status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var got capabilities
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	for _, mode := range []string{ModeFewestPacks, ModeFewestLines, ModeCheapest} {
+		if !slices.Contains(got.Modes, mode) {
+			t.Errorf("capabilities modes %v missing %q", got.Modes, mode)
+		}
+	}
+	if !slices.Equal(got.Policies, supportedPolicies) {
+		t.Errorf("capabilities policies = %v, want %v", got.Policies, supportedPolicies)
+	}
+	for _, option := range []string{"mode", "costs", "policy"} {
+		if !slices.Contains(got.Options, option) {
+			t.Errorf("capabilities options %v missing %q", got.Options, option)
+		}
+	}
+	for _, format := range []string{"application/json", protobufContentType, htmlContentType} {
+		if !slices.Contains(got.Formats, format) {
+			t.Errorf("capabilities formats %v missing %q", got.Formats, format)
+		}
+	}
+	if got.Features["dedupe"] != (optimizeDedupe.window > 0) {
+		t.Errorf("capabilities dedupe = %v, want %v", got.Features["dedupe"], optimizeDedupe.window > 0)
+	}
+}
+
+func TestCapabilitiesHandlerRejectsPost(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/capabilities", nil)
+	rec := httptest.NewRecorder()
+	capabilitiesHandler(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /capabilities status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// defaultCatalogName names the catalog held in PackSizes and PackLabels.
+const defaultCatalogName = "default"
+
+// Catalog is a pack size configuration with optional labels and default
+// constraints.
+type Catalog struct {
+	PackSizes []int            `json:"packSizes"`
+	Labels    map[int]string   `json:"labels,omitempty"`
+	Defaults  *CatalogDefaults `json:"defaults,omitempty"`
+}
+
+// CatalogDefaults are constraints stored with a named catalog and applied
+// to every optimization against it that leaves them unset.
+type CatalogDefaults struct {
+	Policy          string      `json:"policy,omitempty"`
+	MaxWaste        int         `json:"maxWaste,omitempty"`
+	MaxPacks        int         `json:"maxPacks,omitempty"`
+	MaxWastePerSize map[int]int `json:"maxWastePerSize,omitempty"`
+}
+
+// apply returns opts with every constraint it leaves unset taken from d. A
+// nil d leaves opts as they are.
+func (d *CatalogDefaults) apply(opts OptimizeOptions) OptimizeOptions {
+	if d == nil {
+		return opts
+	}
+	if opts.Policy == "" {
+		opts.Policy = d.Policy
+	}
+	if opts.MaxWaste == 0 {
+		opts.MaxWaste = d.MaxWaste
+	}
+	if opts.MaxPacks == 0 {
+		opts.MaxPacks = d.MaxPacks
+	}
+	if opts.MaxWastePerSize == nil {
+		opts.MaxWastePerSize = d.MaxWastePerSize
+	}
+	return opts
+}
+
+// validate checks that d only holds constraints an optimization against
+// sizes accepts.
+func (d *CatalogDefaults) validate(sizes []int) error {
+	if d.Policy != "" && !validPolicy(d.Policy) {
+		return fmt.Errorf("defaults: unknown policy %q", d.Policy)
+	}
+	opts := OptimizeOptions{MaxWaste: d.MaxWaste, MaxPacks: d.MaxPacks, MaxWastePerSize: d.MaxWastePerSize}
+	if err := validateWasteCaps(opts, sizes); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	if err := validatePackLimit(opts); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	return nil
+}
+
+// namedCatalogs holds the catalogs stored with PUT /packages/{name}.
+var namedCatalogs = newCatalogStore(envInt("MAX_CATALOGS", 100))
+
+// ErrCatalogLimit reports that storing another named catalog would exceed
+// the store's limit.
+var ErrCatalogLimit = errors.New("too many named catalogs")
+
+// catalogStore keeps named catalogs next to the default one. The default
+// catalog stays in PackSizes and PackLabels so existing callers see it, and
+// does not count towards limit; the store still reads and replaces it under
+// mu.
+type catalogStore struct {
+	mu       sync.RWMutex
+	catalogs map[string]Catalog
+	limit    int
+}
+
+// newCatalogStore returns a store holding up to limit named catalogs;
+// limit <= 0 disables the cap.
+func newCatalogStore(limit int) *catalogStore {
+	return &catalogStore{catalogs: make(map[string]Catalog), limit: limit}
+}
+
+// lookup returns the catalog stored under name.
+func (s *catalogStore) lookup(name string) (Catalog, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if name == defaultCatalogName {
+		return Catalog{PackSizes: slices.Clone(PackSizes), Labels: PackLabels}, true
+	}
+	catalog, ok := s.catalogs[name]
+	return catalog, ok
+}
+
+// put stores catalog under name, replacing any previous one. Adding a new
+// name beyond the limit fails with ErrCatalogLimit.
+func (s *catalogStore) put(name string, catalog Catalog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == defaultCatalogName {
+		PackSizes = catalog.PackSizes
+		PackLabels = catalog.Labels
+		return nil
+	}
+	if _, exists := s.catalogs[name]; !exists && s.limit > 0 && len(s.catalogs) >= s.limit {
+		return fmt.Errorf("%w: the limit is %d", ErrCatalogLimit, s.limit)
+	}
+	s.catalogs[name] = catalog
+	return nil
+}
+
+// update replaces the catalog stored under name with change applied to it,
+// holding mu throughout so concurrent updates cannot interleave. It reports
+// false when no catalog is stored under name, and leaves the catalog as it
+// was when change fails.
+func (s *catalogStore) update(name string, change func(Catalog) (Catalog, error)) (Catalog, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.catalogs[name]
+	if name == defaultCatalogName {
+		current, ok = Catalog{PackSizes: slices.Clone(PackSizes), Labels: PackLabels}, true
+	}
+	if !ok {
+		return Catalog{}, false, nil
+	}
+
+	updated, err := change(current)
+	if err != nil {
+		return Catalog{}, true, err
+	}
+	if name == defaultCatalogName {
+		PackSizes = updated.PackSizes
+		PackLabels = updated.Labels
+	} else {
+		s.catalogs[name] = updated
+	}
+	return updated, true, nil
+}
+
+// remove deletes the catalog stored under name and reports whether it
+// existed. The default catalog cannot be removed.
+func (s *catalogStore) remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.catalogs[name]
+	delete(s.catalogs, name)
+	return ok
+}
+
+// all returns every catalog, including the default, keyed by name.
+func (s *catalogStore) all() map[string]Catalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string]Catalog, len(s.catalogs)+1)
+	for name, catalog := range s.catalogs {
+		all[name] = catalog
+	}
+	all[defaultCatalogName] = Catalog{PackSizes: slices.Clone(PackSizes), Labels: PackLabels}
+	return all
+}
+
+// validateCatalog reports why catalog cannot be used to optimize: no sizes,
+// invalid sizes, or a largest size whose smallest search window already
+// exceeds the maxDPSize memory ceiling.
+func validateCatalog(catalog Catalog) error {
+	if len(catalog.PackSizes) == 0 {
+		return fmt.Errorf("catalog has no pack sizes")
+	}
+	if err := validatePackSizes(catalog.PackSizes); err != nil {
+		return err
+	}
+	if largest := slices.Max(catalog.PackSizes); largest >= maxDPSize {
+		return fmt.Errorf("largest pack size %d exceeds the memory ceiling of %d items", largest, maxDPSize)
+	}
+	if catalog.Defaults != nil {
+		return catalog.Defaults.validate(catalog.PackSizes)
+	}
+	return nil
+}
+
+// patchCatalog returns catalog without the sizes in remove and with the sizes
+// in add appended, keeping the order of the sizes that stay. Labels of
+// removed sizes are dropped. Removing a size the catalog lacks, or every
+// size, is an error, and the result must pass validateCatalog.
+func patchCatalog(catalog Catalog, add, remove []int) (Catalog, error) {
+	if len(add) == 0 && len(remove) == 0 {
+		return Catalog{}, fmt.Errorf("add or remove at least one pack size")
+	}
+	for _, size := range remove {
+		if !slices.Contains(catalog.PackSizes, size) {
+			return Catalog{}, fmt.Errorf("pack size %d is not in the catalog", size)
+		}
+	}
+
+	patched := Catalog{Labels: make(map[int]string, len(catalog.Labels)), Defaults: catalog.Defaults}
+	for _, size := range catalog.PackSizes {
+		if !slices.Contains(remove, size) {
+			patched.PackSizes = append(patched.PackSizes, size)
+		}
+	}
+	if len(patched.PackSizes) == 0 && len(add) == 0 {
+		return Catalog{}, fmt.Errorf("cannot remove the last remaining pack size")
+	}
+	patched.PackSizes = append(patched.PackSizes, add...)
+	for size, label := range catalog.Labels {
+		if slices.Contains(patched.PackSizes, size) && !slices.Contains(remove, size) {
+			patched.Labels[size] = label
+		}
+	}
+	if err := validateCatalog(patched); err != nil {
+		return Catalog{}, err
+	}
+	return patched, nil
+}
+
+// catalogHealth is the /health report for one catalog.
+type catalogHealth struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HTTP handler for reading and storing named catalogs
+func namedPackageHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	name := r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		catalog, ok := namedCatalogs.lookup(name)
+		if !ok {
+			writeError(w, fmt.Sprintf("Unknown catalog %q", name), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(catalog)
+	case http.MethodPut:
+		var request struct {
+			PackSizes json.RawMessage  `json:"packSizes"`
+			Defaults  *CatalogDefaults `json:"defaults"`
+		}
+
+		if err := decodeJSONBody(r, &request); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		packSizes, labels, err := decodePackSizes(request.PackSizes)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		catalog := Catalog{PackSizes: packSizes, Labels: labels, Defaults: request.Defaults}
+		if name == defaultCatalogName && catalog.Defaults != nil {
+			writeError(w, "The default catalog cannot store defaults", http.StatusBadRequest)
+			return
+		}
+		if err := validateCatalog(catalog); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := namedCatalogs.put(name, catalog); err != nil {
+			writeError(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(catalog)
+	case http.MethodDelete:
+		if name == defaultCatalogName {
+			writeError(w, "The default catalog cannot be deleted", http.StatusBadRequest)
+			return
+		}
+		if !namedCatalogs.remove(name) {
+			writeError(w, fmt.Sprintf("Unknown catalog %q", name), http.StatusNotFound)
+			return
+		}
+
+		response := struct {
+			Message string `json:"message"`
+		}{
+			Message: fmt.Sprintf("Catalog %q deleted", name),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	default:
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HTTP handler restoring the default catalog to defaultPackSizes, without
+// labels
+func resetHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	catalog := Catalog{PackSizes: slices.Clone(defaultPackSizes), Labels: map[int]string{}}
+	if err := namedCatalogs.put(defaultCatalogName, catalog); err != nil {
+		writeError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalog)
+}
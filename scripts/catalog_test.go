@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withNamedCatalogs swaps in an empty named catalog store for the duration
+// of a test.
+func withNamedCatalogs(t *testing.T) {
+	t.Helper()
+	saved := namedCatalogs
+	namedCatalogs = newCatalogStore(0)
+	t.Cleanup(func() { namedCatalogs = saved })
+}
+
+// putCatalog sends body to PUT /packages/{name} through the router.
+func putCatalog(t *testing.T, name, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/packages/"+name, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNamedCatalogs(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	withNamedCatalogs(t)
+
+	if rec := putCatalog(t, "eu", `{"packSizes": [{"size": 400, "label": "Crate"}, 100]}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /packages/eu status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/packages/eu", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	var catalog Catalog
+	if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+		t.Fatalf("decoding GET /packages/eu: %v", err)
+	}
+	if !reflect.DeepEqual(catalog.PackSizes, []int{400, 100}) || catalog.Labels[400] != "Crate" {
+		t.Errorf("GET /packages/eu = %+v, want the stored catalog", catalog)
+	}
+
+	rec = postOptimize(t, `{"quantity": 450, "catalog": "eu"}`)
+	var result OptimizationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding optimize response: %v", err)
+	}
+	want := []PackResult{{PackSize: 400, Quantity: 1, Label: "Crate"}, {PackSize: 100, Quantity: 1}}
+	if !reflect.DeepEqual(result.Packs, want) {
+		t.Errorf("optimize with catalog eu = %+v, want %+v", result.Packs, want)
+	}
+	if !reflect.DeepEqual(PackSizes, []int{250, 500, 1000, 2000, 5000}) {
+		t.Errorf("default PackSizes changed to %v", PackSizes)
+	}
+
+	rec = postOptimize(t, `{"quantity": 450, "catalog": "missing"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("optimize with unknown catalog status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := putCatalog(t, "bad", `{"packSizes": [0]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /packages/bad with invalid sizes status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHealthReportsBrokenCatalogs(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	namedCatalogs.put("good", Catalog{PackSizes: []int{100, 300}})
+	namedCatalogs.put("empty", Catalog{})
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+	healthHandler(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /health status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var health struct {
+		Status   string                   `json:"status"`
+		Catalogs map[string]catalogHealth `json:"catalogs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatalf("decoding health: %v", err)
+	}
+	want := map[string]catalogHealth{
+		"default": {Healthy: true},
+		"good":    {Healthy: true},
+		"empty":   {Error: "catalog has no pack sizes"},
+	}
+	if health.Status != "degraded" || !reflect.DeepEqual(health.Catalogs, want) {
+		t.Errorf("GET /health = %+v, want degraded with %+v", health, want)
+	}
+}
+
+func TestValidateCatalogMemoryCeiling(t *testing.T) {
+	saved := maxDPSize
+	maxDPSize = 1000
+	t.Cleanup(func() { maxDPSize = saved })
+
+	if err := validateCatalog(Catalog{PackSizes: []int{250, 999}}); err != nil {
+		t.Errorf("validateCatalog within the ceiling returned error: %v", err)
+	}
+	if err := validateCatalog(Catalog{PackSizes: []int{250, 1000}}); err == nil {
+		t.Error("validateCatalog should reject a pack size at the memory ceiling")
+	}
+}
+
+// serveJSON sends a JSON request through the router.
+func serveJSON(t *testing.T, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTenantPathPrefix(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	withNamedCatalogs(t)
+
+	for tenant, body := range map[string]string{
+		"acme":   `{"packSizes": [300, 700]}`,
+		"globex": `{"packSizes": [{"size": 400, "label": "Crate"}]}`,
+	} {
+		if rec := serveJSON(t, http.MethodPost, "/t/"+tenant+"/packages", body); rec.Code != http.StatusOK {
+			t.Fatalf("POST /t/%s/packages status = %d: %s", tenant, rec.Code, rec.Body.String())
+		}
+	}
+
+	testCases := []struct {
+		tenant string
+		want   []PackResult
+	}{
+		{"acme", []PackResult{{PackSize: 700, Quantity: 1}, {PackSize: 300, Quantity: 1}}},
+		{"globex", []PackResult{{PackSize: 400, Quantity: 3, Label: "Crate"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tenant, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodPost, "/t/"+tc.tenant+"/optimize", `{"quantity": 1000}`)
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("POST /t/%s/optimize packs = %+v, want %+v", tc.tenant, result.Packs, tc.want)
+			}
+
+			rec = serveJSON(t, http.MethodGet, "/t/"+tc.tenant+"/packages", "")
+			var catalog Catalog
+			if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if len(catalog.PackSizes) != len(tc.want) {
+				t.Errorf("GET /t/%s/packages = %v", tc.tenant, catalog.PackSizes)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(PackSizes, []int{250, 500, 1000, 2000, 5000}) {
+		t.Errorf("tenant updates changed the default PackSizes to %v", PackSizes)
+	}
+	if rec := serveJSON(t, http.MethodPost, "/t/initech/optimize", `{"quantity": 1}`); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /t/initech/optimize status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := serveJSON(t, http.MethodPost, "/t/acme/optimize", `{"quantity": 1, "catalog": "globex"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /t/acme/optimize with another catalog status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestCatalogLimitAndDelete(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	namedCatalogs = newCatalogStore(2)
+
+	for _, name := range []string{"a", "b"} {
+		if rec := putCatalog(t, name, `{"packSizes": [100]}`); rec.Code != http.StatusOK {
+			t.Fatalf("PUT /packages/%s status = %d: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := putCatalog(t, "c", `{"packSizes": [100]}`); rec.Code != http.StatusConflict {
+		t.Errorf("PUT beyond the limit status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := putCatalog(t, "a", `{"packSizes": [200]}`); rec.Code != http.StatusOK {
+		t.Errorf("replacing a stored catalog at the limit status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := putCatalog(t, "default", `{"packSizes": [250, 500]}`); rec.Code != http.StatusOK {
+		t.Errorf("updating the default catalog at the limit status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if rec := serveJSON(t, http.MethodDelete, "/packages/b", ""); rec.Code != http.StatusOK {
+		t.Errorf("DELETE /packages/b status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if _, ok := namedCatalogs.lookup("b"); ok {
+		t.Error("catalog b is still stored after DELETE")
+	}
+	if rec := serveJSON(t, http.MethodDelete, "/packages/b", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second DELETE /packages/b status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := putCatalog(t, "c", `{"packSizes": [100]}`); rec.Code != http.StatusOK {
+		t.Errorf("PUT after a deletion freed a slot status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if rec := serveJSON(t, http.MethodDelete, "/packages/default", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("DELETE /packages/default status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !reflect.DeepEqual(PackSizes, []int{250, 500}) {
+		t.Errorf("PackSizes = %v after deleting default was refused", PackSizes)
+	}
+}
+
+func TestResetCatalog(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	if rec := serveJSON(t, http.MethodPost, "/packages", `{"packSizes": [{"size": 300, "label": "Odd"}, 700]}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/packages/reset", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages/reset status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var reset Catalog
+	if err := json.NewDecoder(rec.Body).Decode(&reset); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := []int{250, 500, 1000, 2000, 5000}
+	if !reflect.DeepEqual(reset.PackSizes, want) || len(reset.Labels) != 0 {
+		t.Errorf("POST /packages/reset = %+v, want %v without labels", reset, want)
+	}
+	if current, _ := namedCatalogs.lookup(defaultCatalogName); !reflect.DeepEqual(current.PackSizes, want) || len(current.Labels) != 0 {
+		t.Errorf("default catalog after reset = %+v, want %v", current, want)
+	}
+
+	PackSizes[0] = 1
+	if defaultPackSizes[0] != 250 {
+		t.Errorf("editing the reset catalog changed defaultPackSizes to %v", defaultPackSizes)
+	}
+
+	if rec := serveJSON(t, http.MethodGet, "/packages/reset", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /packages/reset status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestPatchCatalog(t *testing.T) {
+	testCases := []struct {
+		description string
+		body        string
+		wantStatus  int
+		wantSizes   []int
+	}{
+		{"add a size", `{"add": [3000]}`, http.StatusOK, []int{250, 500, 1000, 3000}},
+		{"remove a size", `{"remove": [250]}`, http.StatusOK, []int{500, 1000}},
+		{"add and remove", `{"add": [3000], "remove": [250, 1000]}`, http.StatusOK, []int{500, 3000}},
+		{"replace every size", `{"add": [42], "remove": [250, 500, 1000]}`, http.StatusOK, []int{42}},
+		{"remove every size", `{"remove": [250, 500, 1000]}`, http.StatusBadRequest, nil},
+		{"remove an absent size", `{"remove": [300]}`, http.StatusBadRequest, nil},
+		{"add an existing size", `{"add": [500]}`, http.StatusBadRequest, nil},
+		{"add a non-positive size", `{"add": [0]}`, http.StatusBadRequest, nil},
+		{"empty change", `{}`, http.StatusBadRequest, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500, 1000})
+			PackLabels = map[int]string{250: "Small", 1000: "Large"}
+
+			rec := serveJSON(t, http.MethodPatch, "/packages", tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("PATCH /packages %s status = %d, want %d: %s", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+
+			current, _ := namedCatalogs.lookup(defaultCatalogName)
+			if tc.wantStatus != http.StatusOK {
+				if want := []int{250, 500, 1000}; !reflect.DeepEqual(current.PackSizes, want) {
+					t.Errorf("PackSizes = %v after a rejected patch, want %v", current.PackSizes, want)
+				}
+				return
+			}
+
+			var response Catalog
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(response.PackSizes, tc.wantSizes) || !reflect.DeepEqual(current.PackSizes, tc.wantSizes) {
+				t.Errorf("PATCH /packages = %v, stored %v, want %v", response.PackSizes, current.PackSizes, tc.wantSizes)
+			}
+			for size := range current.Labels {
+				if !slices.Contains(tc.wantSizes, size) {
+					t.Errorf("label for removed size %d was kept", size)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchTenantCatalog(t *testing.T) {
+	withNamedCatalogs(t)
+
+	if rec := serveJSON(t, http.MethodPatch, "/t/acme/packages", `{"add": [3000]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH unknown tenant status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := serveJSON(t, http.MethodPost, "/t/acme/packages", `{"packSizes": [300, 700]}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /t/acme/packages status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveJSON(t, http.MethodPatch, "/t/acme/packages", `{"add": [1500], "remove": [300]}`); rec.Code != http.StatusOK {
+		t.Fatalf("PATCH /t/acme/packages status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if acme, _ := namedCatalogs.lookup("acme"); !reflect.DeepEqual(acme.PackSizes, []int{700, 1500}) {
+		t.Errorf("acme catalog = %v, want [700 1500]", acme.PackSizes)
+	}
+}
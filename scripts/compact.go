@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// compactContentType is the media type of a compact plan code.
+const compactContentType = "text/plain"
+
+// compactVersion is the version byte leading every compact plan code.
+// Change it whenever the layout below changes, so scanners can reject codes
+// they cannot read.
+const compactVersion = 1
+
+// CompactPlan is the content of a compact plan code: the order reference
+// and the pack lines, largest first.
+type CompactPlan struct {
+	Version  int          `json:"version"`
+	OrderRef string       `json:"orderRef,omitempty"`
+	Packs    []PackResult `json:"packs"`
+}
+
+// encodeCompactPlan packs the lines of result and orderRef into a short,
+// URL-safe base64 code for QR labels: the version byte, the length and
+// bytes of orderRef, the number of lines, then each line's pack size and
+// quantity, all as unsigned varints. Zero-quantity lines are left out, and
+// the same plan always gives the same code.
+func encodeCompactPlan(result *OptimizationResult, orderRef string) string {
+	buf := []byte{compactVersion}
+	buf = binary.AppendUvarint(buf, uint64(len(orderRef)))
+	buf = append(buf, orderRef...)
+
+	var lines []PackResult
+	for _, pack := range result.Packs {
+		if pack.Quantity > 0 {
+			lines = append(lines, pack)
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(lines)))
+	for _, pack := range lines {
+		buf = binary.AppendUvarint(buf, uint64(pack.PackSize))
+		buf = binary.AppendUvarint(buf, uint64(pack.Quantity))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// errMalformedCode reports a compact plan code that does not follow the
+// layout of its version.
+var errMalformedCode = errors.New("malformed plan code")
+
+// decodeCompactPlan reverses encodeCompactPlan.
+func decodeCompactPlan(code string) (*CompactPlan, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not URL-safe base64", errMalformedCode)
+	}
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("%w: empty", errMalformedCode)
+	}
+	if buf[0] != compactVersion {
+		return nil, fmt.Errorf("unsupported plan code version %d, want %d", buf[0], compactVersion)
+	}
+	buf = buf[1:]
+
+	next := func() (int, error) {
+		value, n := binary.Uvarint(buf)
+		if n <= 0 || value > uint64(maxDPSize) {
+			return 0, fmt.Errorf("%w: truncated or out of range", errMalformedCode)
+		}
+		buf = buf[n:]
+		return int(value), nil
+	}
+
+	refLength, err := next()
+	if err != nil {
+		return nil, err
+	}
+	if refLength > len(buf) {
+		return nil, fmt.Errorf("%w: truncated order reference", errMalformedCode)
+	}
+	plan := &CompactPlan{Version: compactVersion, OrderRef: string(buf[:refLength]), Packs: []PackResult{}}
+	buf = buf[refLength:]
+
+	lines, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < lines; i++ {
+		size, err := next()
+		if err != nil {
+			return nil, err
+		}
+		qty, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 || qty == 0 {
+			return nil, fmt.Errorf("%w: line %d needs a positive pack size and quantity", errMalformedCode, i+1)
+		}
+		plan.Packs = append(plan.Packs, PackResult{PackSize: size, Quantity: qty})
+	}
+	if len(buf) > 0 {
+		return nil, fmt.Errorf("%w: unexpected data after the last line", errMalformedCode)
+	}
+	return plan, nil
+}
+
+// HTTP handler decoding a compact plan code
+func decodePlanHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Code string `json:"code"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := decodeCompactPlan(request.Code)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
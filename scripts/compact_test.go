@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompactPlanRoundTrip(t *testing.T) {
+	result := &OptimizationResult{Packs: []PackResult{
+		{PackSize: 5000, Quantity: 2, Label: "Bulk"},
+		{PackSize: 2000, Quantity: 1},
+		{PackSize: 1000},
+		{PackSize: 250, Quantity: 1},
+	}}
+
+	code := encodeCompactPlan(result, "SO-1042")
+	if again := encodeCompactPlan(result, "SO-1042"); again != code {
+		t.Errorf("encoding is not deterministic: %q then %q", code, again)
+	}
+
+	plan, err := decodeCompactPlan(code)
+	if err != nil {
+		t.Fatalf("decodeCompactPlan(%q) returned error: %v", code, err)
+	}
+	want := &CompactPlan{
+		Version:  compactVersion,
+		OrderRef: "SO-1042",
+		Packs:    []PackResult{{PackSize: 5000, Quantity: 2}, {PackSize: 2000, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("decodeCompactPlan(%q) = %+v, want %+v", code, plan, want)
+	}
+}
+
+func TestDecodeCompactPlanRejectsMalformedCodes(t *testing.T) {
+	valid := encodeCompactPlan(&OptimizationResult{Packs: []PackResult{{PackSize: 500, Quantity: 1}}}, "A")
+
+	for _, code := range []string{"", "!!", "AQ", valid[:len(valid)-1], valid + "AA"} {
+		if _, err := decodeCompactPlan(code); !errors.Is(err, errMalformedCode) {
+			t.Errorf("decodeCompactPlan(%q) error = %v, want errMalformedCode", code, err)
+		}
+	}
+	if _, err := decodeCompactPlan("AQ"); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("decodeCompactPlan of a bare version byte error = %v, want truncated", err)
+	}
+	if _, err := decodeCompactPlan("CQ"); err == nil || !strings.Contains(err.Error(), "version 9") {
+		t.Errorf("decodeCompactPlan of version 9 error = %v, want an unsupported version", err)
+	}
+}
+
+func TestOptimizeCompactAndDecode(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?format=compact", strings.NewReader(`{"quantity": 12001, "orderRef": "SO-7"}`))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize?format=compact status = %d: %s", rec.Code, rec.Body.String())
+	}
+	code := strings.TrimSpace(rec.Body.String())
+
+	rec = serveJSON(t, http.MethodPost, "/breakdown/decode", `{"code": "`+code+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /breakdown/decode status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var plan CompactPlan
+	if err := json.NewDecoder(rec.Body).Decode(&plan); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := []PackResult{{PackSize: 5000, Quantity: 2}, {PackSize: 2000, Quantity: 1}, {PackSize: 250, Quantity: 1}}
+	if plan.OrderRef != "SO-7" || !reflect.DeepEqual(plan.Packs, want) {
+		t.Errorf("decoded plan = %+v, want SO-7 with %+v", plan, want)
+	}
+
+	if rec := serveJSON(t, http.MethodPost, "/breakdown/decode", `{"code": "!!"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /breakdown/decode of a malformed code status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
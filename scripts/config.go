@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envDuration reads a duration such as "500ms" from the environment, falling
+// back to def when the variable is unset or malformed.
+func envDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using default %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+// envInt reads an integer from the environment, falling back to def when the
+// variable is unset or malformed.
+func envInt(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using default %d", name, value, def)
+		return def
+	}
+	return n
+}
+
+// envBool reads a boolean such as "true" or "0" from the environment,
+// falling back to def when the variable is unset or malformed.
+func envBool(name string, def bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using default %t", name, value, def)
+		return def
+	}
+	return b
+}
+
+// envNetworks reads a comma-separated list of IP addresses and CIDR blocks
+// from the environment, skipping malformed entries.
+func envNetworks(name string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		cidr := entry
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Printf("invalid %s entry %q, skipping", name, entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
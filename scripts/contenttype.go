@@ -0,0 +1,48 @@
+package main
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// jsonBody guards the endpoints that decode a JSON request body.
+var jsonBody = requireContentType("application/json")
+
+// contentTypeGuard answers 415 to request bodies sent with a media type the
+// wrapped handler cannot read, so a form post is not reported as bad JSON.
+type contentTypeGuard struct {
+	allowed []string
+}
+
+// requireContentType returns a guard accepting the given media types.
+// Parameters such as charset are ignored.
+func requireContentType(allowed ...string) *contentTypeGuard {
+	return &contentTypeGuard{allowed: allowed}
+}
+
+func (g *contentTypeGuard) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+			next(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil && g.accepts(mediaType) {
+			next(w, r)
+			return
+		}
+		enableCORS(w, r)
+		writeError(w, "Unsupported Content-Type, want "+strings.Join(g.allowed, " or "), http.StatusUnsupportedMediaType)
+	}
+}
+
+func (g *contentTypeGuard) accepts(mediaType string) bool {
+	for _, allowed := range g.allowed {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
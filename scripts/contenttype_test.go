@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONBodyRejectsOtherContentTypes(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	testCases := []struct {
+		description string
+		contentType string
+		wantStatus  int
+	}{
+		{"json", "application/json", http.StatusOK},
+		{"json with charset", "application/json; charset=utf-8", http.StatusOK},
+		{"form encoded", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"plain text", "text/plain", http.StatusUnsupportedMediaType},
+		{"missing", "", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			newRouter().ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST with Content-Type %q status = %d, want %d: %s", tc.contentType, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus == http.StatusUnsupportedMediaType {
+				if got := errorMessage(t, rec); got != "Unsupported Content-Type, want application/json" {
+					t.Errorf("error message = %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONBodyIgnoresRequestsWithoutBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/packages", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /packages status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// costPrecision is the number of decimal places reported in TotalCost.
+var costPrecision = envInt("COST_PRECISION", 2)
+
+// maxCostPrecision is the largest costPrecision: a float64 holds about 15
+// significant decimal digits, and beyond that the rounding scale only adds
+// error, overflowing to infinity past 308.
+const maxCostPrecision = 15
+
+// currencyMinorUnits is the number of decimal places of each supported
+// currency's minor unit.
+var currencyMinorUnits = map[string]int{
+	"AUD": 2, "CAD": 2, "CHF": 2, "EUR": 2, "GBP": 2, "USD": 2,
+	"JPY": 0, "KRW": 0,
+	"BHD": 3, "KWD": 3,
+}
+
+// price is the cost of one pack, given in a request either as a plain number
+// or as {"amount": 1.10, "currency": "USD"}.
+type price struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+func (p *price) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		type plain price
+		if err := dec.Decode((*plain)(p)); err != nil {
+			return &fieldError{"costs entries must be numbers or {\"amount\", \"currency\"} objects"}
+		}
+		p.Currency = strings.ToUpper(p.Currency)
+		return nil
+	}
+	if err := json.Unmarshal(data, &p.Amount); err != nil {
+		return &fieldError{"costs entries must be numbers or {\"amount\", \"currency\"} objects"}
+	}
+	return nil
+}
+
+func (p price) MarshalJSON() ([]byte, error) {
+	if p.Currency == "" {
+		return json.Marshal(p.Amount)
+	}
+	type plain price
+	return json.Marshal(plain(p))
+}
+
+// costTable is the costs field of a request, keyed by pack size.
+type costTable map[int]price
+
+// split returns the amounts of c and their shared currency, which is empty
+// when no entry names one. Mixing currencies, or priced entries with and
+// without a currency, is an error.
+func (c costTable) split() (map[int]float64, string, error) {
+	if c == nil {
+		return nil, "", nil
+	}
+	amounts := make(map[int]float64, len(c))
+	currencies := map[string]bool{}
+	for size, p := range c {
+		amounts[size] = p.Amount
+		currencies[p.Currency] = true
+	}
+	if len(currencies) > 1 {
+		names := make([]string, 0, len(currencies))
+		for name := range currencies {
+			if name == "" {
+				name = "(none)"
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, "", fmt.Errorf("costs mix currencies %s; use one currency per request", strings.Join(names, ", "))
+	}
+	for currency := range currencies {
+		return amounts, currency, nil
+	}
+	return amounts, "", nil
+}
+
+// costTolerance is the relative difference below which two costs are treated
+// as equal, so float noise cannot flip the choice between equal-cost options.
+const costTolerance = 1e-9
+
+// validateCosts checks that opts prices every size when costs are given or
+// required by the mode.
+func validateCosts(opts OptimizeOptions, sizes []int) error {
+	if _, ok := currencyMinorUnits[opts.Currency]; opts.Currency != "" && !ok {
+		return fmt.Errorf("unknown currency %q", opts.Currency)
+	}
+	if opts.Costs == nil {
+		if opts.Mode == ModeCheapest {
+			return fmt.Errorf("mode %q requires costs", ModeCheapest)
+		}
+		return nil
+	}
+
+	known := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		known[size] = true
+		cost, ok := opts.Costs[size]
+		if !ok {
+			return fmt.Errorf("missing cost for pack size %d", size)
+		}
+		if cost < 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return fmt.Errorf("cost for pack size %d must be a non-negative number", size)
+		}
+	}
+	for size := range opts.Costs {
+		if !known[size] {
+			return fmt.Errorf("cost given for unknown pack size %d", size)
+		}
+	}
+	return nil
+}
+
+// costLess reports whether cost a is meaningfully lower than cost b.
+func costLess(a, b float64) bool {
+	return a < b-costTolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// roundCost rounds a cost to costPrecision decimal places, or, when a
+// currency is given, to the currency's minor unit with banker's rounding, so
+// an exact half cent goes to the even cent. Float noise below a millionth of
+// the minor unit is dropped first so that, say, 1.015 is treated as a half.
+func roundCost(cost float64, currency string) float64 {
+	if currency == "" {
+		scale := math.Pow(10, float64(costPrecision))
+		return math.Round(cost*scale) / scale
+	}
+	scale := math.Pow(10, float64(currencyMinorUnits[currency]))
+	units := math.Round(cost*scale*1e6) / 1e6
+	return math.RoundToEven(units) / scale
+}
+
+// breakdownCost is the unrounded total cost of a breakdown.
+func breakdownCost(counts map[int]int, costs map[int]float64) float64 {
+	total := 0.0
+	for size, qty := range counts {
+		total += float64(qty) * costs[size]
+	}
+	return total
+}
+
+// cheapestBreakdown finds the lowest-cost breakdown whose total lies in
+// [lo, hi], breaking near-equal costs by waste and then by pack count. It
+// returns -1 when no total in the range is reachable. sizes must be sorted in
+// descending order and hi must not exceed maxSize.
+func cheapestBreakdown(ctx context.Context, sizes []int, lo, hi, maxSize int, costs map[int]float64) (int, map[int]int, error) {
+	type costEntry struct {
+		cost  float64
+		packs int
+		pack  int
+	}
+
+	dp := make([]costEntry, maxSize+1)
+	for i := range dp {
+		dp[i].packs = math.MaxInt32
+	}
+	dp[0] = costEntry{}
+
+	for i := 0; i <= maxSize; i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := stopped(ctx); err != nil {
+				return 0, nil, err
+			}
+		}
+		if dp[i].packs == math.MaxInt32 {
+			continue
+		}
+		for _, pack := range sizes {
+			next := i + pack
+			if next > maxSize {
+				continue
+			}
+			candidate := costEntry{cost: dp[i].cost + costs[pack], packs: dp[i].packs + 1, pack: pack}
+			if dp[next].packs == math.MaxInt32 || costLess(candidate.cost, dp[next].cost) ||
+				(!costLess(dp[next].cost, candidate.cost) && candidate.packs < dp[next].packs) {
+				dp[next] = candidate
+			}
+		}
+	}
+
+	bestAmount := -1
+	for i := lo; i <= hi; i++ {
+		if dp[i].packs == math.MaxInt32 {
+			continue
+		}
+		if bestAmount == -1 || costLess(dp[i].cost, dp[bestAmount].cost) {
+			bestAmount = i
+		}
+	}
+	if bestAmount == -1 {
+		return -1, nil, nil
+	}
+
+	counts := make(map[int]int)
+	for cur := bestAmount; cur > 0; cur -= dp[cur].pack {
+		counts[dp[cur].pack]++
+	}
+	return bestAmount, counts, nil
+}
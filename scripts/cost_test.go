@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptimizePacksCheapest(t *testing.T) {
+	testCases := []struct {
+		costs       map[int]float64
+		input       int
+		wantPacks   []PackResult
+		wantCost    float64
+		description string
+	}{
+		{map[int]float64{250: 1, 500: 3}, 500, []PackResult{{PackSize: 250, Quantity: 2}}, 2, "Two small packs are cheaper"},
+		{map[int]float64{250: 1, 500: 1.5}, 501, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 2.5, "Cheapest keeps waste low"},
+		{map[int]float64{250: 5, 500: 1}, 1, []PackResult{{PackSize: 500, Quantity: 1}}, 1, "Cheaper pack beats less waste"},
+		{map[int]float64{250: 0.1, 500: 0.2}, 750, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 0.3, "Float noise is rounded"},
+		{map[int]float64{250: 0.1, 500: 0.2000000000001}, 500, []PackResult{{PackSize: 500, Quantity: 1}}, 0.2, "Near-equal costs prefer fewer packs"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500})
+
+			result, err := OptimizePacksWithOptions(tc.input, OptimizeOptions{Mode: ModeCheapest, Costs: tc.costs})
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(%d) returned error: %v", tc.input, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("OptimizePacksWithOptions(%d) packs = %v, want %v", tc.input, result.Packs, tc.wantPacks)
+			}
+			if result.TotalCost == nil || *result.TotalCost != tc.wantCost {
+				t.Errorf("OptimizePacksWithOptions(%d) total cost = %v, want %v", tc.input, result.TotalCost, tc.wantCost)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerRoundsCost(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	body := `{"quantity": 750, "costs": {"250": 0.1, "500": 0.2}}`
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+
+	if !strings.Contains(rec.Body.String(), `"totalCost":0.3}`) {
+		t.Errorf("POST /optimize body = %s, want totalCost 0.3", rec.Body.String())
+	}
+
+	var result OptimizationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.TotalItems != 750 || result.TotalPacks != 2 {
+		t.Errorf("costs changed the default solution: %+v", result)
+	}
+}
+
+func TestRoundCostPrecision(t *testing.T) {
+	saved := costPrecision
+	t.Cleanup(func() { costPrecision = saved })
+
+	testCases := []struct {
+		precision int
+		cost      float64
+		want      float64
+	}{
+		{2, 6.000000000001, 6},
+		{2, 0.1 + 0.2, 0.3},
+		{2, 1.005000001, 1.01},
+		{0, 2.6, 3},
+		{3, 1.23456, 1.235},
+		{maxCostPrecision, 1.23456, 1.23456},
+	}
+
+	for _, tc := range testCases {
+		costPrecision = tc.precision
+		if got := roundCost(tc.cost, ""); got != tc.want {
+			t.Errorf("roundCost(%v) at precision %d = %v, want %v", tc.cost, tc.precision, got, tc.want)
+		}
+	}
+}
+
+func TestOptimizePacksRejectsInvalidCosts(t *testing.T) {
+	testCases := []struct {
+		opts        OptimizeOptions
+		description string
+	}{
+		{OptimizeOptions{Mode: ModeCheapest}, "Cheapest without costs"},
+		{OptimizeOptions{Costs: map[int]float64{250: 1}}, "Missing size"},
+		{OptimizeOptions{Costs: map[int]float64{250: 1, 500: -1}}, "Negative cost"},
+		{OptimizeOptions{Costs: map[int]float64{250: 1, 500: 1, 300: 1}}, "Unknown size"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500})
+
+			if _, err := OptimizePacksWithOptions(100, tc.opts); err == nil {
+				t.Errorf("OptimizePacksWithOptions(100, %+v) should return error", tc.opts)
+			}
+		})
+	}
+}
+
+// withCurrency registers a currency with the given minor unit for a test.
+func withCurrency(t *testing.T, code string, minorUnits int) {
+	t.Helper()
+	currencyMinorUnits[code] = minorUnits
+	t.Cleanup(func() { delete(currencyMinorUnits, code) })
+}
+
+func TestRoundCostCurrency(t *testing.T) {
+	withCurrency(t, "XTS", 0)
+
+	testCases := []struct {
+		currency string
+		cost     float64
+		want     float64
+	}{
+		{"USD", 1.234, 1.23},
+		{"USD", 1.005, 1.00},
+		{"USD", 1.015, 1.02},
+		{"USD", 2.675, 2.68},
+		{"USD", 0.125, 0.12},
+		{"USD", 0.1 + 0.2, 0.3},
+		{"XTS", 2.5, 2},
+		{"XTS", 3.5, 4},
+		{"XTS", 2.6, 3},
+		{"XTS", 0.5, 0},
+	}
+
+	for _, tc := range testCases {
+		if got := roundCost(tc.cost, tc.currency); got != tc.want {
+			t.Errorf("roundCost(%v, %s) = %v, want %v", tc.cost, tc.currency, got, tc.want)
+		}
+	}
+}
+
+func TestOptimizeHandlerCurrency(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withCurrency(t, "XTS", 0)
+
+	testCases := []struct {
+		description  string
+		costs        string
+		wantStatus   int
+		wantCost     float64
+		wantCurrency string
+	}{
+		{"USD rounds half to even cent", `{"250": {"amount": 0.125, "currency": "USD"}, "500": {"amount": 0.3, "currency": "USD"}}`, http.StatusOK, 0.12, "USD"},
+		{"lowercase code", `{"250": {"amount": 0.135, "currency": "usd"}, "500": {"amount": 0.3, "currency": "usd"}}`, http.StatusOK, 0.14, "USD"},
+		{"zero-decimal currency", `{"250": {"amount": 2.5, "currency": "XTS"}, "500": {"amount": 4, "currency": "XTS"}}`, http.StatusOK, 2, "XTS"},
+		{"plain numbers keep no currency", `{"250": 0.125, "500": 0.3}`, http.StatusOK, 0.13, ""},
+		{"mixed currencies", `{"250": {"amount": 1, "currency": "USD"}, "500": {"amount": 2, "currency": "EUR"}}`, http.StatusBadRequest, 0, ""},
+		{"currency and plain number", `{"250": {"amount": 1, "currency": "USD"}, "500": 2}`, http.StatusBadRequest, 0, ""},
+		{"unknown currency", `{"250": {"amount": 1, "currency": "ABC"}, "500": {"amount": 2, "currency": "ABC"}}`, http.StatusBadRequest, 0, ""},
+		{"malformed entry", `{"250": {"price": 1}, "500": 2}`, http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, `{"quantity": 250, "costs": `+tc.costs+`}`)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /optimize costs %s status = %d, want %d: %s", tc.costs, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.TotalCost == nil || *result.TotalCost != tc.wantCost || result.Currency != tc.wantCurrency {
+				t.Errorf("POST /optimize costs %s = %v %q, want %v %q", tc.costs, result.TotalCost, result.Currency, tc.wantCost, tc.wantCurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// requestTimeout is the response time budget applied to every endpoint.
+var requestTimeout = envDuration("REQUEST_TIMEOUT", 10*time.Second)
+
+// withDeadline enforces a response time budget on next. The handler runs
+// against a buffered response under a context that expires after timeout; if
+// it has not finished by then the client receives 503 and the offending
+// request is logged. The optimize solvers and the analyses that build DP or
+// reachability tables watch the context, so an abandoned request stops soon
+// after and frees its limiter slot; the infeasible-range table and the
+// catalog warnings of POST /packages do not and run to completion. A panic
+// in next is re-raised on the serving goroutine, where net/http recovers it,
+// instead of crashing the server; one raised after the 503 is only logged.
+// A non-positive timeout disables the guard.
+func withDeadline(timeout time.Duration, next http.Handler) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		buf := newResponseBuffer()
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer close(done)
+			defer func() {
+				if p := recover(); p != nil {
+					if ctx.Err() != nil {
+						log.Printf("panic after the %s budget expired: %s %s: %v", timeout, r.Method, r.URL.Path, p)
+					}
+					panicked <- p
+				}
+			}()
+			next.ServeHTTP(buf, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			buf.writeTo(w)
+		case <-ctx.Done():
+			log.Printf("request exceeded %s budget: %s %s query=%q", timeout, r.Method, r.URL.Path, r.URL.RawQuery)
+			writeError(w, "Request timed out", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// solveContext is the context a solve under opts watches; without one it
+// never stops early.
+func solveContext(opts OptimizeOptions) context.Context {
+	if opts.Context == nil {
+		return context.Background()
+	}
+	return opts.Context
+}
+
+// stopped reports why the solve under opts was abandoned, or nil while it
+// may go on.
+func stopped(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("optimization stopped: %w", err)
+	}
+	return nil
+}
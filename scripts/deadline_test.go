@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithDeadlineTimesOutSlowHandler(t *testing.T) {
+	var logs bytes.Buffer
+	saved := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(saved) })
+
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("too late"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?trace=1", nil)
+	rec := httptest.NewRecorder()
+	withDeadline(20*time.Millisecond, slow).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("slow handler status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if strings.Contains(rec.Body.String(), "too late") {
+		t.Errorf("slow handler output leaked into the timed out response: %q", rec.Body.String())
+	}
+	if got := logs.String(); !strings.Contains(got, "POST /optimize") || !strings.Contains(got, "trace=1") {
+		t.Errorf("log = %q, want the offending endpoint and parameters", got)
+	}
+}
+
+func TestWithDeadlinePassesFastHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 251}`))
+	rec := httptest.NewRecorder()
+	withDeadline(time.Second, http.HandlerFunc(optimizeHandler)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("fast handler status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("fast handler Content-Type = %q, want application/json", got)
+	}
+	if !strings.Contains(rec.Body.String(), `"totalItems":500`) {
+		t.Errorf("fast handler body = %q, want the optimize result", rec.Body.String())
+	}
+}
+
+func TestWithDeadlineRepanicsOnServingGoroutine(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recovered %v, want the handler's panic", p)
+		}
+	}()
+	req := httptest.NewRequest(http.MethodPost, "/optimize", nil)
+	withDeadline(time.Second, panicking).ServeHTTP(httptest.NewRecorder(), req)
+	t.Error("withDeadline returned instead of re-panicking")
+}
+
+func TestCanceledContextStopsSolve(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}}
+	if _, err := OptimizeCatalog(1_000_000, catalog, OptimizeOptions{Context: ctx}); !errors.Is(err, context.Canceled) {
+		t.Errorf("OptimizeCatalog error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := OptimizeBatch([]int{500, 750}, catalog, OptimizeOptions{Context: ctx}); !errors.Is(err, context.Canceled) {
+		t.Errorf("OptimizeBatch error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := OptimizeCatalog(750, catalog, OptimizeOptions{Context: context.Background()}); err != nil {
+		t.Errorf("OptimizeCatalog with a live context returned error: %v", err)
+	}
+}
+
+func TestCanceledContextStopsAnalyses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sizes := []int{1000, 500, 250}
+	catalog := Catalog{PackSizes: sizes}
+	checks := map[string]func() error{
+		"cheapestBreakdown": func() error {
+			_, _, err := cheapestBreakdown(ctx, sizes, 750, 1750, 1750, map[int]float64{250: 1, 500: 1.5, 1000: 2.5})
+			return err
+		},
+		"toleranceLadder": func() error {
+			_, err := toleranceLadder(750, catalog, OptimizeOptions{Context: ctx}, []float64{0.1})
+			return err
+		},
+		"suggestUpsell": func() error {
+			_, err := suggestUpsell(ctx, &OptimizationResult{OrderQuantity: 751}, catalog)
+			return err
+		},
+		"minimumWaste": func() error {
+			_, err := minimumWaste(ctx, 751, sizes)
+			return err
+		},
+		"zeroWasteCoverage": func() error {
+			_, err := zeroWasteCoverage(ctx, sizes, 1000)
+			return err
+		},
+		"worstWaste": func() error {
+			_, err := worstWaste(ctx, sizes, 1000)
+			return err
+		},
+		"overfillWaste": func() error {
+			_, err := overfillWaste(ctx, []int{751}, sizes)
+			return err
+		},
+	}
+	for name, check := range checks {
+		if err := check(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s error = %v, want %v", name, err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// optimizeDedupe collapses identical optimize submissions from the same client.
+var optimizeDedupe = newDeduper(envDuration("DEDUPE_WINDOW", 500*time.Millisecond))
+
+// responseBuffer is an http.ResponseWriter that keeps the response in memory
+// so it can be replayed to several clients.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *responseBuffer) Header() http.Header         { return b.header }
+func (b *responseBuffer) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *responseBuffer) WriteHeader(status int)      { b.status = status }
+
+// writeTo replays the buffered response onto w.
+func (b *responseBuffer) writeTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
+
+// dedupeEntry is a single in-flight or recently completed computation.
+type dedupeEntry struct {
+	done     chan struct{}
+	response *responseBuffer
+	expires  time.Time
+}
+
+// deduper serves the first computed response to every identical POST body
+// received from the same client IP while the computation is in flight and for
+// window afterwards, so retry storms cost a single optimization.
+type deduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*dedupeEntry
+}
+
+func newDeduper(window time.Duration) *deduper {
+	return &deduper{window: window, entries: make(map[string]*dedupeEntry)}
+}
+
+func (d *deduper) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || d.window <= 0 {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		// The same body asked for in another representation is a different
+		// response.
+		key := clientIP(r) + " " + r.URL.RequestURI() + " " + r.Header.Get("Accept") + " " + hex.EncodeToString(sum[:])
+
+		now := time.Now()
+		d.mu.Lock()
+		for k, e := range d.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(d.entries, k)
+			}
+		}
+		entry, shared := d.entries[key]
+		if !shared {
+			entry = &dedupeEntry{done: make(chan struct{})}
+			d.entries[key] = entry
+		}
+		d.mu.Unlock()
+
+		if !shared {
+			d.lead(key, entry, next, r)
+		}
+
+		<-entry.done
+		entry.response.writeTo(w)
+	}
+}
+
+// lead computes entry's response for the requests sharing key. If next
+// panics, the waiters get a 500 instead of blocking forever, the entry is
+// dropped so a retry computes afresh, and the panic carries on.
+func (d *deduper) lead(key string, entry *dedupeEntry, next http.HandlerFunc, r *http.Request) {
+	completed := false
+	defer func() {
+		d.mu.Lock()
+		if completed {
+			entry.expires = time.Now().Add(d.window)
+		} else {
+			delete(d.entries, key)
+			entry.response = newResponseBuffer()
+			writeError(entry.response, "Internal server error", http.StatusInternalServerError)
+		}
+		d.mu.Unlock()
+		close(entry.done)
+	}()
+
+	entry.response = newResponseBuffer()
+	next(entry.response, r)
+	completed = true
+}
+
+// clientIP returns the remote IP of r without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeduperCollapsesConcurrentIdenticalRequests(t *testing.T) {
+	var runs atomic.Int32
+	handler := newDeduper(500 * time.Millisecond).wrap(func(w http.ResponseWriter, r *http.Request) {
+		runs.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		optimizeHandler(w, r)
+	})
+
+	const n = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	bodies := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 501}`))
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("request %d status = %d, want %d", i, rec.Code, http.StatusOK)
+			}
+			bodies[i] = rec.Body.String()
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("OptimizePacks ran %d times for %d identical requests, want 1", got, n)
+	}
+	for i, body := range bodies {
+		if body != bodies[0] {
+			t.Errorf("request %d body = %q, want %q", i, body, bodies[0])
+		}
+	}
+}
+
+func TestDeduperKeysByClientQueryAndBody(t *testing.T) {
+	var runs atomic.Int32
+	handler := newDeduper(time.Minute).wrap(func(w http.ResponseWriter, r *http.Request) {
+		runs.Add(1)
+		optimizeHandler(w, r)
+	})
+
+	send := func(remoteAddr, target, body string) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		handler(httptest.NewRecorder(), req)
+	}
+
+	send("10.0.0.1:1000", "/optimize", `{"quantity": 1}`)
+	send("10.0.0.1:2000", "/optimize", `{"quantity": 1}`)
+	if got := runs.Load(); got != 1 {
+		t.Errorf("same client and body ran %d times, want 1", got)
+	}
+
+	send("10.0.0.2:1000", "/optimize", `{"quantity": 1}`)
+	send("10.0.0.1:1000", "/optimize", `{"quantity": 2}`)
+	send("10.0.0.1:1000", "/optimize?tree=true", `{"quantity": 1}`)
+	if got := runs.Load(); got != 4 {
+		t.Errorf("different clients, bodies or queries ran %d times, want 4", got)
+	}
+}
+
+func TestDeduperExpiresAfterWindow(t *testing.T) {
+	var runs atomic.Int32
+	handler := newDeduper(10 * time.Millisecond).wrap(func(w http.ResponseWriter, r *http.Request) {
+		runs.Add(1)
+		optimizeHandler(w, r)
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`))
+		handler(httptest.NewRecorder(), req)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if got := runs.Load(); got != 2 {
+		t.Errorf("requests outside the window ran %d times, want 2", got)
+	}
+}
+
+func TestDeduperReleasesWaitersWhenLeaderPanics(t *testing.T) {
+	var runs atomic.Int32
+	joined := make(chan struct{})
+	handler := newDeduper(500 * time.Millisecond).wrap(func(w http.ResponseWriter, r *http.Request) {
+		if runs.Add(1) == 1 {
+			// Give the waiter time to join before the leader fails.
+			<-joined
+			time.Sleep(50 * time.Millisecond)
+			panic("boom")
+		}
+		optimizeHandler(w, r)
+	})
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 501}`))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	leaderDone := make(chan any)
+	go func() {
+		defer func() { leaderDone <- recover() }()
+		send()
+	}()
+	for runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	waiter := make(chan *httptest.ResponseRecorder)
+	go func() { waiter <- send() }()
+	close(joined)
+
+	if p := <-leaderDone; p != "boom" {
+		t.Errorf("leader recovered %v, want its handler's panic", p)
+	}
+	select {
+	case rec := <-waiter:
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("waiter status = %d, want %d", rec.Code, http.StatusInternalServerError)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiter still blocked after the leader panicked")
+	}
+
+	if rec := send(); rec.Code != http.StatusOK || runs.Load() < 2 {
+		t.Errorf("retry status = %d after %d runs, want a fresh 200", rec.Code, runs.Load())
+	}
+}
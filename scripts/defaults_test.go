@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCatalogDefaults(t *testing.T) {
+	withNamedCatalogs(t)
+
+	if rec := putCatalog(t, "tight", `{"packSizes": [250, 500, 1000], "defaults": {"maxWaste": 100}}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /packages/tight status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := putCatalog(t, "loose", `{"packSizes": [250, 500, 1000]}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /packages/loose status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	testCases := []struct {
+		description string
+		body        string
+		wantStatus  int
+	}{
+		{"without defaults", `{"quantity": 501, "catalog": "loose"}`, http.StatusOK},
+		{"default maxWaste rejects", `{"quantity": 501, "catalog": "tight"}`, http.StatusUnprocessableEntity},
+		{"default maxWaste allows", `{"quantity": 700, "catalog": "tight"}`, http.StatusOK},
+		{"request overrides the default", `{"quantity": 501, "catalog": "tight", "maxWaste": 250}`, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if rec := postOptimize(t, tc.body); rec.Code != tc.wantStatus {
+				t.Errorf("POST /optimize %s status = %d, want %d: %s", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	rec := postOptimize(t, `{"quantity": 501, "catalog": "tight", "maxWaste": 250, "echoRequest": true}`)
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Waste != 249 || result.Request == nil || result.Request.MaxWaste != 250 {
+		t.Errorf("overridden result = waste %d, echo %+v; want waste 249 under maxWaste 250", result.Waste, result.Request)
+	}
+}
+
+func TestCatalogDefaultsApplyPolicyAndPackLimit(t *testing.T) {
+	withNamedCatalogs(t)
+	putCatalog(t, "exact", `{"packSizes": [250, 500], "defaults": {"policy": "exact", "maxPacks": 2}}`)
+
+	if rec := postOptimize(t, `{"quantity": 501, "catalog": "exact"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("501 under a default exact policy status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if rec := postOptimize(t, `{"quantity": 1250, "catalog": "exact"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("1250 under a default maxPacks of 2 status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if rec := postOptimize(t, `{"quantity": 501, "catalog": "exact", "policy": "overfill"}`); rec.Code != http.StatusOK {
+		t.Errorf("501 overriding the default policy status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestCatalogDefaultsApplyToUploads(t *testing.T) {
+	withNamedCatalogs(t)
+	putCatalog(t, "exact", `{"packSizes": [250, 500], "defaults": {"policy": "exact", "maxPacks": 2}}`)
+
+	testCases := []struct {
+		description string
+		fields      map[string]string
+		wantFailed  int
+	}{
+		{"catalog defaults", map[string]string{"catalog": "exact"}, 2},
+		{"policy overrides the default", map[string]string{"catalog": "exact", "policy": "overfill"}, 1},
+		{"default catalog", nil, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postUploadForm(t, "500\n501\n1250\n", "", tc.fields)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize/upload status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var upload UploadResult
+			if err := json.NewDecoder(rec.Body).Decode(&upload); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if upload.Summary.Failed != tc.wantFailed {
+				t.Errorf("upload with %v failed %d rows, want %d: %+v", tc.fields, upload.Summary.Failed, tc.wantFailed, upload.Results)
+			}
+		})
+	}
+}
+
+func TestCatalogDefaultsValidation(t *testing.T) {
+	withNamedCatalogs(t)
+
+	for _, body := range []string{
+		`{"packSizes": [250, 500], "defaults": {"policy": "sometimes"}}`,
+		`{"packSizes": [250, 500], "defaults": {"maxWaste": -1}}`,
+		`{"packSizes": [250, 500], "defaults": {"maxPacks": -1}}`,
+		`{"packSizes": [250, 500], "defaults": {"maxWastePerSize": {"1000": 0}}}`,
+		`{"packSizes": [250, 500], "defaults": {"inventory": 10}}`,
+	} {
+		if rec := putCatalog(t, "bad", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT /packages/bad %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if rec := putCatalog(t, defaultCatalogName, `{"packSizes": [250], "defaults": {"maxWaste": 10}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /packages/default with defaults status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
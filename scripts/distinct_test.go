@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSolveDuplicatedSizes(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+	}{
+		{"fewest packs", []int{500, 500, 250, 250}, 750, OptimizeOptions{},
+			[]PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}},
+		{"fewest lines", []int{1000, 1000, 500, 250}, 3000, OptimizeOptions{Mode: ModeFewestLines},
+			[]PackResult{{PackSize: 1000, Quantity: 3}}},
+		{"cheapest", []int{500, 250, 250}, 500, OptimizeOptions{Mode: ModeCheapest, Costs: map[int]float64{250: 1, 500: 3}},
+			[]PackResult{{PackSize: 250, Quantity: 2}}},
+		{"single size", []int{250, 250}, 600, OptimizeOptions{},
+			[]PackResult{{PackSize: 250, Quantity: 3}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			sizes := append([]int(nil), tc.sizes...)
+			result, err := solve(tc.quantity, sizes, tc.opts)
+			if err != nil {
+				t.Fatalf("solve(%d, %v) returned error: %v", tc.quantity, tc.sizes, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			packs := 0
+			for _, pack := range tc.want {
+				packs += pack.Quantity
+			}
+			if result.TotalPacks != packs {
+				t.Errorf("totalPacks = %d, want %d", result.TotalPacks, packs)
+			}
+			if !reflect.DeepEqual(sizes, tc.sizes) {
+				t.Errorf("solve modified sizes to %v", sizes)
+			}
+		})
+	}
+}
+
+func TestOptimizeCatalogDuplicatedSizes(t *testing.T) {
+	result, err := OptimizeCatalog(501, Catalog{PackSizes: []int{250, 500, 250, 1000, 500}}, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(501) returned error: %v", err)
+	}
+	want := []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}
+	if !reflect.DeepEqual(result.Packs, want) || result.TotalPacks != 2 || result.TotalItems != 750 {
+		t.Errorf("result = %+v, want 500 + 250", result)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// FulfillmentCenter is a warehouse with its own catalog. Capacity caps the
+// order units it can be allocated; zero means unlimited.
+type FulfillmentCenter struct {
+	Name      string `json:"name"`
+	PackSizes []int  `json:"packSizes"`
+	Capacity  int    `json:"capacity"`
+}
+
+// CenterAllocation is the portion of an order assigned to one center.
+type CenterAllocation struct {
+	Center   string              `json:"center"`
+	Quantity int                 `json:"quantity"`
+	Result   *OptimizationResult `json:"result"`
+}
+
+// DistributedResult is an order split across fulfillment centers.
+type DistributedResult struct {
+	OrderQuantity int                `json:"orderQuantity"`
+	TotalItems    int                `json:"totalItems"`
+	TotalPacks    int                `json:"totalPacks"`
+	Waste         int                `json:"waste"`
+	Allocations   []CenterAllocation `json:"allocations"`
+}
+
+// OptimizeDistributed allocates orderQuantity across centers greedily: each
+// round, every unused center plans the largest portion it can take and the
+// plan with the least waste, then fewest packs, then largest portion wins.
+// Each center is used at most once. This is a heuristic and does not
+// guarantee the globally optimal split.
+func OptimizeDistributed(orderQuantity int, centers []FulfillmentCenter) (*DistributedResult, error) {
+	if orderQuantity <= 0 {
+		return nil, fmt.Errorf("order quantity must be positive")
+	}
+	if len(centers) == 0 {
+		return nil, fmt.Errorf("at least one center is required")
+	}
+	names := make(map[string]bool, len(centers))
+	for _, center := range centers {
+		if center.Name == "" {
+			return nil, fmt.Errorf("every center needs a name")
+		}
+		if names[center.Name] {
+			return nil, fmt.Errorf("center names must be unique: %q", center.Name)
+		}
+		names[center.Name] = true
+		if len(center.PackSizes) == 0 {
+			return nil, fmt.Errorf("center %q has no pack sizes", center.Name)
+		}
+		if err := validatePackSizes(center.PackSizes); err != nil {
+			return nil, fmt.Errorf("center %q: %v", center.Name, err)
+		}
+		if center.Capacity < 0 {
+			return nil, fmt.Errorf("center %q capacity must not be negative", center.Name)
+		}
+	}
+
+	distributed := &DistributedResult{OrderQuantity: orderQuantity, Allocations: []CenterAllocation{}}
+	used := make([]bool, len(centers))
+	for remaining := orderQuantity; remaining > 0; {
+		var best *CenterAllocation
+		bestIndex := -1
+		for i, center := range centers {
+			if used[i] {
+				continue
+			}
+			portion := remaining
+			if center.Capacity > 0 && center.Capacity < portion {
+				portion = center.Capacity
+			}
+			result, err := solve(portion, sortedDescending(center.PackSizes), OptimizeOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("center %q: %w", center.Name, err)
+			}
+			candidate := &CenterAllocation{Center: center.Name, Quantity: portion, Result: result}
+			if best == nil || betterAllocation(candidate, best) {
+				best, bestIndex = candidate, i
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("centers lack capacity for %d of %d units", remaining, orderQuantity)
+		}
+
+		used[bestIndex] = true
+		remaining -= best.Quantity
+		distributed.Allocations = append(distributed.Allocations, *best)
+		distributed.TotalItems += best.Result.TotalItems
+		distributed.TotalPacks += best.Result.TotalPacks
+	}
+	distributed.Waste = distributed.TotalItems - orderQuantity
+	return distributed, nil
+}
+
+// betterAllocation orders candidate allocations by waste, packs, then portion.
+func betterAllocation(a, b *CenterAllocation) bool {
+	if a.Result.Waste != b.Result.Waste {
+		return a.Result.Waste < b.Result.Waste
+	}
+	if a.Result.TotalPacks != b.Result.TotalPacks {
+		return a.Result.TotalPacks < b.Result.TotalPacks
+	}
+	return a.Quantity > b.Quantity
+}
+
+// HTTP handler for splitting an order across fulfillment centers
+func distributedHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Quantity int                 `json:"quantity"`
+		Centers  []FulfillmentCenter `json:"centers"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := OptimizeDistributed(request.Quantity, request.Centers)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptimizeDistributedSplitsAcrossCenters(t *testing.T) {
+	centers := []FulfillmentCenter{
+		{Name: "east", PackSizes: []int{250}, Capacity: 600},
+		{Name: "west", PackSizes: []int{500}, Capacity: 500},
+	}
+
+	result, err := OptimizeDistributed(1000, centers)
+	if err != nil {
+		t.Fatalf("OptimizeDistributed(1000) returned error: %v", err)
+	}
+
+	if len(result.Allocations) != 2 {
+		t.Fatalf("OptimizeDistributed(1000) allocations = %+v, want 2 centers", result.Allocations)
+	}
+	if got := result.Allocations[0]; got.Center != "west" || got.Quantity != 500 || got.Result.TotalPacks != 1 {
+		t.Errorf("first allocation = %+v, want west taking 500 in one pack", got)
+	}
+	if got := result.Allocations[1]; got.Center != "east" || got.Quantity != 500 || got.Result.TotalPacks != 2 {
+		t.Errorf("second allocation = %+v, want east taking 500 in two packs", got)
+	}
+	if result.TotalItems != 1000 || result.TotalPacks != 3 || result.Waste != 0 {
+		t.Errorf("totals = %d items, %d packs, %d waste; want 1000, 3, 0",
+			result.TotalItems, result.TotalPacks, result.Waste)
+	}
+}
+
+func TestOptimizeDistributedPrefersSingleCenterWithoutCapacityLimits(t *testing.T) {
+	centers := []FulfillmentCenter{
+		{Name: "east", PackSizes: []int{300}},
+		{Name: "west", PackSizes: []int{250, 500}},
+	}
+
+	result, err := OptimizeDistributed(1000, centers)
+	if err != nil {
+		t.Fatalf("OptimizeDistributed(1000) returned error: %v", err)
+	}
+	if len(result.Allocations) != 1 || result.Allocations[0].Center != "west" {
+		t.Errorf("allocations = %+v, want west alone", result.Allocations)
+	}
+}
+
+func TestOptimizeDistributedErrors(t *testing.T) {
+	testCases := []struct {
+		quantity    int
+		centers     []FulfillmentCenter
+		description string
+	}{
+		{1000, nil, "No centers"},
+		{1000, []FulfillmentCenter{{Name: "east", PackSizes: []int{250}, Capacity: 100}}, "Insufficient capacity"},
+		{1000, []FulfillmentCenter{{Name: "east"}}, "Empty catalog"},
+		{1000, []FulfillmentCenter{{Name: "east", PackSizes: []int{250}}, {Name: "east", PackSizes: []int{500}}}, "Duplicate names"},
+		{0, []FulfillmentCenter{{Name: "east", PackSizes: []int{250}}}, "Zero quantity"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if _, err := OptimizeDistributed(tc.quantity, tc.centers); err == nil {
+				t.Errorf("OptimizeDistributed(%d, %+v) should return error", tc.quantity, tc.centers)
+			}
+		})
+	}
+}
+
+func TestDistributedHandler(t *testing.T) {
+	body := `{"quantity": 1000, "centers": [
+		{"name": "east", "packSizes": [250], "capacity": 600},
+		{"name": "west", "packSizes": [500], "capacity": 500}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/optimize/distributed", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	distributedHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/distributed status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var result DistributedResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(result.Allocations) != 2 || result.Waste != 0 {
+		t.Errorf("POST /optimize/distributed = %+v, want a zero-waste two-center split", result)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+// EchoedRequest is an optimize request as the server interpreted it: the
+// parsed quantity, the catalog it resolved to, and every option with the
+// default applied when the request left it out.
+type EchoedRequest struct {
+	Quantity    int    `json:"quantity"`
+	MaxQuantity int    `json:"maxQuantity,omitempty"`
+	Catalog     string `json:"catalog"`
+	PackSizes   []int  `json:"packSizes"`
+
+	ScaleSizes float64 `json:"scaleSizes,omitempty"`
+
+	Mode              string `json:"mode"`
+	Policy            string `json:"policy"`
+	UnderfillTiebreak string `json:"underfillTiebreak"`
+
+	PenalizeSmallPacks bool `json:"penalizeSmallPacks"`
+	PreferEvenCounts   bool `json:"preferEvenCounts"`
+	WasteDelta         int  `json:"wasteDelta"`
+	SearchMargin       int  `json:"searchMargin"`
+
+	Costs           map[int]float64 `json:"costs,omitempty"`
+	Currency        string          `json:"currency,omitempty"`
+	Volumes         map[int]float64 `json:"volumes,omitempty"`
+	MaxWastePerSize map[int]int     `json:"maxWastePerSize,omitempty"`
+	MaxPacks        int             `json:"maxPacks,omitempty"`
+	MaxWaste        int             `json:"maxWaste,omitempty"`
+	Slots           map[int]int     `json:"slots,omitempty"`
+	MaxSlots        int             `json:"maxSlots,omitempty"`
+	Budget          float64         `json:"budget,omitempty"`
+	Weights         *ScoreWeights   `json:"weights,omitempty"`
+	WastePenalty    float64         `json:"wastePenalty,omitempty"`
+	SpreadTies      bool            `json:"spreadTies"`
+	Seed            *int64          `json:"seed,omitempty"`
+
+	TreatZeroAsEmpty bool `json:"treatZeroAsEmpty"`
+	MinOrderQuantity int  `json:"minOrderQuantity"`
+
+	StrictSmallOrders bool `json:"strictSmallOrders"`
+}
+
+// echoRequest describes how quantity and opts are applied to the catalog
+// stored under name. Seed and WastePenalty are reported only when they are
+// used.
+func echoRequest(quantity int, name string, catalog Catalog, opts OptimizeOptions) *EchoedRequest {
+	sizes := sortedDescending(catalog.PackSizes)
+	echo := &EchoedRequest{
+		Quantity:    quantity,
+		MaxQuantity: opts.MaxQuantity,
+		Catalog:     name,
+		PackSizes:   sizes,
+
+		Mode:              opts.Mode,
+		Policy:            opts.Policy,
+		UnderfillTiebreak: opts.UnderfillTiebreak,
+
+		PenalizeSmallPacks: opts.PenalizeSmallPacks,
+		PreferEvenCounts:   opts.PreferEvenCounts,
+		WasteDelta:         opts.WasteDelta,
+		SearchMargin:       opts.SearchMargin,
+
+		Costs:           opts.Costs,
+		Currency:        opts.Currency,
+		Volumes:         opts.Volumes,
+		MaxWastePerSize: opts.MaxWastePerSize,
+		MaxPacks:        opts.MaxPacks,
+		MaxWaste:        opts.MaxWaste,
+		Slots:           opts.Slots,
+		MaxSlots:        opts.MaxSlots,
+		Budget:          opts.Budget,
+		Weights:         opts.Weights,
+		SpreadTies:      opts.SpreadTies,
+
+		TreatZeroAsEmpty: opts.TreatZeroAsEmpty,
+		MinOrderQuantity: opts.MinOrderQuantity,
+
+		StrictSmallOrders: opts.StrictSmallOrders,
+	}
+	if echo.Mode == "" {
+		echo.Mode = ModeFewestPacks
+	}
+	if echo.Policy == "" {
+		echo.Policy = fulfillmentPolicy
+	}
+	if echo.UnderfillTiebreak == "" {
+		echo.UnderfillTiebreak = underfillTiebreak
+	}
+	if len(sizes) > 0 {
+		echo.WasteDelta = wasteDelta(opts, sizes)
+	}
+	if opts.Weights != nil {
+		echo.WastePenalty = opts.WastePenalty
+		if echo.WastePenalty == 0 {
+			echo.WastePenalty = 1
+		}
+	}
+	if opts.SpreadTies {
+		seed := opts.Seed
+		echo.Seed = &seed
+	}
+	return echo
+}
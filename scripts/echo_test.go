@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizeHandlerEchoRequest(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+	withFulfillmentPolicy(t, PolicyOverfill)
+	withUnderfillTiebreak(t, TiebreakOver)
+
+	testCases := []struct {
+		description string
+		body        string
+		want        EchoedRequest
+	}{
+		{
+			"defaults filled in",
+			`{"quantity": "250+1", "echoRequest": true}`,
+			EchoedRequest{
+				Quantity: 251, Catalog: defaultCatalogName, PackSizes: []int{1000, 500, 250},
+				Mode: ModeFewestPacks, Policy: PolicyOverfill, UnderfillTiebreak: TiebreakOver,
+				WasteDelta: 250,
+			},
+		},
+		{
+			"explicit options kept",
+			`{"minQuantity": 480, "maxQuantity": 520, "mode": "fewestLines", "policy": "allow-underfill",
+				"underfillTiebreak": "under", "penalizeSmallPacks": true, "wasteDelta": 100, "searchMargin": 50,
+				"maxWastePerSize": {"1000": 0}, "echoRequest": true}`,
+			EchoedRequest{
+				Quantity: 480, MaxQuantity: 520, Catalog: defaultCatalogName, PackSizes: []int{1000, 500, 250},
+				Mode: ModeFewestLines, Policy: PolicyAllowUnderfill, UnderfillTiebreak: TiebreakUnder,
+				PenalizeSmallPacks: true, WasteDelta: 100, SearchMargin: 50, MaxWastePerSize: map[int]int{1000: 0},
+			},
+		},
+		{
+			"costs, currency and seed",
+			`{"quantity": 800, "costs": {"250": {"amount": 1, "currency": "EUR"}, "500": {"amount": 1.5, "currency": "EUR"},
+				"1000": {"amount": 2, "currency": "EUR"}}, "spreadTies": true, "seed": 9, "echoRequest": true}`,
+			EchoedRequest{
+				Quantity: 800, Catalog: defaultCatalogName, PackSizes: []int{1000, 500, 250},
+				Mode: ModeFewestPacks, Policy: PolicyOverfill, UnderfillTiebreak: TiebreakOver, WasteDelta: 250,
+				Costs: map[int]float64{250: 1, 500: 1.5, 1000: 2}, Currency: "EUR", SpreadTies: true, Seed: func() *int64 { s := int64(9); return &s }(),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var result OptimizationResult
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.Request == nil || !reflect.DeepEqual(*result.Request, tc.want) {
+				t.Errorf("echoed request = %+v, want %+v", result.Request, tc.want)
+			}
+		})
+	}
+
+	rec := postOptimize(t, `{"quantity": 251}`)
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Request != nil {
+		t.Errorf("without echoRequest the response echoed %+v", result.Request)
+	}
+}
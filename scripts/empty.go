@@ -0,0 +1,30 @@
+package main
+
+// treatZeroAsEmpty is the server default for answering a zero quantity with
+// an empty plan instead of an error.
+var treatZeroAsEmpty = envBool("TREAT_ZERO_AS_EMPTY", false)
+
+// zeroAsEmpty resolves a request's treatZeroAsEmpty field, falling back to
+// the server default when it is left out.
+func zeroAsEmpty(requested *bool) bool {
+	if requested != nil {
+		return *requested
+	}
+	return treatZeroAsEmpty
+}
+
+// emptyResult is the plan for a zero quantity under
+// OptimizeOptions.TreatZeroAsEmpty: no packs, no items and nothing to pay.
+func emptyResult(opts OptimizeOptions) *OptimizationResult {
+	result := buildResult(0, 0, nil, nil)
+	if opts.Costs != nil {
+		cost := 0.0
+		result.TotalCost = &cost
+		result.Currency = opts.Currency
+	}
+	if opts.Volumes != nil {
+		volume := 0.0
+		result.TotalVolume = &volume
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withTreatZeroAsEmpty sets the server default for zero quantities for the
+// duration of a test.
+func withTreatZeroAsEmpty(t *testing.T, enabled bool) {
+	t.Helper()
+	saved := treatZeroAsEmpty
+	treatZeroAsEmpty = enabled
+	t.Cleanup(func() { treatZeroAsEmpty = saved })
+}
+
+func TestOptimizeTreatZeroAsEmpty(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	testCases := []struct {
+		description  string
+		serverOption bool
+		body         string
+		wantStatus   int
+	}{
+		{"zero is an error by default", false, `{"quantity": 0}`, http.StatusBadRequest},
+		{"request enables empty plans", false, `{"quantity": 0, "treatZeroAsEmpty": true}`, http.StatusOK},
+		{"server default enables empty plans", true, `{"quantity": 0}`, http.StatusOK},
+		{"request overrides the server default", true, `{"quantity": 0, "treatZeroAsEmpty": false}`, http.StatusBadRequest},
+		{"negative quantities are still rejected", true, `{"quantity": -5}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withTreatZeroAsEmpty(t, tc.serverOption)
+			rec := postOptimize(t, tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /optimize %s status = %d, want %d: %s", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			want := OptimizationResult{Packs: []PackResult{}}
+			if !reflect.DeepEqual(result, want) {
+				t.Errorf("POST /optimize %s = %+v, want an empty plan", tc.body, result)
+			}
+		})
+	}
+}
+
+func TestOptimizeCatalogEmptyPlanIsPriced(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500}}
+	opts := OptimizeOptions{Costs: map[int]float64{250: 1, 500: 1.5}, Currency: "EUR", TreatZeroAsEmpty: true}
+
+	result, err := OptimizeCatalog(0, catalog, opts)
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(0) returned error: %v", err)
+	}
+	if result.TotalCost == nil || *result.TotalCost != 0 || result.Currency != "EUR" || len(result.Packs) != 0 {
+		t.Errorf("OptimizeCatalog(0) = %+v, want an empty plan costing 0 EUR", result)
+	}
+
+	opts.Costs = map[int]float64{250: 1}
+	if _, err := OptimizeCatalog(0, catalog, opts); err == nil {
+		t.Error("an empty plan should still validate the request's costs")
+	}
+}
+
+func TestBatchTreatZeroAsEmpty(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	testCases := []struct {
+		description string
+		body        string
+		wantError   string
+		wantResult  *OptimizationResult
+	}{
+		{"zero is an item error by default", `{"quantities": [251, 0]}`, "order quantity must be positive", nil},
+		{"empty plan for zero", `{"quantities": [251, 0], "treatZeroAsEmpty": true}`, "", &OptimizationResult{Packs: []PackResult{}}},
+		{"negative is still an item error", `{"quantities": [251, -1], "treatZeroAsEmpty": true}`, "order quantity must be positive", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/optimize/batch", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			batchHandler(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize/batch %s status = %d: %s", tc.body, rec.Code, rec.Body.String())
+			}
+			var got BatchResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(got.Results) != 2 || got.Results[0].Result == nil || got.Results[0].Result.TotalItems != 500 {
+				t.Fatalf("POST /optimize/batch %s = %+v, want 500 items for 251", tc.body, got.Results)
+			}
+			item := got.Results[1]
+			if item.Error != tc.wantError || !reflect.DeepEqual(item.Result, tc.wantResult) {
+				t.Errorf("second item = %+v, want error %q and result %+v", item, tc.wantError, tc.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+// maxSearchStates bounds the partial pack mixes visited by one
+// enumerateBreakdowns call, so dense catalogs cannot stall a request. It
+// bounds the CPU of a search whatever the number of results asked for.
+var maxSearchStates = envInt("MAX_SEARCH_STATES", 200000)
+
+// enumerateBreakdowns calls visit with every pack mix whose total lies in
+// [lo, hi]. counts is aligned with sizes, which must be sorted in descending
+// order, and is reused between calls, so visit must copy it to retain it.
+// Mixes using many large packs are visited first. Enumeration stops after
+// maxStates partial mixes and the return value reports whether it did.
+func enumerateBreakdowns(sizes []int, lo, hi, maxStates int, visit func(counts []int, total int)) (truncated bool) {
+	counts := make([]int, len(sizes))
+	states := 0
+
+	var walk func(idx, total int)
+	walk = func(idx, total int) {
+		if truncated {
+			return
+		}
+		states++
+		if states > maxStates {
+			truncated = true
+			return
+		}
+
+		size := sizes[idx]
+		if idx == len(sizes)-1 {
+			first := 0
+			if lo > total {
+				first = (lo - total + size - 1) / size
+			}
+			for k := (hi - total) / size; k >= first; k-- {
+				counts[idx] = k
+				visit(counts, total+k*size)
+			}
+			counts[idx] = 0
+			return
+		}
+
+		for k := (hi - total) / size; k >= 0; k-- {
+			counts[idx] = k
+			walk(idx+1, total+k*size)
+		}
+		counts[idx] = 0
+	}
+
+	if hi >= lo && len(sizes) > 0 {
+		walk(0, 0)
+	}
+	return truncated
+}
+
+// countsMap converts counts aligned with sizes into the map form used by
+// buildResult, dropping unused sizes.
+func countsMap(sizes, counts []int) map[int]int {
+	m := make(map[int]int)
+	for i, qty := range counts {
+		if qty > 0 {
+			m[sizes[i]] = qty
+		}
+	}
+	return m
+}
+
+// countsSlice converts the map form of a breakdown into counts aligned with
+// sizes.
+func countsSlice(sizes []int, m map[int]int) []int {
+	counts := make([]int, len(sizes))
+	for i, size := range sizes {
+		counts[i] = m[size]
+	}
+	return counts
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestEnumerateBreakdowns(t *testing.T) {
+	var got []string
+	truncated := enumerateBreakdowns([]int{500, 250}, 700, 1000, maxSearchStates, func(counts []int, total int) {
+		got = append(got, fmt.Sprintf("%v=%d", counts, total))
+	})
+
+	want := []string{"[2 0]=1000", "[1 2]=1000", "[1 1]=750", "[0 4]=1000", "[0 3]=750"}
+	if truncated || !slices.Equal(got, want) {
+		t.Errorf("enumerateBreakdowns = %v (truncated %v), want %v", got, truncated, want)
+	}
+}
+
+func TestEnumerateBreakdownsTruncates(t *testing.T) {
+	visited := 0
+	truncated := enumerateBreakdowns([]int{7, 5, 3, 2, 1}, 0, 1000, 100, func(counts []int, total int) {
+		visited++
+	})
+	if !truncated {
+		t.Errorf("enumerateBreakdowns over a dense catalog should truncate at 100 states")
+	}
+	if visited == 0 {
+		t.Errorf("enumerateBreakdowns visited nothing before truncating")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// errorMessage decodes the JSON error envelope of a response.
+func errorMessage(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding error envelope %q: %v", rec.Body.String(), err)
+	}
+	if body.Status != rec.Code {
+		t.Errorf("error envelope status = %d, response status = %d", body.Status, rec.Code)
+	}
+	return body.Error
+}
+
+func TestUnknownRouteReturnsJSON404(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		t.Run(method, func(t *testing.T) {
+			req := httptest.NewRequest(method, "/no/such/route", nil)
+			rec := httptest.NewRecorder()
+			newRouter().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s /no/such/route status = %d, want %d", method, rec.Code, http.StatusNotFound)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+			}
+			if got := errorMessage(t, rec); got != "Not found: /no/such/route" {
+				t.Errorf("error message = %q, want %q", got, "Not found: /no/such/route")
+			}
+		})
+	}
+}
+
+func TestKnownRoutesStillResolve(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /health status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
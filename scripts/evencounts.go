@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// validateEvenCounts checks that opts.PreferEvenCounts is combined only with
+// objectives that pick among mixes of the least-waste total.
+func validateEvenCounts(opts OptimizeOptions) error {
+	if !opts.PreferEvenCounts {
+		return nil
+	}
+	if perPackMode(opts.Mode) {
+		return fmt.Errorf("preferEvenCounts is not supported with mode %q", opts.Mode)
+	}
+	if opts.PenalizeSmallPacks {
+		return fmt.Errorf("preferEvenCounts cannot be combined with penalizeSmallPacks")
+	}
+	return nil
+}
+
+// oddLines counts the sizes of a mix used an odd number of times.
+func oddLines(counts []int) int {
+	odd := 0
+	for _, qty := range counts {
+		odd += qty % 2
+	}
+	return odd
+}
+
+// evenCountsBreakdown searches the totals in [lo, hi] for the mix with the
+// fewest sizes used an odd number of times, then the least waste, then the
+// fewest packs. It returns -1 when no total in the window is reachable.
+func evenCountsBreakdown(sizes []int, lo, hi int) (int, map[int]int) {
+	bestAmount, bestPacks, bestOdd := -1, 0, 0
+	var best []int
+
+	enumerateBreakdowns(sizes, lo, hi, maxSearchStates, func(counts []int, total int) {
+		packs := 0
+		for _, qty := range counts {
+			packs += qty
+		}
+		odd := oddLines(counts)
+
+		better := bestAmount == -1
+		if !better && odd != bestOdd {
+			better = odd < bestOdd
+		} else if !better && total != bestAmount {
+			better = total < bestAmount
+		} else if !better {
+			better = packs < bestPacks
+		}
+		if better {
+			bestAmount, bestPacks, bestOdd = total, packs, odd
+			best = append(best[:0], counts...)
+		}
+	})
+
+	if bestAmount == -1 {
+		return -1, nil
+	}
+	return bestAmount, countsMap(sizes, best)
+}
+
+// oddCountsWarning tells the client that no mix within delta of the least
+// waste, or within the constraints, ships every size an even number of
+// times.
+func oddCountsWarning(delta int) string {
+	return fmt.Sprintf("No mix within %d items of the least waste uses an even number of every pack size", delta)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPreferEvenCounts(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+		wantWaste   int
+		wantWarning bool
+	}{
+		{"default keeps odd counts", []int{250, 500, 1000}, 1500, OptimizeOptions{},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 500, Quantity: 1}}, 0, false},
+		{"even counts at equal waste", []int{250, 500, 1000}, 1500, OptimizeOptions{PreferEvenCounts: true},
+			[]PackResult{{PackSize: 500, Quantity: 2}, {PackSize: 250, Quantity: 2}}, 0, false},
+		{"even counts at higher waste", []int{300, 1000}, 1000, OptimizeOptions{PreferEvenCounts: true},
+			[]PackResult{{PackSize: 300, Quantity: 4}}, 200, false},
+		{"wasteDelta too small to reach even counts", []int{300, 1000}, 1000, OptimizeOptions{PreferEvenCounts: true, WasteDelta: 100},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, Catalog{PackSizes: tc.sizes}, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.Waste != tc.wantWaste {
+				t.Errorf("waste = %d, want %d", result.Waste, tc.wantWaste)
+			}
+			if got := len(result.Warnings) > 0; got != tc.wantWarning {
+				t.Errorf("warnings = %v, want a warning %v", result.Warnings, tc.wantWarning)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerPreferEvenCounts(t *testing.T) {
+	withPackSizes(t, []int{300, 1000})
+
+	rec := postOptimize(t, `{"quantity": 1000, "preferEvenCounts": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := []PackResult{{PackSize: 300, Quantity: 4}}; !reflect.DeepEqual(result.Packs, want) {
+		t.Errorf("packs = %+v, want %+v", result.Packs, want)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 1000, "preferEvenCounts": true, "penalizeSmallPacks": true}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("preferEvenCounts with penalizeSmallPacks status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
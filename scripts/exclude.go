@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// excludeSizes returns catalog without the sizes in exclude, for leaving out
+// out-of-stock sizes of a single request without storing the change. Labels
+// and the per-size waste caps of its defaults go with their sizes. Every
+// excluded size must be in the catalog, and excluding all of them is
+// infeasible.
+func excludeSizes(catalog Catalog, exclude []int) (Catalog, error) {
+	for _, size := range exclude {
+		if !slices.Contains(catalog.PackSizes, size) {
+			return Catalog{}, fmt.Errorf("exclude names pack size %d, which is not in the catalog", size)
+		}
+	}
+
+	remaining := Catalog{Labels: make(map[int]string, len(catalog.Labels))}
+	for _, size := range catalog.PackSizes {
+		if slices.Contains(exclude, size) {
+			continue
+		}
+		remaining.PackSizes = append(remaining.PackSizes, size)
+		if label, ok := catalog.Labels[size]; ok {
+			remaining.Labels[size] = label
+		}
+	}
+	if len(remaining.PackSizes) == 0 {
+		return Catalog{}, fmt.Errorf("%w: exclude leaves no pack sizes", ErrInfeasible)
+	}
+	if catalog.Defaults != nil {
+		defaults := *catalog.Defaults
+		defaults.MaxWastePerSize = withoutSizes(defaults.MaxWastePerSize, exclude)
+		remaining.Defaults = &defaults
+	}
+	return remaining, nil
+}
+
+// excludeOptions drops the per-size settings of opts for the sizes in
+// exclude, so a request may keep pricing a size it excludes.
+func excludeOptions(opts OptimizeOptions, exclude []int) OptimizeOptions {
+	opts.Costs = withoutSizes(opts.Costs, exclude)
+	opts.Volumes = withoutSizes(opts.Volumes, exclude)
+	opts.Slots = withoutSizes(opts.Slots, exclude)
+	opts.MaxWastePerSize = withoutSizes(opts.MaxWastePerSize, exclude)
+	return opts
+}
+
+// withoutSizes returns a copy of m without the keys in exclude. A nil m
+// stays nil.
+func withoutSizes[V any](m map[int]V, exclude []int) map[int]V {
+	if m == nil {
+		return nil
+	}
+	kept := maps.Clone(m)
+	for _, size := range exclude {
+		delete(kept, size)
+	}
+	return kept
+}
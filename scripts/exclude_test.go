@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizeHandlerExclude(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 12001, "exclude": [5000], "costs": {"250": 1, "500": 2, "1000": 3, "2000": 5, "5000": 9}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := []PackResult{{PackSize: 2000, Quantity: 6}, {PackSize: 250, Quantity: 1}}
+	if !reflect.DeepEqual(result.Packs, want) {
+		t.Errorf("packs = %+v, want %+v", result.Packs, want)
+	}
+	if !reflect.DeepEqual(PackSizes, []int{250, 500, 1000, 2000, 5000}) {
+		t.Errorf("PackSizes = %v, want the catalog unchanged", PackSizes)
+	}
+
+	testCases := []struct {
+		description string
+		body        string
+		wantStatus  int
+	}{
+		{"exact fill needs the excluded size", `{"quantity": 250, "policy": "exact", "exclude": [250]}`, http.StatusUnprocessableEntity},
+		{"every size excluded", `{"quantity": 250, "exclude": [250, 500, 1000, 2000, 5000]}`, http.StatusUnprocessableEntity},
+		{"size not in the catalog", `{"quantity": 250, "exclude": [300]}`, http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if rec := postOptimize(t, tc.body); rec.Code != tc.wantStatus {
+				t.Errorf("POST /optimize status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
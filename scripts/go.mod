@@ -1,3 +1,5 @@
 module pack-optimizer
 
-go 1.22.3
+go 1.24
+
+require google.golang.org/protobuf v1.34.2
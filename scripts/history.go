@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// optimizeHistory keeps the most recent successful optimizations for
+// auditing.
+var optimizeHistory = newHistoryBuffer(envInt("HISTORY_SIZE", 100))
+
+// HistoryEntry is one recorded optimize request and its result.
+type HistoryEntry struct {
+	Time    time.Time           `json:"time"`
+	Request optimizeRequest     `json:"request"`
+	Result  *OptimizationResult `json:"result"`
+}
+
+// historyBuffer is a fixed-size ring of HistoryEntry values; once full, each
+// new entry evicts the oldest.
+type historyBuffer struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// newHistoryBuffer returns a buffer holding up to size entries; size <= 0
+// disables recording.
+func newHistoryBuffer(size int) *historyBuffer {
+	if size < 0 {
+		size = 0
+	}
+	return &historyBuffer{entries: make([]HistoryEntry, size)}
+}
+
+func (h *historyBuffer) add(request optimizeRequest, result *OptimizationResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return
+	}
+	h.entries[h.next] = HistoryEntry{Time: time.Now().UTC(), Request: request, Result: result}
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// recent returns the retained entries, newest first.
+func (h *historyBuffer) recent() []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+	recent := make([]HistoryEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		recent = append(recent, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+	return recent
+}
+
+// HTTP handler exposing recent optimization history
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := struct {
+		Entries []HistoryEntry `json:"entries"`
+	}{
+		Entries: optimizeHistory.recent(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
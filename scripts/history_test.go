@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHistoryBufferKeepsMostRecent(t *testing.T) {
+	h := newHistoryBuffer(3)
+	for q := 1; q <= 5; q++ {
+		h.add(optimizeRequest{Quantity: quantityValue(q)}, &OptimizationResult{OrderQuantity: q})
+	}
+
+	recent := h.recent()
+	if len(recent) != 3 {
+		t.Fatalf("recent() returned %d entries, want 3", len(recent))
+	}
+	for i, want := range []int{5, 4, 3} {
+		if got := int(recent[i].Request.Quantity); got != want {
+			t.Errorf("recent()[%d] quantity = %d, want %d", i, got, want)
+		}
+		if recent[i].Time.IsZero() {
+			t.Errorf("recent()[%d] has no timestamp", i)
+		}
+	}
+}
+
+func TestHistoryBufferPartiallyFilled(t *testing.T) {
+	h := newHistoryBuffer(5)
+	h.add(optimizeRequest{Quantity: 1}, &OptimizationResult{OrderQuantity: 1})
+	h.add(optimizeRequest{Quantity: 2}, &OptimizationResult{OrderQuantity: 2})
+
+	recent := h.recent()
+	if len(recent) != 2 || recent[0].Request.Quantity != 2 || recent[1].Request.Quantity != 1 {
+		t.Errorf("recent() = %+v, want quantities [2 1]", recent)
+	}
+
+	if got := newHistoryBuffer(0); len(got.recent()) != 0 {
+		t.Errorf("disabled buffer should stay empty")
+	}
+}
+
+func TestHistoryHandlerListsOptimizations(t *testing.T) {
+	saved := optimizeHistory
+	optimizeHistory = newHistoryBuffer(2)
+	t.Cleanup(func() { optimizeHistory = saved })
+
+	postOptimize(t, `{"quantity": 251}`)
+	postOptimize(t, `{"quantity": 0}`)
+	postOptimize(t, `{"quantity": 501, "mode": "fewestLines"}`)
+
+	rec := httptest.NewRecorder()
+	historyHandler(rec, httptest.NewRequest(http.MethodGet, "/history", nil))
+
+	var response struct {
+		Entries []HistoryEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(response.Entries) != 2 {
+		t.Fatalf("GET /history returned %d entries, want 2 successful ones", len(response.Entries))
+	}
+	if got := response.Entries[0]; got.Request.Mode != ModeFewestLines || got.Result.TotalItems != 750 {
+		t.Errorf("newest entry = %+v, want the fewestLines request for 501", got)
+	}
+	if got := response.Entries[1]; got.Request.Quantity != 251 || got.Result.TotalItems != 500 {
+		t.Errorf("older entry = %+v, want the request for 251", got)
+	}
+}
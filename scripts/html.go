@@ -0,0 +1,58 @@
+package main
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlContentType selects the HTML table representation of a result, for
+// reading one in a browser.
+const htmlContentType = "text/html"
+
+// resultTemplate renders an OptimizationResult as a bare page: one row per
+// pack line, then the totals. Labels and other strings are escaped.
+var resultTemplate = template.Must(template.New("result").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Pack optimization for {{.OrderQuantity}}</title></head>
+<body>
+<table>
+<thead><tr><th>Pack size</th><th>Label</th><th>Quantity</th><th>Items</th></tr></thead>
+<tbody>
+{{- range .Packs}}
+<tr><td>{{.PackSize}}</td><td>{{.Label}}</td><td>{{.Quantity}}</td><td>{{.Items}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><th>Order quantity</th><td colspan="3">{{.OrderQuantity}}</td></tr>
+<tr><th>Total items</th><td colspan="3">{{.TotalItems}}</td></tr>
+<tr><th>Total packs</th><td colspan="3">{{.TotalPacks}}</td></tr>
+<tr><th>Waste</th><td colspan="3">{{.Waste}}</td></tr>
+{{- if .Shortfall}}
+<tr><th>Shortfall</th><td colspan="3">{{.Shortfall}}</td></tr>
+{{- end}}
+{{- if .TotalCost}}
+<tr><th>Total cost</th><td colspan="3">{{.TotalCost}}{{with .Currency}} {{.}}{{end}}</td></tr>
+{{- end}}
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+// writeResultHTML writes result to w as the page of resultTemplate.
+func writeResultHTML(w io.Writer, result *OptimizationResult) error {
+	view := struct {
+		*OptimizationResult
+		Packs []htmlPackRow
+	}{OptimizationResult: result}
+	for _, pack := range result.Packs {
+		view.Packs = append(view.Packs, htmlPackRow{PackResult: pack, Items: pack.PackSize * pack.Quantity})
+	}
+	return resultTemplate.Execute(w, view)
+}
+
+// htmlPackRow is a pack line of the HTML table with its item count.
+type htmlPackRow struct {
+	PackResult
+	Items int
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptimizeHTML(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	PackLabels = map[int]string{250: "<Small>"}
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 12001, "costs": {"250": 1, "500": 2, "1000": 3, "2000": 4, "5000": 5}}`))
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, htmlContentType) {
+		t.Errorf("Content-Type = %q, want %q", got, htmlContentType)
+	}
+
+	body := rec.Body.String()
+	for _, row := range []string{
+		"<tr><td>5000</td><td></td><td>2</td><td>10000</td></tr>",
+		"<tr><td>2000</td><td></td><td>1</td><td>2000</td></tr>",
+		"<tr><td>250</td><td>&lt;Small&gt;</td><td>1</td><td>250</td></tr>",
+		`<tr><th>Order quantity</th><td colspan="3">12001</td></tr>`,
+		`<tr><th>Total items</th><td colspan="3">12250</td></tr>`,
+		`<tr><th>Total packs</th><td colspan="3">4</td></tr>`,
+		`<tr><th>Waste</th><td colspan="3">249</td></tr>`,
+		`<tr><th>Total cost</th><td colspan="3">15</td></tr>`,
+	} {
+		if !strings.Contains(body, row) {
+			t.Errorf("HTML response lacks %s:\n%s", row, body)
+		}
+	}
+	if strings.Contains(body, "Shortfall") {
+		t.Errorf("HTML response lists a shortfall for an overfilled order:\n%s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 250}`))
+	rec = httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type without Accept = %q, want application/json", got)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// catalogImportDir is the only directory POST /packages/import may read
+// catalog files from. Imports are disabled while it is empty.
+var catalogImportDir = os.Getenv("CATALOG_IMPORT_DIR")
+
+// ErrImportNotAllowed reports an import path outside catalogImportDir, or an
+// import while imports are disabled.
+var ErrImportNotAllowed = errors.New("catalog imports are only allowed from CATALOG_IMPORT_DIR")
+
+// resolveImportPath resolves path, relative to dir unless absolute, and
+// checks that it names a file inside dir once symlinks are followed.
+func resolveImportPath(dir, path string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("%w, which is not set", ErrImportNotAllowed)
+	}
+	root, err := filepath.Abs(dir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("catalog import directory is unavailable: %w", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// Judge a missing file by its lexical path so probing outside the
+		// directory never reveals what exists there.
+		resolved = filepath.Clean(path)
+	}
+	rel, relErr := filepath.Rel(root, resolved)
+	if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrImportNotAllowed, path)
+	}
+	return resolved, err
+}
+
+// readCatalogFile reads a catalog in the POST /packages body format and
+// validates it.
+func readCatalogFile(path string) (Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	var file struct {
+		PackSizes json.RawMessage  `json:"packSizes"`
+		Defaults  *CatalogDefaults `json:"defaults"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return Catalog{}, fmt.Errorf("Invalid catalog file: %v", strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	packSizes, labels, err := decodePackSizes(file.PackSizes)
+	if err != nil {
+		return Catalog{}, err
+	}
+	catalog := Catalog{PackSizes: packSizes, Labels: labels, Defaults: file.Defaults}
+	if err := validateCatalog(catalog); err != nil {
+		return Catalog{}, err
+	}
+	return catalog, nil
+}
+
+// HTTP handler for importing a catalog from a file on the server
+func importHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Path    string `json:"path"`
+		Catalog string `json:"catalog"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Path == "" {
+		writeError(w, "path is required", http.StatusBadRequest)
+		return
+	}
+	name := request.Catalog
+	if name == "" {
+		name = defaultCatalogName
+	}
+
+	path, err := resolveImportPath(catalogImportDir, request.Path)
+	if errors.Is(err, ErrImportNotAllowed) {
+		writeError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	var catalog Catalog
+	if err == nil {
+		catalog, err = readCatalogFile(path)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, fmt.Sprintf("Catalog file %s not found", request.Path), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if name == defaultCatalogName && catalog.Defaults != nil {
+		writeError(w, "The default catalog cannot store defaults", http.StatusBadRequest)
+		return
+	}
+	if err := namedCatalogs.put(name, catalog); err != nil {
+		writeError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalog)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withImportDir points catalogImportDir at a fresh directory holding files,
+// and returns it.
+func withImportDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	saved := catalogImportDir
+	catalogImportDir = dir
+	t.Cleanup(func() { catalogImportDir = saved })
+	return dir
+}
+
+func TestImportCatalog(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	dir := withImportDir(t, map[string]string{"spring.json": `{"packSizes": [300, {"size": 700, "label": "Crate"}]}`})
+
+	testCases := []struct {
+		description string
+		body        string
+		lookup      string
+	}{
+		{"relative path into a named catalog", `{"path": "spring.json", "catalog": "spring"}`, "spring"},
+		{"absolute path inside the directory", fmt.Sprintf(`{"path": %q, "catalog": "spring"}`, filepath.Join(dir, "spring.json")), "spring"},
+		{"default catalog", `{"path": "./spring.json"}`, defaultCatalogName},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodPost, "/packages/import", tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /packages/import %s status = %d: %s", tc.body, rec.Code, rec.Body.String())
+			}
+			var applied Catalog
+			if err := json.NewDecoder(rec.Body).Decode(&applied); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			want := Catalog{PackSizes: []int{300, 700}, Labels: map[int]string{700: "Crate"}}
+			if !reflect.DeepEqual(applied, want) {
+				t.Errorf("applied catalog = %+v, want %+v", applied, want)
+			}
+			if stored, ok := namedCatalogs.lookup(tc.lookup); !ok || !reflect.DeepEqual(stored.PackSizes, want.PackSizes) {
+				t.Errorf("catalog %q = %+v, want %v", tc.lookup, stored, want.PackSizes)
+			}
+		})
+	}
+}
+
+func TestImportCatalogRejectsBadPaths(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"packSizes": [1]}`), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", secret, err)
+	}
+	dir := withImportDir(t, map[string]string{"broken.json": `{"packSizes": []}`})
+	if err := os.Symlink(secret, filepath.Join(dir, "link.json")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		path        string
+		wantStatus  int
+	}{
+		{"parent traversal", "../" + filepath.Base(outside) + "/secret.json", http.StatusForbidden},
+		{"absolute path outside", secret, http.StatusForbidden},
+		{"symlink escaping the directory", "link.json", http.StatusForbidden},
+		{"missing file outside", "../nowhere.json", http.StatusForbidden},
+		{"missing file inside", "nowhere.json", http.StatusNotFound},
+		{"invalid catalog", "broken.json", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := serveJSON(t, http.MethodPost, "/packages/import", fmt.Sprintf(`{"path": %q}`, tc.path))
+			if rec.Code != tc.wantStatus {
+				t.Errorf("POST /packages/import %s status = %d, want %d: %s", tc.path, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	if got := PackSizes; !reflect.DeepEqual(got, []int{250, 500}) {
+		t.Errorf("rejected imports changed the default catalog to %v", got)
+	}
+
+	catalogImportDir = ""
+	if rec := serveJSON(t, http.MethodPost, "/packages/import", `{"path": "broken.json"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("import with CATALOG_IMPORT_DIR unset status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
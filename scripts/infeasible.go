@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+)
+
+// QuantityRange is the inclusive range of order quantities From..To.
+type QuantityRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// InfeasibleRange lists the order quantities of a range that no pack mix
+// satisfies under the constraints, merged into runs of consecutive
+// quantities.
+type InfeasibleRange struct {
+	From       int             `json:"from"`
+	To         int             `json:"to"`
+	Infeasible []QuantityRange `json:"infeasible"`
+	Count      int             `json:"count"`
+}
+
+// RangeConstraints are the constraints an infeasible range is mapped under.
+// Inventory caps the packs available of each listed size; sizes it leaves
+// out are unlimited.
+type RangeConstraints struct {
+	Policy    string
+	MaxWaste  int
+	MaxPacks  int
+	Inventory map[int]int
+}
+
+// validateInventory checks that inventory only lists known sizes with
+// non-negative counts.
+func validateInventory(inventory map[int]int, sizes []int) error {
+	for size, count := range inventory {
+		if !slices.Contains(sizes, size) {
+			return fmt.Errorf("inventory given for unknown pack size %d", size)
+		}
+		if count < 0 {
+			return fmt.Errorf("inventory for pack size %d must not be negative", size)
+		}
+	}
+	return nil
+}
+
+// boundedPackTable returns the fewest packs summing exactly to each total
+// 0..maxSize when at most inventory[size] packs of a listed size may be
+// used; unreachable totals hold math.MaxInt32. Without inventory it reads
+// the unbounded buildPackTable. Limited sizes are split into bundles of 1,
+// 2, 4, ... packs, each used at most once, which reach every count up to
+// the limit.
+func boundedPackTable(sizes []int, inventory map[int]int, maxSize int) []int {
+	packs := make([]int, maxSize+1)
+	if inventory == nil {
+		for i, entry := range buildPackTable(sizes, maxSize) {
+			packs[i] = entry.packs
+		}
+		return packs
+	}
+
+	for i := range packs {
+		packs[i] = math.MaxInt32
+	}
+	packs[0] = 0
+	for _, size := range sizes {
+		count, limited := inventory[size]
+		if !limited {
+			for t := size; t <= maxSize; t++ {
+				if packs[t-size] != math.MaxInt32 && packs[t-size]+1 < packs[t] {
+					packs[t] = packs[t-size] + 1
+				}
+			}
+			continue
+		}
+		for bundle := 1; count > 0; bundle *= 2 {
+			k := min(bundle, count)
+			count -= k
+			weight := k * size
+			for t := maxSize; t >= weight; t-- {
+				if packs[t-weight] != math.MaxInt32 && packs[t-weight]+k < packs[t] {
+					packs[t] = packs[t-weight] + k
+				}
+			}
+		}
+	}
+	return packs
+}
+
+// infeasibleQuantities maps the quantities from..to of catalog that no mix
+// satisfies under constraints. One table covers the whole range: under the
+// overfill policy each quantity's least total is the next feasible one,
+// found by scanning down from to plus the largest size. That always holds
+// it: dropping any pack from a total at least the largest size above the
+// quantity leaves a smaller total, within every constraint, that still
+// covers it.
+func infeasibleQuantities(catalog Catalog, from, to int, constraints RangeConstraints) (*InfeasibleRange, error) {
+	if from <= 0 || to < from {
+		return nil, fmt.Errorf("from must be positive and to at least from")
+	}
+	if len(catalog.PackSizes) == 0 {
+		return nil, fmt.Errorf("catalog has no pack sizes")
+	}
+	policy := constraints.Policy
+	if policy == "" {
+		policy = fulfillmentPolicy
+	}
+	switch {
+	case !validPolicy(policy):
+		return nil, fmt.Errorf("unknown policy %q", policy)
+	case policy == PolicyAllowUnderfill:
+		return nil, fmt.Errorf("policy %q is not supported for infeasible ranges", PolicyAllowUnderfill)
+	case constraints.MaxWaste < 0:
+		return nil, fmt.Errorf("maxWaste must not be negative")
+	case constraints.MaxPacks < 0:
+		return nil, fmt.Errorf("maxPacks must not be negative")
+	}
+	if err := validateInventory(constraints.Inventory, catalog.PackSizes); err != nil {
+		return nil, err
+	}
+
+	sizes := sortedDescending(catalog.PackSizes)
+	maxSize, err := searchWindow(to, sizes, OptimizeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	packs := boundedPackTable(sizes, constraints.Inventory, maxSize)
+	feasible := func(total int) bool {
+		if constraints.MaxPacks > 0 {
+			return packs[total] <= constraints.MaxPacks
+		}
+		return packs[total] != math.MaxInt32
+	}
+
+	infeasible := make([]bool, to-from+1)
+	next := -1
+	for q := maxSize; q >= from; q-- {
+		if feasible(q) {
+			next = q
+		}
+		if q > to {
+			continue
+		}
+		if policy == PolicyExact {
+			infeasible[q-from] = next != q
+		} else {
+			infeasible[q-from] = next == -1 || constraints.MaxWaste > 0 && next-q > constraints.MaxWaste
+		}
+	}
+
+	result := &InfeasibleRange{From: from, To: to, Infeasible: []QuantityRange{}}
+	for i, blocked := range infeasible {
+		if !blocked {
+			continue
+		}
+		result.Count++
+		q := from + i
+		if last := len(result.Infeasible) - 1; last >= 0 && result.Infeasible[last].To == q-1 {
+			result.Infeasible[last].To = q
+		} else {
+			result.Infeasible = append(result.Infeasible, QuantityRange{From: q, To: q})
+		}
+	}
+	return result, nil
+}
+
+// HTTP handler mapping the infeasible quantities of a range
+func infeasibleRangeHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		From      int         `json:"from"`
+		To        int         `json:"to"`
+		Catalog   string      `json:"catalog"`
+		Policy    string      `json:"policy"`
+		MaxWaste  int         `json:"maxWaste"`
+		MaxPacks  int         `json:"maxPacks"`
+		Inventory map[int]int `json:"inventory"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	catalogName := request.Catalog
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", request.Catalog), http.StatusNotFound)
+		return
+	}
+
+	// The catalog's per-size waste caps depend on the mix shipped, which the
+	// table does not track, so only its other defaults apply.
+	opts := catalog.Defaults.apply(OptimizeOptions{Policy: request.Policy, MaxWaste: request.MaxWaste, MaxPacks: request.MaxPacks})
+	result, err := infeasibleQuantities(catalog, request.From, request.To, RangeConstraints{
+		Policy:    opts.Policy,
+		MaxWaste:  opts.MaxWaste,
+		MaxPacks:  opts.MaxPacks,
+		Inventory: request.Inventory,
+	})
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBoundedPackTable(t *testing.T) {
+	packs := boundedPackTable([]int{500, 250}, map[int]int{500: 1, 250: 5}, 2000)
+
+	for total, want := range map[int]int{0: 0, 250: 1, 500: 1, 750: 2, 1000: 3, 1750: 6, 2000: math.MaxInt32} {
+		if packs[total] != want {
+			t.Errorf("packs[%d] = %d, want %d", total, packs[total], want)
+		}
+	}
+}
+
+func TestInfeasibleQuantities(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	testCases := []struct {
+		description string
+		from, to    int
+		constraints RangeConstraints
+		want        []QuantityRange
+		wantCount   int
+	}{
+		{"unconstrained", 1, 10000, RangeConstraints{}, []QuantityRange{}, 0},
+		{"inventory exhausted above its total", 1, 10000,
+			RangeConstraints{Inventory: map[int]int{5000: 1, 2000: 1, 1000: 1, 500: 1, 250: 1}},
+			[]QuantityRange{{From: 8751, To: 10000}}, 1250},
+		{"maxPacks", 9000, 12000, RangeConstraints{MaxPacks: 2},
+			[]QuantityRange{{From: 10001, To: 12000}}, 2000},
+		{"maxWaste", 1, 1000, RangeConstraints{Inventory: map[int]int{250: 0, 500: 0}, MaxWaste: 100},
+			[]QuantityRange{{From: 1, To: 899}}, 899},
+		{"exact", 1, 600, RangeConstraints{Policy: PolicyExact},
+			[]QuantityRange{{From: 1, To: 249}, {From: 251, To: 499}, {From: 501, To: 600}}, 598},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := infeasibleQuantities(catalog, tc.from, tc.to, tc.constraints)
+			if err != nil {
+				t.Fatalf("infeasibleQuantities returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Infeasible, tc.want) || result.Count != tc.wantCount {
+				t.Errorf("infeasible = %+v (%d), want %+v (%d)", result.Infeasible, result.Count, tc.want, tc.wantCount)
+			}
+		})
+	}
+}
+
+func TestInfeasibleRangeHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := serveJSON(t, http.MethodPost, "/optimize/infeasible-range", `{"from": 8000, "to": 9000, "inventory": {"5000": 1, "2000": 1, "1000": 1, "500": 1, "250": 1}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/infeasible-range status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result InfeasibleRange
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := []QuantityRange{{From: 8751, To: 9000}}; !reflect.DeepEqual(result.Infeasible, want) || result.Count != 250 {
+		t.Errorf("infeasible = %+v (%d), want %+v (250)", result.Infeasible, result.Count, want)
+	}
+
+	for _, body := range []string{
+		`{"from": 10, "to": 5}`,
+		`{"from": 1, "to": 100, "inventory": {"300": 1}}`,
+		`{"from": 1, "to": 100, "policy": "allow-underfill"}`,
+	} {
+		if rec := serveJSON(t, http.MethodPost, "/optimize/infeasible-range", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize/infeasible-range %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build debug
+
+package main
+
+import "fmt"
+
+// checkInvariants panics if result is not a consistent rendering of counts.
+// It is compiled in only with the debug build tag.
+func checkInvariants(result *OptimizationResult, counts map[int]int) {
+	items, packs := 0, 0
+	for size, qty := range counts {
+		items += size * qty
+		packs += qty
+	}
+	if items != result.TotalItems {
+		panic(fmt.Sprintf("invariant violated for order %d: packs %v sum to %d items, result reports %d",
+			result.OrderQuantity, counts, items, result.TotalItems))
+	}
+	if packs != result.TotalPacks {
+		panic(fmt.Sprintf("invariant violated for order %d: counts %v hold %d packs, result reports %d",
+			result.OrderQuantity, counts, packs, result.TotalPacks))
+	}
+
+	listed := 0
+	for _, pack := range result.Packs {
+		if pack.Quantity <= 0 {
+			panic(fmt.Sprintf("invariant violated for order %d: pack line %+v has no packs",
+				result.OrderQuantity, pack))
+		}
+		listed += pack.Quantity
+	}
+	if listed != result.TotalPacks {
+		panic(fmt.Sprintf("invariant violated for order %d: pack lines %v hold %d packs, result reports %d",
+			result.OrderQuantity, result.Packs, listed, result.TotalPacks))
+	}
+	if result.Waste-result.Shortfall != result.TotalItems-result.OrderQuantity || result.Waste < 0 || result.Shortfall < 0 {
+		panic(fmt.Sprintf("invariant violated for order %d: waste %d and shortfall %d for %d items",
+			result.OrderQuantity, result.Waste, result.Shortfall, result.TotalItems))
+	}
+}
@@ -0,0 +1,34 @@
+//go:build debug
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptimizePacksInvariants(t *testing.T) {
+	for _, mode := range []string{ModeFewestPacks, ModeFewestLines} {
+		for _, quantity := range []int{1, 249, 250, 251, 501, 999, 4999, 5001, 12001, 23456} {
+			if _, err := OptimizePacksWithOptions(quantity, OptimizeOptions{Mode: mode}); err != nil {
+				t.Errorf("OptimizePacksWithOptions(%d, %s) returned error: %v", quantity, mode, err)
+			}
+		}
+	}
+}
+
+func TestCheckInvariantsPanicsOnMismatch(t *testing.T) {
+	result := buildResult(300, 500, []int{500, 250}, map[int]int{250: 2})
+	result.TotalItems = 750
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("checkInvariants did not panic on an inconsistent result")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "sum to 500 items, result reports 750") {
+			t.Errorf("checkInvariants panic = %v, want a detailed item mismatch", r)
+		}
+	}()
+	checkInvariants(result, map[int]int{250: 2})
+}
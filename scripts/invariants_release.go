@@ -0,0 +1,6 @@
+//go:build !debug
+
+package main
+
+// checkInvariants is a no-op outside debug builds; see invariants_debug.go.
+func checkInvariants(result *OptimizationResult, counts map[int]int) {}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// optimizeLimiter bounds the optimize computations, and so the DP tables, in
+// memory at once.
+var optimizeLimiter = newLimiter(envInt("MAX_CONCURRENT_OPTIMIZATIONS", 64))
+
+// limiterRetryAfter is the Retry-After hint, in seconds, sent with a 429.
+const limiterRetryAfter = 1
+
+// limiter sheds requests once its computation slots are full instead of
+// queueing them.
+type limiter struct {
+	slots chan struct{}
+}
+
+// newLimiter returns a limiter with n slots; n <= 0 disables limiting.
+func newLimiter(n int) *limiter {
+	if n <= 0 {
+		return &limiter{}
+	}
+	return &limiter{slots: make(chan struct{}, n)}
+}
+
+func (l *limiter) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if l.slots == nil || r.Method == "OPTIONS" {
+			next(w, r)
+			return
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+			defer func() { <-l.slots }()
+			next(w, r)
+		default:
+			enableCORS(w, r)
+			w.Header().Set("Retry-After", strconv.Itoa(limiterRetryAfter))
+			writeError(w, "Server busy, retry later", http.StatusTooManyRequests)
+		}
+	}
+}
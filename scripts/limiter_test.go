@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLimiterRejectsOverflowRequests(t *testing.T) {
+	const slots = 3
+	release := make(chan struct{})
+	started := make(chan struct{}, slots+1)
+
+	handler := newLimiter(slots).wrap(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		optimizeHandler(w, r)
+	})
+
+	var done sync.WaitGroup
+	for i := 0; i < slots; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`))
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("in-slot request status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		}()
+	}
+	for i := 0; i < slots; i++ {
+		<-started
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("overflow request status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("overflow Retry-After = %q, want %q", got, "1")
+	}
+	errorMessage(t, rec)
+
+	close(release)
+	done.Wait()
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("request after slots freed status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	handler := newLimiter(0).wrap(optimizeHandler)
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 1}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("unlimited request status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRouterLimitsTableEndpoints(t *testing.T) {
+	saved := optimizeLimiter
+	optimizeLimiter = newLimiter(1)
+	t.Cleanup(func() { optimizeLimiter = saved })
+	optimizeLimiter.slots <- struct{}{}
+	router := newRouter()
+
+	for _, target := range []string{
+		"/optimize/min-waste?quantity=501",
+		"/packages/coverage?max=1000",
+		"/packages/worst-waste?max=1000",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("GET %s with every slot taken status = %d, want %d", target, rec.Code, http.StatusTooManyRequests)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"net/http"
+)
+
+// catalogLock freezes the catalogs while PACK_CONFIG_READONLY is set: the
+// endpoints it wraps still answer GET but refuse every modification.
+var catalogLock = &readOnlyGuard{locked: envBool("PACK_CONFIG_READONLY", false)}
+
+// readOnlyGuard answers 403 to the methods that would modify what the
+// wrapped handler serves while locked, and logs each attempt.
+type readOnlyGuard struct {
+	locked bool
+}
+
+func (g *readOnlyGuard) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !g.locked || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == "OPTIONS" {
+			next(w, r)
+			return
+		}
+
+		log.Printf("refused change to read-only pack configuration: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		enableCORS(w, r)
+		writeError(w, "Pack configuration is read-only", http.StatusForbidden)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withCatalogLock sets whether the catalogs are read-only for the duration
+// of a test.
+func withCatalogLock(t *testing.T, locked bool) {
+	t.Helper()
+	saved := catalogLock.locked
+	catalogLock.locked = locked
+	t.Cleanup(func() { catalogLock.locked = saved })
+}
+
+func TestCatalogLock(t *testing.T) {
+	withImportDir(t, map[string]string{"spring.json": `{"packSizes": [300, 700]}`})
+
+	modifications := []struct {
+		method, target, body string
+	}{
+		{http.MethodPost, "/packages", `{"packSizes": [100, 200]}`},
+		{http.MethodPost, "/packages/reset", ""},
+		{http.MethodPost, "/packages/import", `{"path": "spring.json"}`},
+		{http.MethodPut, "/packages/eu", `{"packSizes": [400]}`},
+		{http.MethodDelete, "/packages/eu", ""},
+		{http.MethodPost, "/t/acme/packages", `{"packSizes": [100]}`},
+	}
+
+	for _, locked := range []bool{true, false} {
+		for _, tc := range modifications {
+			t.Run(fmt.Sprintf("%s %s locked=%t", tc.method, tc.target, locked), func(t *testing.T) {
+				withPackSizes(t, []int{250, 500})
+				withNamedCatalogs(t)
+				namedCatalogs.put("eu", Catalog{PackSizes: []int{400}})
+				withCatalogLock(t, locked)
+				var logs bytes.Buffer
+				saved := log.Writer()
+				log.SetOutput(&logs)
+				t.Cleanup(func() { log.SetOutput(saved) })
+
+				rec := serveJSON(t, tc.method, tc.target, tc.body)
+				if locked {
+					if rec.Code != http.StatusForbidden {
+						t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+					}
+					if !reflect.DeepEqual(PackSizes, []int{250, 500}) {
+						t.Errorf("refused change updated PackSizes to %v", PackSizes)
+					}
+					if catalog, ok := namedCatalogs.lookup("eu"); !ok || !reflect.DeepEqual(catalog.PackSizes, []int{400}) {
+						t.Errorf("refused change updated catalog eu to %+v", catalog)
+					}
+					if !strings.Contains(logs.String(), "read-only pack configuration: "+tc.method+" "+tc.target) {
+						t.Errorf("refused change was not logged: %q", logs.String())
+					}
+				} else if rec.Code != http.StatusOK {
+					t.Errorf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestCatalogLockAllowsReads(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withNamedCatalogs(t)
+	namedCatalogs.put("eu", Catalog{PackSizes: []int{400}})
+	withCatalogLock(t, true)
+
+	for _, target := range []string{"/packages", "/packages/eu"} {
+		if rec := serveJSON(t, http.MethodGet, target, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s while locked status = %d, want %d", target, rec.Code, http.StatusOK)
+		}
+	}
+	if rec := postOptimize(t, `{"quantity": 251}`); rec.Code != http.StatusOK {
+		t.Errorf("POST /optimize while locked status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "fmt"
+
+// manifestContentType selects the shipping manifest representation of a
+// result, in the schema our WMS ingests.
+const manifestContentType = "application/vnd.manifest+json"
+
+// Manifest is a result as a shipping manifest: one line item per pack size,
+// referencing the customer's order.
+type Manifest struct {
+	OrderRef      string         `json:"orderRef,omitempty"`
+	OrderQuantity int            `json:"orderQuantity"`
+	TotalItems    int            `json:"totalItems"`
+	TotalPacks    int            `json:"totalPacks"`
+	Waste         int            `json:"waste"`
+	Shortfall     int            `json:"shortfall,omitempty"`
+	LineItems     []ManifestLine `json:"lineItems"`
+}
+
+// ManifestLine ships Quantity packs of one size, ItemCount items in all.
+type ManifestLine struct {
+	Line        int    `json:"line"`
+	SKU         string `json:"sku"`
+	Description string `json:"description,omitempty"`
+	PackSize    int    `json:"packSize"`
+	Quantity    int    `json:"quantity"`
+	ItemCount   int    `json:"itemCount"`
+}
+
+// packSKU is the identifier of a pack size on a manifest.
+func packSKU(size int) string {
+	return fmt.Sprintf("PACK-%d", size)
+}
+
+// buildManifest maps result onto a manifest for the order orderRef. Lines
+// are numbered from 1 in the order of result.Packs, and zero-quantity lines
+// added by includeUnused are left out.
+func buildManifest(result *OptimizationResult, orderRef string) *Manifest {
+	manifest := &Manifest{
+		OrderRef:      orderRef,
+		OrderQuantity: result.OrderQuantity,
+		TotalItems:    result.TotalItems,
+		TotalPacks:    result.TotalPacks,
+		Waste:         result.Waste,
+		Shortfall:     result.Shortfall,
+		LineItems:     []ManifestLine{},
+	}
+	for _, pack := range result.Packs {
+		if pack.Quantity == 0 {
+			continue
+		}
+		manifest.LineItems = append(manifest.LineItems, ManifestLine{
+			Line:        len(manifest.LineItems) + 1,
+			SKU:         packSKU(pack.PackSize),
+			Description: pack.Label,
+			PackSize:    pack.PackSize,
+			Quantity:    pack.Quantity,
+			ItemCount:   pack.PackSize * pack.Quantity,
+		})
+	}
+	return manifest
+}
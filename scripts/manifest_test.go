@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildManifest(t *testing.T) {
+	result := &OptimizationResult{
+		OrderQuantity: 12001,
+		TotalItems:    12250,
+		TotalPacks:    4,
+		Waste:         249,
+		Packs: []PackResult{
+			{PackSize: 5000, Quantity: 2, Label: "Bulk"},
+			{PackSize: 2000, Quantity: 1},
+			{PackSize: 1000},
+			{PackSize: 250, Quantity: 1},
+		},
+	}
+
+	manifest := buildManifest(result, "SO-1042")
+
+	want := &Manifest{
+		OrderRef:      "SO-1042",
+		OrderQuantity: 12001,
+		TotalItems:    12250,
+		TotalPacks:    4,
+		Waste:         249,
+		LineItems: []ManifestLine{
+			{Line: 1, SKU: "PACK-5000", Description: "Bulk", PackSize: 5000, Quantity: 2, ItemCount: 10000},
+			{Line: 2, SKU: "PACK-2000", PackSize: 2000, Quantity: 1, ItemCount: 2000},
+			{Line: 3, SKU: "PACK-250", PackSize: 250, Quantity: 1, ItemCount: 250},
+		},
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("buildManifest = %+v, want %+v", manifest, want)
+	}
+}
+
+func TestOptimizeManifest(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	for _, tc := range []struct {
+		description, target, accept string
+	}{
+		{"accept header", "/optimize", manifestContentType},
+		{"format parameter", "/optimize?format=manifest", ""},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(`{"quantity": 501, "orderRef": "SO-7"}`))
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			optimizeHandler(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST %s status = %d: %s", tc.target, rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != manifestContentType {
+				t.Errorf("Content-Type = %q, want %q", got, manifestContentType)
+			}
+
+			var manifest map[string]any
+			if err := json.NewDecoder(rec.Body).Decode(&manifest); err != nil {
+				t.Fatalf("decoding manifest: %v", err)
+			}
+			if manifest["orderRef"] != "SO-7" || manifest["totalItems"] != 750.0 {
+				t.Errorf("manifest = %v, want orderRef SO-7 and 750 items", manifest)
+			}
+			lines, _ := manifest["lineItems"].([]any)
+			if len(lines) != 2 {
+				t.Fatalf("lineItems = %v, want 2 lines", manifest["lineItems"])
+			}
+			wantLine := map[string]any{"line": 1.0, "sku": "PACK-500", "packSize": 500.0, "quantity": 1.0, "itemCount": 500.0}
+			if !reflect.DeepEqual(lines[0], wantLine) {
+				t.Errorf("lineItems[0] = %v, want %v", lines[0], wantLine)
+			}
+		})
+	}
+
+	rec := postOptimize(t, `{"quantity": 501, "orderRef": "SO-7"}`)
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("default Content-Type = %q, want application/json", got)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// MergeResults combines separately computed plans into one: the quantities
+// of each pack size are summed, and the totals, waste and shortfall are
+// recomputed against combinedOrder. Packs are listed largest first, keeping
+// the first label given for each size, and nil results are skipped. Other
+// fields, such as costs or diagnostics, describe a single plan and are not
+// carried over.
+func MergeResults(combinedOrder int, results ...*OptimizationResult) *OptimizationResult {
+	counts := make(map[int]int)
+	labels := make(map[int]string)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, pack := range result.Packs {
+			counts[pack.PackSize] += pack.Quantity
+			if labels[pack.PackSize] == "" {
+				labels[pack.PackSize] = pack.Label
+			}
+		}
+	}
+
+	sizes := make([]int, 0, len(counts))
+	totalItems := 0
+	for size, qty := range counts {
+		sizes = append(sizes, size)
+		totalItems += size * qty
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	merged := buildResult(combinedOrder, totalItems, sizes, counts)
+	applyLabels(merged, labels)
+	return merged
+}
+
+// validateMergeInput checks that every plan to merge lists positive pack
+// sizes and non-negative quantities.
+func validateMergeInput(results []*OptimizationResult) error {
+	if len(results) == 0 {
+		return fmt.Errorf("results must contain at least one result")
+	}
+	for i, result := range results {
+		if result == nil {
+			return fmt.Errorf("results[%d] must not be null", i)
+		}
+		for _, pack := range result.Packs {
+			if pack.PackSize <= 0 || pack.Quantity < 0 {
+				return fmt.Errorf("results[%d] packs need a positive packSize and a non-negative quantity", i)
+			}
+		}
+	}
+	return nil
+}
+
+// HTTP handler for merging separately computed plans
+func mergeHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Results       []*OptimizationResult `json:"results"`
+		OrderQuantity int                   `json:"orderQuantity,omitempty"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateMergeInput(request.Results); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.OrderQuantity < 0 {
+		writeError(w, "orderQuantity must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	// Without a combined order the plans are merged against the sum of
+	// their own orders.
+	combinedOrder := request.OrderQuantity
+	if combinedOrder == 0 {
+		for _, result := range request.Results {
+			combinedOrder += result.OrderQuantity
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MergeResults(combinedOrder, request.Results...))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMergeResults(t *testing.T) {
+	first := &OptimizationResult{OrderQuantity: 501, TotalItems: 750, TotalPacks: 2, Waste: 249,
+		Packs: []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}}
+	second := &OptimizationResult{OrderQuantity: 700, TotalItems: 750, TotalPacks: 2, Waste: 50,
+		Packs: []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1, Label: "Small"}}}
+
+	merged := MergeResults(1201, first, nil, second)
+
+	want := []PackResult{{PackSize: 500, Quantity: 2}, {PackSize: 250, Quantity: 2, Label: "Small"}}
+	if !reflect.DeepEqual(merged.Packs, want) {
+		t.Errorf("packs = %+v, want %+v", merged.Packs, want)
+	}
+	if merged.OrderQuantity != 1201 || merged.TotalItems != 1500 || merged.TotalPacks != 4 || merged.Waste != 299 {
+		t.Errorf("merged = order %d, %d items, %d packs, waste %d; want 1201, 1500, 4, 299",
+			merged.OrderQuantity, merged.TotalItems, merged.TotalPacks, merged.Waste)
+	}
+
+	short := MergeResults(2000, first, second)
+	if short.Waste != 0 || short.Shortfall != 500 {
+		t.Errorf("merged against 2000 = waste %d, shortfall %d; want 0, 500", short.Waste, short.Shortfall)
+	}
+}
+
+func TestMergeHandler(t *testing.T) {
+	body := `{"results": [
+		{"orderQuantity": 501, "totalItems": 750, "totalPacks": 2, "waste": 249, "packs": [{"packSize": 500, "quantity": 1}, {"packSize": 250, "quantity": 1}]},
+		{"orderQuantity": 12001, "totalItems": 12250, "totalPacks": 4, "waste": 249, "packs": [{"packSize": 5000, "quantity": 2}, {"packSize": 2000, "quantity": 1}, {"packSize": 250, "quantity": 1}]}
+	]}`
+	rec := serveJSON(t, http.MethodPost, "/optimize/merge", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/merge status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var merged OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&merged); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if merged.OrderQuantity != 12502 || merged.TotalItems != 13000 || merged.TotalPacks != 6 || merged.Waste != 498 {
+		t.Errorf("merged = order %d, %d items, %d packs, waste %d; want 12502, 13000, 6, 498",
+			merged.OrderQuantity, merged.TotalItems, merged.TotalPacks, merged.Waste)
+	}
+
+	for _, body := range []string{`{"results": []}`, `{"results": [null]}`, `{"results": [{"packs": [{"packSize": 0, "quantity": 1}]}]}`} {
+		if rec := serveJSON(t, http.MethodPost, "/optimize/merge", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize/merge %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
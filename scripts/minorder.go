@@ -0,0 +1,29 @@
+package main
+
+import "fmt"
+
+// minOrderQuantity is the server default for OptimizeOptions.MinOrderQuantity:
+// the supplier's minimum order, or zero for none.
+var minOrderQuantity = envInt("MIN_ORDER_QUANTITY", 0)
+
+// resolveMinOrder resolves a request's minOrderQuantity field, falling back
+// to the server default when it is left out. Zero disables the minimum.
+func resolveMinOrder(requested *int) (int, error) {
+	if requested == nil {
+		return minOrderQuantity, nil
+	}
+	if *requested < 0 {
+		return 0, fmt.Errorf("minOrderQuantity must not be negative")
+	}
+	return *requested, nil
+}
+
+// rebaseOrder reports result, packed for a larger order, against the
+// orderQuantity the customer placed: the extra items count as waste.
+func rebaseOrder(result *OptimizationResult, orderQuantity int) {
+	result.OrderQuantity = orderQuantity
+	result.Waste, result.Shortfall = result.TotalItems-orderQuantity, 0
+	if result.Waste < 0 {
+		result.Waste, result.Shortfall = 0, -result.Waste
+	}
+}
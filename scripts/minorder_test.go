@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withMinOrderQuantity sets the server's minimum order for the duration of a
+// test.
+func withMinOrderQuantity(t *testing.T, minimum int) {
+	t.Helper()
+	saved := minOrderQuantity
+	minOrderQuantity = minimum
+	t.Cleanup(func() { minOrderQuantity = saved })
+}
+
+func TestMinOrderQuantity(t *testing.T) {
+	withPackSizes(t, []int{50, 100})
+
+	testCases := []struct {
+		description   string
+		serverMinimum int
+		body          string
+		want          OptimizationResult
+	}{
+		{
+			"order below the server minimum is bumped",
+			100,
+			`{"quantity": 30}`,
+			OptimizationResult{OrderQuantity: 30, TotalItems: 100, TotalPacks: 1, Packs: []PackResult{{PackSize: 100, Quantity: 1}}, Waste: 70, BumpedTo: 100},
+		},
+		{
+			"order at the minimum is kept",
+			100,
+			`{"quantity": 100}`,
+			OptimizationResult{OrderQuantity: 100, TotalItems: 100, TotalPacks: 1, Packs: []PackResult{{PackSize: 100, Quantity: 1}}},
+		},
+		{
+			"request sets a minimum",
+			0,
+			`{"quantity": 30, "minOrderQuantity": 100}`,
+			OptimizationResult{OrderQuantity: 30, TotalItems: 100, TotalPacks: 1, Packs: []PackResult{{PackSize: 100, Quantity: 1}}, Waste: 70, BumpedTo: 100},
+		},
+		{
+			"request disables the server minimum",
+			100,
+			`{"quantity": 30, "minOrderQuantity": 0}`,
+			OptimizationResult{OrderQuantity: 30, TotalItems: 50, TotalPacks: 1, Packs: []PackResult{{PackSize: 50, Quantity: 1}}, Waste: 20},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withMinOrderQuantity(t, tc.serverMinimum)
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize %s status = %d: %s", tc.body, rec.Code, rec.Body.String())
+			}
+			var got OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("POST /optimize %s = %+v, want %+v", tc.body, got, tc.want)
+			}
+		})
+	}
+
+	if rec := postOptimize(t, `{"quantity": 30, "minOrderQuantity": -1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative minOrderQuantity status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := postOptimize(t, `{"minQuantity": 30, "maxQuantity": 60, "minOrderQuantity": 100}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("minimum above maxQuantity status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBatchMinOrderQuantity(t *testing.T) {
+	withPackSizes(t, []int{50, 100})
+	withMinOrderQuantity(t, 100)
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize/batch", strings.NewReader(`{"quantities": [30, 140]}`))
+	rec := httptest.NewRecorder()
+	batchHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/batch status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got BatchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if bumped := got.Results[0].Result; bumped == nil || bumped.TotalItems != 100 || bumped.Waste != 70 || bumped.BumpedTo != 100 {
+		t.Errorf("batch item for 30 = %+v, want 100 items bumped from 30", bumped)
+	}
+	if kept := got.Results[1].Result; kept == nil || kept.TotalItems != 150 || kept.BumpedTo != 0 {
+		t.Errorf("batch item for 140 = %+v, want 150 items without a bump", kept)
+	}
+}
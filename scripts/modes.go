@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Optimization modes. The pack modes first minimize waste and differ in how
+// they break ties between combinations of the same total; ModeCheapest and
+// ModeMinVolume minimize cost or shipped volume instead.
+const (
+	// ModeFewestPacks minimizes the total number of packs. It is the default.
+	ModeFewestPacks = "fewestPacks"
+	// ModeFewestLines minimizes the number of distinct pack sizes (pick
+	// locations), then the total number of packs.
+	ModeFewestLines = "fewestLines"
+	// ModeCheapest minimizes total cost, then waste, then total packs. It
+	// requires per-size costs.
+	ModeCheapest = "cheapest"
+	// ModeMinVolume minimizes the total volume shipped, then waste, then
+	// total packs, so fewer large boxes win only when they pack items more
+	// densely. It requires per-size volumes.
+	ModeMinVolume = "minVolume"
+	// ModeUniform accepts up to the waste delta more waste than the minimum
+	// to maximize the share of packs of a single size, then minimizes waste
+	// and total packs, for lines that run best on one size.
+	ModeUniform = "uniform"
+)
+
+// supportedModes lists every mode accepted by OptimizePacksWithOptions.
+var supportedModes = []string{ModeFewestPacks, ModeFewestLines, ModeCheapest, ModeMinVolume, ModeUniform}
+
+// perPackMode reports whether mode minimizes a per-pack quantity, cost or
+// volume, with its own table rather than waste first.
+func perPackMode(mode string) bool {
+	return mode == ModeCheapest || mode == ModeMinVolume
+}
+
+// validMode reports whether mode names a supported mode. The empty mode
+// selects ModeFewestPacks.
+func validMode(mode string) bool {
+	return mode == "" || slices.Contains(supportedModes, mode)
+}
+
+// maxFewestLinesSizes bounds the catalogs ModeFewestLines accepts: its
+// search builds a table per subset of sizes, 2^n - 1 of them at worst.
+const maxFewestLinesSizes = 12
+
+// validateFewestLines checks that a fewestLines request's catalog of sizes
+// is small enough to search subset by subset.
+func validateFewestLines(opts OptimizeOptions, sizes []int) error {
+	if opts.Mode == ModeFewestLines && len(sizes) > maxFewestLinesSizes {
+		return fmt.Errorf("mode %q supports at most %d pack sizes, the catalog has %d", ModeFewestLines, maxFewestLinesSizes, len(sizes))
+	}
+	return nil
+}
+
+// fewestLinesCounts returns the breakdown of amount that uses the fewest
+// distinct sizes, breaking ties by total packs. It tries every subset of sizes
+// in increasing order of subset size and stops at the first size that can
+// reach amount exactly; amount must be reachable with the full catalog.
+// Subsets whose greatest common divisor does not divide amount are skipped,
+// and the tables filled for the rest may hold at most maxDPSize entries in
+// all, so the search costs no more than one table at the memory ceiling.
+func fewestLinesCounts(ctx context.Context, sizes []int, amount int) (map[int]int, error) {
+	var best map[int]int
+	var err error
+	bestPacks := math.MaxInt32
+	entries := 0
+
+	for lines := 1; lines <= len(sizes) && best == nil && err == nil; lines++ {
+		forEachSubset(len(sizes), lines, func(indexes []int) {
+			if err != nil {
+				return
+			}
+			subset := make([]int, len(indexes))
+			divisor := 0
+			for i, idx := range indexes {
+				subset[i] = sizes[idx]
+				divisor = gcd(divisor, sizes[idx])
+			}
+			if amount%divisor != 0 {
+				return
+			}
+			if entries += amount + 1; entries > maxDPSize {
+				err = fmt.Errorf("%w: mode %q needs more than %d table entries to search the pack sizes for %d", ErrWindowTooLarge, ModeFewestLines, maxDPSize, amount)
+				return
+			}
+			var dp []dpEntry
+			if dp, err = fillPackTable(ctx, subset, amount); err != nil {
+				return
+			}
+			if dp[amount].packs < bestPacks {
+				bestPacks = dp[amount].packs
+				best = packCounts(dp, amount)
+			}
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// forEachSubset calls fn with every k-element subset of 0..n-1, as ascending
+// index slices in lexicographic order. fn must not retain the slice.
+func forEachSubset(n, k int, fn func(indexes []int)) {
+	indexes := make([]int, k)
+	var walk func(pos, next int)
+	walk = func(pos, next int) {
+		if pos == k {
+			fn(indexes)
+			return
+		}
+		for i := next; i <= n-(k-pos); i++ {
+			indexes[pos] = i
+			walk(pos+1, i+1)
+		}
+	}
+	walk(0, 0)
+}
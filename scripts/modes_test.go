@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizePacksFewestLines(t *testing.T) {
+	testCases := []struct {
+		mode        string
+		input       int
+		wantPacks   []PackResult
+		description string
+	}{
+		{ModeFewestPacks, 3000, []PackResult{{PackSize: 2000, Quantity: 1}, {PackSize: 1000, Quantity: 1}}, "Fewest packs mixes two sizes"},
+		{ModeFewestLines, 3000, []PackResult{{PackSize: 1000, Quantity: 3}}, "Fewest lines uses a single size"},
+		{ModeFewestLines, 7250, []PackResult{{PackSize: 250, Quantity: 29}}, "Fewest lines trades pack count for one location"},
+		{ModeFewestLines, 250, []PackResult{{PackSize: 250, Quantity: 1}}, "Fewest lines on an exact pack"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+			result, err := OptimizePacksWithOptions(tc.input, OptimizeOptions{Mode: tc.mode})
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(%d, %s) returned error: %v", tc.input, tc.mode, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("OptimizePacksWithOptions(%d, %s) packs = %v, want %v",
+					tc.input, tc.mode, result.Packs, tc.wantPacks)
+			}
+		})
+	}
+}
+
+func TestOptimizePacksUnknownMode(t *testing.T) {
+	if _, err := OptimizePacksWithOptions(100, OptimizeOptions{Mode: "cheapest"}); err == nil {
+		t.Errorf("OptimizePacksWithOptions with unknown mode should return error")
+	}
+}
+
+func TestFewestLinesRejectsLargeCatalogs(t *testing.T) {
+	sizes := make([]int, maxFewestLinesSizes+1)
+	for i := range sizes {
+		sizes[i] = 100 + i
+	}
+
+	if _, err := OptimizeCatalog(1000, Catalog{PackSizes: sizes}, OptimizeOptions{Mode: ModeFewestLines}); err == nil {
+		t.Errorf("fewestLines with %d sizes returned no error", len(sizes))
+	}
+	if _, err := OptimizeCatalog(1000, Catalog{PackSizes: sizes[1:]}, OptimizeOptions{Mode: ModeFewestLines}); err != nil {
+		t.Errorf("fewestLines with %d sizes returned error: %v", len(sizes)-1, err)
+	}
+	if _, err := OptimizeCatalog(1000, Catalog{PackSizes: sizes}, OptimizeOptions{}); err != nil {
+		t.Errorf("fewestPacks with %d sizes returned error: %v", len(sizes), err)
+	}
+}
+
+func TestFewestLinesBoundsTableEntries(t *testing.T) {
+	saved := maxDPSize
+	t.Cleanup(func() { maxDPSize = saved })
+	sizes := []int{5000, 2000, 1000, 500, 250}
+
+	// Only the 250 subset divides 7250, so the search fills one table.
+	maxDPSize = 7251
+	counts, err := fewestLinesCounts(context.Background(), sizes, 7250)
+	if err != nil {
+		t.Fatalf("fewestLinesCounts(7250) with a %d-entry budget returned error: %v", maxDPSize, err)
+	}
+	if !reflect.DeepEqual(counts, map[int]int{250: 29}) {
+		t.Errorf("fewestLinesCounts(7250) = %v, want 29 x 250", counts)
+	}
+
+	maxDPSize = 7250
+	if _, err := fewestLinesCounts(context.Background(), sizes, 7250); !errors.Is(err, ErrWindowTooLarge) {
+		t.Errorf("fewestLinesCounts(7250) with a %d-entry budget error = %v, want %v", maxDPSize, err, ErrWindowTooLarge)
+	}
+}
+
+func TestFewestLinesStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fewestLinesCounts(ctx, []int{5000, 2000, 1000, 500, 250}, 3000); !errors.Is(err, context.Canceled) {
+		t.Errorf("fewestLinesCounts with a cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
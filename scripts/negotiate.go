@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// jsonContentType is the media type every endpoint can respond with.
+const jsonContentType = "application/json"
+
+// representations are the media types an endpoint can respond with, in the
+// server's order of preference, plus the ?format= values that select one
+// explicitly, bypassing the Accept header.
+type representations struct {
+	offers  []string
+	formats map[string]string
+}
+
+// produces returns the representations of an endpoint offering the given
+// media types, the first being its default.
+func produces(offers ...string) representations {
+	return representations{offers: offers}
+}
+
+// withFormats returns rs with ?format= values mapped to media types.
+func (rs representations) withFormats(formats map[string]string) representations {
+	rs.formats = formats
+	return rs
+}
+
+// jsonResponse guards the endpoints that only respond with JSON.
+var jsonResponse = produces(jsonContentType)
+
+// negotiate picks the media type to respond to r with. On failure it
+// answers 406, or 400 for an unknown ?format=, and returns false.
+func (rs representations) negotiate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" && rs.formats != nil {
+		if mediaType, ok := rs.formats[format]; ok {
+			return mediaType, true
+		}
+		writeError(w, fmt.Sprintf("Unknown format %q", format), http.StatusBadRequest)
+		return "", false
+	}
+	if mediaType, ok := negotiateAccept(r.Header.Get("Accept"), rs.offers); ok {
+		return mediaType, true
+	}
+	writeError(w, "Not acceptable, this endpoint responds with "+strings.Join(rs.offers, ", "), http.StatusNotAcceptable)
+	return "", false
+}
+
+// wrap answers 406 before calling next when the client accepts none of rs.
+func (rs representations) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "OPTIONS" {
+			if _, ok := rs.negotiate(w, r); !ok {
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept splits an Accept header into its media ranges, skipping
+// malformed entries. A missing q-value is 1.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, entry := range strings.Split(header, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(entry)
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || typ == "" || subtype == "" || typ == "*" && subtype != "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// negotiateAccept picks the offer the Accept header prefers. Each offer
+// takes the q-value of the most specific range matching it, so
+// "text/*;q=0, text/csv" accepts CSV alone, and the first of equally
+// preferred offers wins. A missing header, or one holding no valid range,
+// accepts the first offer. It returns false when every offer has q=0.
+func negotiateAccept(header string, offers []string) (string, bool) {
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return offers[0], true
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, subtype, _ := strings.Cut(offer, "/")
+		q, specificity := 0.0, -1
+		for _, rng := range ranges {
+			var match int
+			switch {
+			case rng.typ == typ && rng.subtype == subtype:
+				match = 2
+			case rng.typ == typ && rng.subtype == "*":
+				match = 1
+			case rng.typ == "*":
+				match = 0
+			default:
+				continue
+			}
+			if match > specificity {
+				q, specificity = rng.q, match
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateAccept(t *testing.T) {
+	offers := []string{jsonContentType, protobufContentType, htmlContentType, csvContentType}
+
+	testCases := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", jsonContentType, true},
+		{"*/*", jsonContentType, true},
+		{"application/x-protobuf", protobufContentType, true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", htmlContentType, true},
+		{"application/json;q=0.5, text/csv", csvContentType, true},
+		{"application/json;q=0.5, text/*;q=0.7", htmlContentType, true},
+		{"text/*;q=0, text/csv", csvContentType, true},
+		{"application/json;q=0, */*", protobufContentType, true},
+		{"TEXT/CSV; charset=utf-8", csvContentType, true},
+		{"image/png", "", false},
+		{"*/*;q=0", "", false},
+		{"application/json;q=oops", jsonContentType, true},
+		{"not a media type", jsonContentType, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accept, func(t *testing.T) {
+			got, ok := negotiateAccept(tc.accept, offers)
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("negotiateAccept(%q) = %q, %v; want %q, %v", tc.accept, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(`{"quantity": 501}`))
+	req.Header.Set("Accept", "image/png")
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("POST /optimize accepting image/png status = %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("GET /capabilities accepting text/csv status = %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/optimize?format=yaml", strings.NewReader(`{"quantity": 501}`))
+	rec = httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /optimize?format=yaml status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/optimize?format=compact", strings.NewReader(`{"quantity": 501}`))
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, compactContentType) {
+		t.Errorf("?format=compact Content-Type = %q, want %q despite Accept", got, compactContentType)
+	}
+}
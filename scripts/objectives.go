@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Objectives holds the least-waste and the fewest-packs answer to one order,
+// for callers that want to weigh the two themselves. When both objectives
+// pick the same packs, MinWaste and MinPacks are the same result and
+// Equivalent is set.
+type Objectives struct {
+	MinWaste   *OptimizationResult `json:"minWaste"`
+	MinPacks   *OptimizationResult `json:"minPacks"`
+	Equivalent bool                `json:"equivalent"`
+}
+
+// bothObjectives solves orderQuantity against catalog twice: once the usual
+// way, least waste first, and once for the fewest packs whatever the waste,
+// preferring less waste among mixes with as few packs. Both keep the rest of
+// opts, such as the policy and a minimum order.
+func bothObjectives(orderQuantity int, catalog Catalog, opts OptimizeOptions) (*Objectives, error) {
+	if opts.Weights != nil {
+		return nil, fmt.Errorf("bothObjectives cannot be combined with weights")
+	}
+	if perPackMode(opts.Mode) {
+		return nil, fmt.Errorf("bothObjectives is not supported with mode %q", opts.Mode)
+	}
+
+	minWaste, err := OptimizeCatalog(orderQuantity, catalog, opts)
+	if err != nil {
+		return nil, err
+	}
+	packOpts := opts
+	packOpts.Mode = ModeFewestPacks
+	packOpts.Weights = &ScoreWeights{Packs: 1}
+	minPacks, err := OptimizeCatalog(orderQuantity, catalog, packOpts)
+	if err != nil {
+		return nil, err
+	}
+	minPacks.Score = nil
+
+	if minPacks.TotalItems == minWaste.TotalItems && reflect.DeepEqual(minPacks.Packs, minWaste.Packs) {
+		return &Objectives{MinWaste: minWaste, MinPacks: minWaste, Equivalent: true}, nil
+	}
+	return &Objectives{MinWaste: minWaste, MinPacks: minPacks}, nil
+}
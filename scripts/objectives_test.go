@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBothObjectives(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	testCases := []struct {
+		description    string
+		quantity       int
+		wantMinWaste   []PackResult
+		wantMinPacks   []PackResult
+		wantEquivalent bool
+	}{
+		{"objectives differ", 501,
+			[]PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, false},
+		{"objectives differ on a larger order", 4750,
+			[]PackResult{{PackSize: 2000, Quantity: 2}, {PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+			[]PackResult{{PackSize: 5000, Quantity: 1}}, false},
+		{"objectives coincide", 1000,
+			[]PackResult{{PackSize: 1000, Quantity: 1}},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			objectives, err := bothObjectives(tc.quantity, catalog, OptimizeOptions{})
+			if err != nil {
+				t.Fatalf("bothObjectives(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(objectives.MinWaste.Packs, tc.wantMinWaste) {
+				t.Errorf("minWaste = %+v, want %+v", objectives.MinWaste.Packs, tc.wantMinWaste)
+			}
+			if !reflect.DeepEqual(objectives.MinPacks.Packs, tc.wantMinPacks) {
+				t.Errorf("minPacks = %+v, want %+v", objectives.MinPacks.Packs, tc.wantMinPacks)
+			}
+			if objectives.Equivalent != tc.wantEquivalent {
+				t.Errorf("equivalent = %v, want %v", objectives.Equivalent, tc.wantEquivalent)
+			}
+			if tc.wantEquivalent && objectives.MinPacks != objectives.MinWaste {
+				t.Errorf("equivalent objectives returned two results")
+			}
+		})
+	}
+
+	if _, err := bothObjectives(501, catalog, OptimizeOptions{Weights: &ScoreWeights{Packs: 1}}); err == nil {
+		t.Errorf("bothObjectives with weights returned no error")
+	}
+}
+
+func TestOptimizeBothObjectives(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 501, "bothObjectives": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Objectives == nil {
+		t.Fatalf("response has no objectives")
+	}
+	if got := result.Objectives; got.MinWaste.Waste != 249 || got.MinPacks.TotalPacks != 1 || got.MinPacks.Waste != 499 || got.Equivalent {
+		t.Errorf("objectives = minWaste %+v, minPacks %+v, want 249 waste and one pack wasting 499", got.MinWaste, got.MinPacks)
+	}
+}
@@ -1,38 +1,79 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 )
 
 // PackResult represents a pack size and quantity combination
 type PackResult struct {
-	PackSize int `json:"packSize"`
-	Quantity int `json:"quantity"`
+	PackSize int    `json:"packSize"`
+	Quantity int    `json:"quantity"`
+	Label    string `json:"label,omitempty"`
 }
 
 // OptimizationResult represents the complete optimization result
 type OptimizationResult struct {
-	OrderQuantity int          `json:"orderQuantity"`
-	TotalItems    int          `json:"totalItems"`
-	TotalPacks    int          `json:"totalPacks"`
-	Packs         []PackResult `json:"packs"`
-	Waste         int          `json:"waste"`
+	OrderQuantity         int                   `json:"orderQuantity"`
+	TotalItems            int                   `json:"totalItems"`
+	TotalPacks            int                   `json:"totalPacks"`
+	Packs                 []PackResult          `json:"packs"`
+	Waste                 int                   `json:"waste"`
+	Shortfall             int                   `json:"shortfall,omitempty"`
+	BumpedTo              int                   `json:"bumpedTo,omitempty"`
+	TotalCost             *float64              `json:"totalCost,omitempty"`
+	Currency              string                `json:"currency,omitempty"`
+	TotalVolume           *float64              `json:"totalVolume,omitempty"`
+	TotalSlots            int                   `json:"totalSlots,omitempty"`
+	Score                 *float64              `json:"score,omitempty"`
+	Savings               *Savings              `json:"savings,omitempty"`
+	Diagnostics           *Diagnostics          `json:"diagnostics,omitempty"`
+	Upsell                *Upsell               `json:"upsell,omitempty"`
+	Tolerances            []ToleranceResult     `json:"tolerances,omitempty"`
+	Alternatives          []*OptimizationResult `json:"alternatives,omitempty"`
+	AlternativesTruncated bool                  `json:"alternativesTruncated,omitempty"`
+	UpgradePath           []UpgradeStep         `json:"upgradePath,omitempty"`
+	Objectives            *Objectives           `json:"objectives,omitempty"`
+	BindingConstraint     string                `json:"bindingConstraint,omitempty"`
+	AlreadyShipped        int                   `json:"alreadyShipped,omitempty"`
+	OverShipped           int                   `json:"overShipped,omitempty"`
+	Uniformity            *Uniformity           `json:"uniformity,omitempty"`
+	Warnings              []string              `json:"warnings,omitempty"`
+	Request               *EchoedRequest        `json:"request,omitempty"`
+	Tree                  *PackingTree          `json:"tree,omitempty"`
+	Lesson                *Lesson               `json:"lesson,omitempty"`
+	Ranges                []ItemRange           `json:"ranges,omitempty"`
+	Permalink             string                `json:"permalink,omitempty"`
+	ServerTime            string                `json:"serverTime,omitempty"`
 }
 
 // Configuration for pack sizes
-var PackSizes = []int{250, 500, 1000, 2000, 5000}
+var PackSizes = slices.Clone(defaultPackSizes)
+
+// defaultPackSizes is the catalog the server starts with and
+// POST /packages/reset restores.
+var defaultPackSizes = []int{250, 500, 1000, 2000, 5000}
+
+// PackLabels holds optional human-readable names for pack sizes, e.g. "Small".
+var PackLabels = map[int]string{}
 
 // CORS middleware
 func enableCORS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+signatureHeader)
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -40,24 +81,582 @@ func enableCORS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// OptimizePacks implements the core pack optimization algorithm
+// OptimizeOptions tunes how OptimizePacksWithOptions chooses between pack
+// combinations. The zero value reproduces OptimizePacks.
+type OptimizeOptions struct {
+	// Mode selects the objective; see the Mode constants.
+	Mode string
+	// Costs is the price of one pack of each size. When set, every configured
+	// size must be priced and the result reports its TotalCost.
+	Costs map[int]float64
+	// Currency is the ISO 4217 code Costs are given in. When set, TotalCost
+	// is rounded to its minor unit and reported with it; see roundCost.
+	Currency string
+	// Volumes is the volume of one pack of each size. When set, every
+	// configured size must have one and the result reports its TotalVolume.
+	Volumes map[int]float64
+	// Policy decides which totals may fill the order; see the Policy
+	// constants. Empty selects the server default, fulfillmentPolicy.
+	Policy string
+	// UnderfillTiebreak decides between an overfilled and an underfilled
+	// total equally near the order under PolicyAllowUnderfill; see the
+	// Tiebreak constants. Empty selects the server default,
+	// underfillTiebreak.
+	UnderfillTiebreak string
+	// PenalizeSmallPacks avoids breakdowns that add a single smallest-size
+	// pack to larger packs, accepting up to WasteDelta extra waste.
+	PenalizeSmallPacks bool
+	// PreferEvenCounts prefers breakdowns using every size an even number of
+	// times, accepting up to WasteDelta extra waste. When none qualifies, the
+	// mix with the fewest odd counts is used and the result warns.
+	PreferEvenCounts bool
+	// WasteDelta is how much more waste than the minimum a near-optimal
+	// solution may carry. Zero means the smallest pack size.
+	WasteDelta int
+	// SearchMargin widens the DP window beyond orderQuantity + the largest
+	// pack. The default window always contains the minimal-waste and the
+	// cheapest totals, so a margin cannot improve the current objectives; it
+	// only costs memory and time, and is bounded by maxDPSize.
+	SearchMargin int
+	// MaxWastePerSize caps the waste attributed to each listed pack size;
+	// see attributeWaste.
+	MaxWastePerSize map[int]int
+	// Weights, when set, choose the total by weighted waste and pack count
+	// instead of waste first.
+	Weights *ScoreWeights
+	// WastePenalty is the exponent waste is raised to in the weighted score,
+	// so 2 makes large waste disproportionately costly against pack count.
+	// Zero is linear. It requires Weights.
+	WastePenalty float64
+	// SpreadTies picks among the breakdowns of the chosen total that use the
+	// fewest packs at random, seeded by Seed, instead of always the same one.
+	// Only the fewestPacks mode supports it.
+	SpreadTies bool
+	Seed       int64
+	// MaxQuantity, when positive, is the largest total the customer accepts.
+	// The order quantity is then the bottom of the acceptable range, and
+	// waste is measured against it.
+	MaxQuantity int
+	// TreatZeroAsEmpty answers a zero order quantity with an empty plan
+	// instead of an error. Negative quantities are still rejected.
+	TreatZeroAsEmpty bool
+	// MinOrderQuantity, when positive, is the supplier's minimum order:
+	// smaller orders are packed as if it had been ordered. Waste is still
+	// measured against the original order, and BumpedTo reports the bump.
+	MinOrderQuantity int
+	// MaxWaste, when positive, is the most items a solution may ship beyond
+	// the order, which caps the totals searched like MaxQuantity.
+	MaxWaste int
+	// MaxPacks, when positive, is the most packs a solution may use. The
+	// objective picks the best total that fits within it, and the result
+	// reports in BindingConstraint whether the limit changed the answer.
+	MaxPacks int
+	// Slots is the number of pallet slots one pack of each size occupies.
+	// When set, every configured size must have one and the result reports
+	// its TotalSlots.
+	Slots map[int]int
+	// MaxSlots, when positive, is the number of slots on the pallet: the
+	// least-waste total some mix fits into them is chosen, and orders that
+	// fit no mix are infeasible. It requires Slots.
+	MaxSlots int
+	// Budget, when positive, is the most the solution may cost under Costs:
+	// the least-waste total some mix reaches within it is chosen, and orders
+	// no mix affords are infeasible. It requires Costs.
+	Budget float64
+	// Diagnostics reports where the chosen total landed in the search window
+	// in the result's Diagnostics.
+	Diagnostics bool
+	// StrictSmallOrders rejects a result that wastes more items than the
+	// order, such as 1 item shipped in a 250 pack, as infeasible.
+	StrictSmallOrders bool
+	// Context, when set, abandons the solve with its error once it is done,
+	// such as when the request behind it timed out.
+	Context context.Context
+	// wasteLimited records that limitWaste set MaxQuantity from MaxWaste.
+	wasteLimited bool
+}
+
+// ErrInfeasible reports that no pack combination satisfies the request.
+var ErrInfeasible = errors.New("no pack combination satisfies the request")
+
+// maxDPSize is the memory ceiling: the largest DP table, in items, a single
+// request may allocate.
+var maxDPSize = envInt("MAX_DP_SIZE", 20_000_000)
+
+// ErrWindowTooLarge reports that a request needs a DP table larger than
+// maxDPSize.
+var ErrWindowTooLarge = errors.New("search window exceeds the memory ceiling")
+
+// OptimizePacks implements the core pack optimization algorithm. It is safe
+// for concurrent use: each call solves on its own copy of PackSizes.
 func OptimizePacks(orderQuantity int) (*OptimizationResult, error) {
+	return OptimizePacksWithOptions(orderQuantity, OptimizeOptions{})
+}
+
+// OptimizePacksWithOptions optimizes orderQuantity against the configured
+// PackSizes using the objective selected by opts. PackSizes keeps the order
+// it was configured in; the optimizer sorts a copy.
+func OptimizePacksWithOptions(orderQuantity int, opts OptimizeOptions) (*OptimizationResult, error) {
+	catalog, _ := namedCatalogs.lookup(defaultCatalogName)
+	return OptimizeCatalog(orderQuantity, catalog, opts)
+}
+
+// OptimizeCatalog optimizes orderQuantity against catalog instead of the
+// default PackSizes. The catalog is not modified.
+func OptimizeCatalog(orderQuantity int, catalog Catalog, opts OptimizeOptions) (*OptimizationResult, error) {
+	if orderQuantity == 0 && opts.TreatZeroAsEmpty {
+		if err := validateOptions(catalog, opts); err != nil {
+			return nil, err
+		}
+		return emptyResult(opts), nil
+	}
 	if orderQuantity <= 0 {
 		return nil, fmt.Errorf("order quantity must be positive")
 	}
+	target := max(orderQuantity, opts.MinOrderQuantity)
+	if opts.MaxQuantity > 0 && opts.MaxQuantity < target {
+		return nil, fmt.Errorf("maxQuantity %d is below the order quantity %d", opts.MaxQuantity, target)
+	}
+	if err := validateOptions(catalog, opts); err != nil {
+		return nil, err
+	}
+	if opts.MaxWaste > 0 && target > orderQuantity+opts.MaxWaste {
+		return nil, fmt.Errorf("%w: the minimum order of %d wastes more than maxWaste %d", ErrInfeasible, target, opts.MaxWaste)
+	}
+
+	result, err := solve(target, sortedDescending(catalog.PackSizes), limitWaste(orderQuantity, opts))
+	if err != nil {
+		return nil, err
+	}
+	if target != orderQuantity {
+		rebaseOrder(result, orderQuantity)
+		result.BumpedTo = target
+	}
+	if opts.StrictSmallOrders {
+		if err := checkSmallOrder(result); err != nil {
+			return nil, err
+		}
+	}
+	applyLabels(result, catalog.Labels)
+	if len(catalog.PackSizes) == 1 {
+		result.Warnings = append(result.Warnings, singleSizeWarning(catalog.PackSizes[0]))
+	}
+	return result, nil
+}
+
+// validateOptions checks the order-independent parts of a request against
+// catalog.
+func validateOptions(catalog Catalog, opts OptimizeOptions) error {
+	if !validMode(opts.Mode) {
+		return fmt.Errorf("unknown mode %q", opts.Mode)
+	}
+	if len(catalog.PackSizes) == 0 {
+		return fmt.Errorf("catalog has no pack sizes")
+	}
+	if err := validateWasteCaps(opts, catalog.PackSizes); err != nil {
+		return err
+	}
+	if err := validateWeights(opts); err != nil {
+		return err
+	}
+	if err := validateSpreadTies(opts); err != nil {
+		return err
+	}
+	if err := validatePackLimit(opts); err != nil {
+		return err
+	}
+	if err := validateVolumes(opts, catalog.PackSizes); err != nil {
+		return err
+	}
+	if err := validateSlots(opts, catalog.PackSizes); err != nil {
+		return err
+	}
+	if err := validateEvenCounts(opts); err != nil {
+		return err
+	}
+	if err := validateUniform(opts); err != nil {
+		return err
+	}
+	if err := validateFewestLines(opts, catalog.PackSizes); err != nil {
+		return err
+	}
+	if err := validateBudget(opts); err != nil {
+		return err
+	}
+	return validateCosts(opts, catalog.PackSizes)
+}
+
+// solve runs the optimizer for a positive orderQuantity against sizes, which
+// must be sorted in descending order.
+func solve(orderQuantity int, sizes []int, opts OptimizeOptions) (*OptimizationResult, error) {
+	sizes = distinctSizes(sizes)
+	observeWindow(orderQuantity, sizes, opts)
+	var dp []dpEntry
+	if needsPackTable(sizes, opts) {
+		maxSize, err := searchWindow(orderQuantity, sizes, opts)
+		if err != nil {
+			return nil, err
+		}
+		if dp, err = fillPackTable(solveContext(opts), sizes, maxSize); err != nil {
+			return nil, err
+		}
+	}
+	result, err := solveWithTable(orderQuantity, sizes, opts, dp)
+	if err == nil && largeTableThreshold > 0 && len(dp)-1 > largeTableThreshold {
+		result.Warnings = append(result.Warnings, largeTableWarning(orderQuantity, len(dp)-1))
+	}
+	return result, err
+}
 
-	sort.Sort(sort.Reverse(sort.IntSlice(PackSizes)))
+// searchWindow returns the largest total considered for orderQuantity: the
+// order plus the largest pack and any SearchMargin, bounded by maxDPSize.
+// A window that would not even fit in an int is rejected before it can wrap
+// around to a negative table length.
+func searchWindow(orderQuantity int, sizes []int, opts OptimizeOptions) (int, error) {
+	maxSize, err := windowSize(orderQuantity, sizes, opts)
+	if err != nil {
+		return 0, err
+	}
+	if maxSize > maxDPSize {
+		return 0, windowTooLarge(orderQuantity, maxSize)
+	}
+	return maxSize, nil
+}
 
+// windowSize is searchWindow before the memory ceiling is applied.
+func windowSize(orderQuantity int, sizes []int, opts OptimizeOptions) (int, error) {
+	if orderQuantity > math.MaxInt-sizes[0] || orderQuantity+sizes[0] > math.MaxInt-opts.SearchMargin {
+		return 0, windowOverflow(orderQuantity, sizes[0], opts.SearchMargin)
+	}
 	// Define upper limit: orderQuantity + max pack size
-	maxSize := orderQuantity + PackSizes[0]
+	return orderQuantity + sizes[0] + opts.SearchMargin, nil
+}
+
+// windowOverflow explains a search window that would not fit in an int.
+func windowOverflow(orderQuantity, largest, searchMargin int) error {
+	return fmt.Errorf("%w: order %d plus the largest pack %d and searchMargin %d overflows the search window", ErrWindowTooLarge, orderQuantity, largest, searchMargin)
+}
+
+// windowTooLarge explains a search window above maxDPSize. The table spans
+// every total up to the window, so it grows with the order whatever the pack
+// sizes; a catalog with a size of 1 is rejected at the same order as any
+// other. Single-size catalogs build no table and are not limited.
+func windowTooLarge(orderQuantity, maxSize int) error {
+	return fmt.Errorf("%w: order %d needs a %d-item search table, limit is %d; split the order or raise MAX_DP_SIZE", ErrWindowTooLarge, orderQuantity, maxSize, maxDPSize)
+}
+
+// largeTableWarning tells the client that its order needed a search table
+// above largeTableThreshold, before it grows into the memory ceiling.
+func largeTableWarning(orderQuantity, maxSize int) string {
+	return fmt.Sprintf("Order %d needed a %d-item search table; orders needing more than %d items are rejected", orderQuantity, maxSize, maxDPSize)
+}
+
+// solveWithTable answers orderQuantity from dp, a pack table covering at
+// least its search window, so one table can serve many orders. dp is nil
+// when needsPackTable says none is needed.
+func solveWithTable(orderQuantity int, sizes []int, opts OptimizeOptions, dp []dpEntry) (*OptimizationResult, error) {
+	sizes = distinctSizes(sizes)
+	policy := opts.Policy
+	if policy == "" {
+		policy = fulfillmentPolicy
+	}
+	if !validPolicy(policy) {
+		return nil, fmt.Errorf("unknown policy %q", policy)
+	}
+	tiebreak := opts.UnderfillTiebreak
+	if tiebreak == "" {
+		tiebreak = underfillTiebreak
+	}
+	if !validTiebreak(tiebreak) {
+		return nil, fmt.Errorf("unknown underfillTiebreak %q", tiebreak)
+	}
+	ctx := solveContext(opts)
+	if err := stopped(ctx); err != nil {
+		return nil, err
+	}
+
+	var bestAmount int
+	var counts map[int]int
+	var diagnostics *Diagnostics
+	binding := BindingNone
+	if perPackMode(opts.Mode) {
+		maxSize, err := searchWindow(orderQuantity, sizes, opts)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi := orderQuantity, maxSize
+		switch policy {
+		case PolicyExact:
+			hi = orderQuantity
+		case PolicyAllowUnderfill:
+			return nil, fmt.Errorf("policy %q is not supported with mode %q", PolicyAllowUnderfill, opts.Mode)
+		}
+		hi = capAtMaxQuantity(hi, opts)
+		perPack := opts.Costs
+		if opts.Mode == ModeMinVolume {
+			perPack = opts.Volumes
+		}
+		if bestAmount, counts, err = cheapestBreakdown(ctx, sizes, lo, hi, maxSize, perPack); err != nil {
+			return nil, err
+		}
+		if bestAmount == -1 {
+			return nil, fmt.Errorf("%w: order %d cannot be filled exactly", ErrInfeasible, orderQuantity)
+		}
+		// The cheapest mix over the window is the one to fit the budget.
+		if cost := breakdownCost(counts, opts.Costs); opts.Budget > 0 && !withinBudget(cost, opts.Budget) {
+			return nil, overBudget(orderQuantity, cost, opts.Budget, opts.Currency)
+		}
+	} else {
+		if dp == nil {
+			// Division needs no table, so the memory ceiling does not
+			// apply, but the next multiple must still fit in an int.
+			if orderQuantity > math.MaxInt-sizes[0] {
+				return nil, windowOverflow(orderQuantity, sizes[0], opts.SearchMargin)
+			}
+			bestAmount = singleSizeAmount(orderQuantity, sizes[0], policy, tiebreak)
+		} else {
+			bestAmount = selectAmount(dp, orderQuantity, policy, tiebreak)
+		}
+		if bestAmount == -1 {
+			return nil, fmt.Errorf("%w: order %d cannot be filled exactly", ErrInfeasible, orderQuantity)
+		}
+		if capAtMaxQuantity(bestAmount, opts) != bestAmount {
+			return nil, fmt.Errorf("%w: no total between %d and %d can be packed", ErrInfeasible, orderQuantity, opts.MaxQuantity)
+		}
+		// With a single size every larger total adds both waste and packs,
+		// so no weighting can beat bestAmount.
+		if opts.Weights != nil && bestAmount >= orderQuantity && dp != nil {
+			maxSize, err := searchWindow(orderQuantity, sizes, opts)
+			if err != nil {
+				return nil, err
+			}
+			hi := maxSize
+			if policy == PolicyExact {
+				hi = orderQuantity
+			}
+			weighted := weightedAmount(dp, orderQuantity, bestAmount, hi, *opts.Weights, opts.WastePenalty)
+			if capAtMaxQuantity(weighted, opts) != weighted {
+				binding = capBinding(opts)
+				weighted = weightedAmount(dp, orderQuantity, bestAmount, capAtMaxQuantity(hi, opts), *opts.Weights, opts.WastePenalty)
+			}
+			bestAmount = weighted
+		}
+		// needsPackTable builds a table whenever a pack limit is set.
+		if opts.MaxPacks > 0 && dp[bestAmount].packs > opts.MaxPacks {
+			binding = BindingMaxPacks
+			limited := limitPacks(dp, opts.MaxPacks)
+			if bestAmount = selectAmount(limited, orderQuantity, policy, tiebreak); bestAmount == -1 || capAtMaxQuantity(bestAmount, opts) != bestAmount {
+				return nil, fmt.Errorf("%w: no pack mix for order %d uses at most %d packs", ErrInfeasible, orderQuantity, opts.MaxPacks)
+			}
+			if opts.Weights != nil && bestAmount >= orderQuantity {
+				bestAmount = weightedAmount(limited, orderQuantity, bestAmount, capAtMaxQuantity(len(dp)-1, opts), *opts.Weights, opts.WastePenalty)
+			}
+		}
+
+		if dp == nil {
+			counts = map[int]int{sizes[0]: bestAmount / sizes[0]}
+		} else {
+			counts = packCounts(dp, bestAmount)
+		}
+		if opts.SpreadTies {
+			diagnostics = &Diagnostics{TieDiagnostics: &TieDiagnostics{Seed: opts.Seed}}
+			var spread map[int]int
+			if spread, diagnostics.CoOptimal = spreadTieBreakdown(sizes, bestAmount, countPacks(counts), opts.Seed); spread != nil {
+				counts = spread
+			}
+		}
+		limitedAmount := bestAmount
+		if opts.Mode == ModeFewestLines {
+			var err error
+			if counts, err = fewestLinesCounts(solveContext(opts), sizes, bestAmount); err != nil {
+				return nil, err
+			}
+		}
+		var preferences []func(sizes []int, lo, hi int) (int, map[int]int)
+		if opts.Mode == ModeUniform {
+			preferences = append(preferences, uniformBreakdown)
+		}
+		if opts.PenalizeSmallPacks {
+			preferences = append(preferences, penalizedSmallPacksBreakdown)
+		}
+		if opts.PreferEvenCounts {
+			preferences = append(preferences, evenCountsBreakdown)
+		}
+		for _, prefer := range preferences {
+			lo, hi := nearOptimalWindow(orderQuantity, bestAmount, wasteDelta(opts, sizes), policy)
+			amount, preferred, capped := preferCapped(prefer, sizes, lo, hi, opts)
+			if amount != -1 {
+				bestAmount, counts = amount, preferred
+			}
+			if capped {
+				binding = capBinding(opts)
+			}
+		}
+		// Preferences between mixes yield to the pack limit.
+		if opts.MaxPacks > 0 && countPacks(counts) > opts.MaxPacks {
+			bestAmount, counts = limitedAmount, packCounts(dp, limitedAmount)
+		}
+		if opts.MaxSlots > 0 && breakdownSlots(counts, opts.Slots) > opts.MaxSlots {
+			maxSize, err := searchWindow(orderQuantity, sizes, opts)
+			if err != nil {
+				return nil, err
+			}
+			hi := capAtMaxQuantity(maxSize, opts)
+			switch policy {
+			case PolicyExact:
+				hi = orderQuantity
+			case PolicyAllowUnderfill:
+				return nil, fmt.Errorf("policy %q is not supported with maxSlots", PolicyAllowUnderfill)
+			}
+			var fewest int
+			bestAmount, counts, fewest = slottedBreakdown(sizes, orderQuantity, hi, maxSize, opts.Slots, opts.MaxSlots)
+			if err := stopped(ctx); err != nil {
+				return nil, err
+			}
+			if bestAmount == -1 {
+				return nil, overflowsPallet(orderQuantity, fewest, opts.MaxSlots)
+			}
+			binding = BindingMaxSlots
+		}
+		if opts.Budget > 0 && !withinBudget(breakdownCost(counts, opts.Costs), opts.Budget) {
+			maxSize, err := searchWindow(orderQuantity, sizes, opts)
+			if err != nil {
+				return nil, err
+			}
+			hi := capAtMaxQuantity(maxSize, opts)
+			switch policy {
+			case PolicyExact:
+				hi = orderQuantity
+			case PolicyAllowUnderfill:
+				return nil, fmt.Errorf("policy %q is not supported with budget", PolicyAllowUnderfill)
+			}
+			var cheapest float64
+			bestAmount, counts, cheapest = budgetBreakdown(sizes, orderQuantity, hi, maxSize, opts.Costs, opts.Budget)
+			if err := stopped(ctx); err != nil {
+				return nil, err
+			}
+			if bestAmount == -1 {
+				return nil, overBudget(orderQuantity, cheapest, opts.Budget, opts.Currency)
+			}
+			// Of the total's mixes within budget, prefer the usual fewest packs.
+			if dp != nil && withinBudget(breakdownCost(packCounts(dp, bestAmount), opts.Costs), opts.Budget) {
+				counts = packCounts(dp, bestAmount)
+			}
+			binding = BindingBudget
+		}
+		if opts.MaxWastePerSize != nil && !withinWasteCaps(sizes, countsSlice(sizes, counts), max(bestAmount-orderQuantity, 0), opts.MaxWastePerSize) {
+			maxSize, err := searchWindow(orderQuantity, sizes, opts)
+			if err != nil {
+				return nil, err
+			}
+			bestAmount, counts = cappedWasteBreakdown(sizes, orderQuantity, bestAmount, capAtMaxQuantity(maxSize, opts), opts.MaxWastePerSize, opts.MaxPacks)
+			if bestAmount == -1 {
+				return nil, fmt.Errorf("%w: no pack mix for order %d keeps waste within maxWastePerSize", ErrInfeasible, orderQuantity)
+			}
+			binding = BindingMaxWastePerSize
+		}
+	}
 
-	// DP table: dp[i] = {totalPacks, prevAmount, lastUsedPack}
-	type dpEntry struct {
-		packs int
-		prev  int
-		pack  int
+	result := buildResult(orderQuantity, bestAmount, sizes, counts)
+	checkInvariants(result, counts)
+	if opts.Diagnostics {
+		if diagnostics == nil {
+			diagnostics = &Diagnostics{}
+		}
+		diagnostics.WindowDiagnostics = windowDiagnostics(orderQuantity, bestAmount, sizes, opts)
+		if diagnostics.WindowEdge {
+			result.Warnings = append(result.Warnings, windowEdgeWarning(diagnostics.WindowDiagnostics))
+		}
+	}
+	result.Diagnostics = diagnostics
+	if opts.PreferEvenCounts && oddLines(countsSlice(sizes, counts)) > 0 {
+		result.Warnings = append(result.Warnings, oddCountsWarning(wasteDelta(opts, sizes)))
+	}
+	if opts.Mode == ModeUniform {
+		result.Uniformity = planUniformity(sizes, counts)
+	}
+	if opts.Costs != nil {
+		cost := roundCost(breakdownCost(counts, opts.Costs), opts.Currency)
+		result.TotalCost = &cost
+		result.Currency = opts.Currency
+	}
+	if opts.Volumes != nil {
+		volume := breakdownVolume(counts, opts.Volumes)
+		result.TotalVolume = &volume
+	}
+	if opts.Slots != nil {
+		result.TotalSlots = breakdownSlots(counts, opts.Slots)
+	}
+	if opts.Weights != nil {
+		score := opts.Weights.score(result.Waste, result.TotalPacks, opts.WastePenalty)
+		result.Score = &score
+	}
+	if hasConstraints(opts) {
+		result.BindingConstraint = binding
+	}
+	return result, nil
+}
+
+// capAtMaxQuantity limits a candidate total to opts.MaxQuantity when it is set.
+func capAtMaxQuantity(total int, opts OptimizeOptions) int {
+	if opts.MaxQuantity > 0 && total > opts.MaxQuantity {
+		return opts.MaxQuantity
+	}
+	return total
+}
+
+// wasteDelta resolves opts.WasteDelta, defaulting to the smallest of sizes.
+func wasteDelta(opts OptimizeOptions, sizes []int) int {
+	if opts.WasteDelta > 0 {
+		return opts.WasteDelta
+	}
+	return sizes[len(sizes)-1]
+}
+
+// sortedDescending returns a copy of sizes sorted largest first, as solve
+// expects, without duplicates.
+func sortedDescending(sizes []int) []int {
+	sorted := slices.Clone(sizes)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	return slices.Compact(sorted)
+}
+
+// distinctSizes returns sizes, sorted in descending order, without
+// duplicates. Catalogs are validated to hold distinct sizes, but a snapshot
+// that slipped through with a duplicate would list its packs twice and
+// double-count them, so the solver works on a de-duplicated copy and logs
+// the defect. sizes itself is returned when it holds no duplicate.
+func distinctSizes(sizes []int) []int {
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i] == sizes[i-1] {
+			log.Printf("pack sizes %v hold duplicates, solving with each size once", sizes)
+			return slices.Compact(slices.Clone(sizes))
+		}
 	}
+	return sizes
+}
+
+// dpEntry is one cell of the pack DP table: the fewest packs summing exactly
+// to the cell's amount, the amount before the last pack, and that last pack.
+type dpEntry struct {
+	packs int
+	prev  int
+	pack  int
+}
+
+// cancelCheckInterval is how many amounts a table fill covers between
+// checks of its context.
+const cancelCheckInterval = 1 << 16
+
+// buildPackTable fills the DP table for amounts 0..maxSize. Unreachable
+// amounts keep packs == math.MaxInt32.
+func buildPackTable(sizes []int, maxSize int) []dpEntry {
+	dp, _ := fillPackTable(context.Background(), sizes, maxSize)
+	return dp
+}
 
+// fillPackTable is buildPackTable, giving up once ctx is done.
+func fillPackTable(ctx context.Context, sizes []int, maxSize int) ([]dpEntry, error) {
 	dp := make([]dpEntry, maxSize+1)
 	for i := range dp {
 		dp[i].packs = math.MaxInt32
@@ -65,10 +664,15 @@ func OptimizePacks(orderQuantity int) (*OptimizationResult, error) {
 	dp[0] = dpEntry{packs: 0, prev: -1, pack: 0}
 
 	for i := 0; i <= maxSize; i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := stopped(ctx); err != nil {
+				return nil, err
+			}
+		}
 		if dp[i].packs == math.MaxInt32 {
 			continue
 		}
-		for _, pack := range PackSizes {
+		for _, pack := range sizes {
 			if i+pack <= maxSize {
 				if dp[i].packs+1 < dp[i+pack].packs {
 					dp[i+pack] = dpEntry{
@@ -80,47 +684,153 @@ func OptimizePacks(orderQuantity int) (*OptimizationResult, error) {
 			}
 		}
 	}
+	return dp, nil
+}
 
-	// Find minimal totalItems ≥ orderQuantity
-	bestAmount := -1
-	for i := orderQuantity; i <= maxSize; i++ {
-		if dp[i].packs != math.MaxInt32 {
-			bestAmount = i
-			break
-		}
-	}
-
-	if bestAmount == -1 {
-		panic("No valid solution found")
-	}
-
-	// Backtrack to find pack breakdown
+// packCounts backtracks from a reachable amount to the pack breakdown.
+func packCounts(dp []dpEntry, amount int) map[int]int {
 	counts := make(map[int]int)
-	for cur := bestAmount; cur > 0; {
+	for cur := amount; cur > 0; {
 		p := dp[cur].pack
 		counts[p]++
 		cur = dp[cur].prev
 	}
+	return counts
+}
 
-	// Build result
+// countPacks returns the number of packs in a breakdown.
+func countPacks(counts map[int]int) int {
+	packs := 0
+	for _, qty := range counts {
+		packs += qty
+	}
+	return packs
+}
+
+// buildResult assembles the response for a breakdown, listing packs in the
+// order of sizes.
+// Sizes with a zero count are left out, so Packs never holds a zero-quantity
+// line and TotalPacks is the sum of the listed quantities.
+func buildResult(orderQuantity, totalItems int, sizes []int, counts map[int]int) *OptimizationResult {
 	packResults := []PackResult{}
 	totalPacks := 0
-	for _, size := range PackSizes {
-		if qty, ok := counts[size]; ok {
+	for _, size := range sizes {
+		if qty := counts[size]; qty > 0 {
 			packResults = append(packResults, PackResult{PackSize: size, Quantity: qty})
 			totalPacks += qty
 		}
 	}
 
-	return &OptimizationResult{
+	result := &OptimizationResult{
 		OrderQuantity: orderQuantity,
-		TotalItems:    bestAmount,
+		TotalItems:    totalItems,
 		TotalPacks:    totalPacks,
 		Packs:         packResults,
-		Waste:         bestAmount - orderQuantity,
-	}, nil
+		Waste:         totalItems - orderQuantity,
+	}
+	if totalItems < orderQuantity {
+		result.Waste = 0
+		result.Shortfall = orderQuantity - totalItems
+	}
+	return result
 }
 
+// decodeJSONBody decodes exactly one JSON object from the request body into v,
+// rejecting unknown fields and any data after the object.
+func decodeJSONBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var fieldErr *fieldError
+		if errors.As(err, &fieldErr) {
+			return fieldErr
+		}
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return fmt.Errorf("Invalid JSON: %s", strings.TrimPrefix(err.Error(), "json: "))
+		}
+		return fmt.Errorf("Invalid JSON")
+	}
+	if dec.More() {
+		return fmt.Errorf("Invalid JSON: unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// optimizeRequest is the JSON body accepted by POST /optimize.
+type optimizeRequest struct {
+	Quantity quantityValue `json:"quantity,omitempty"`
+	Mode     string        `json:"mode,omitempty"`
+	Costs    costTable     `json:"costs,omitempty"`
+	Policy   string        `json:"policy,omitempty"`
+
+	Volumes map[int]float64 `json:"volumes,omitempty"`
+
+	UnderfillTiebreak string `json:"underfillTiebreak,omitempty"`
+
+	PenalizeSmallPacks bool `json:"penalizeSmallPacks,omitempty"`
+	PreferEvenCounts   bool `json:"preferEvenCounts,omitempty"`
+	WasteDelta         int  `json:"wasteDelta,omitempty"`
+	SearchMargin       int  `json:"searchMargin,omitempty"`
+
+	MinQuantity int `json:"minQuantity,omitempty"`
+	MaxQuantity int `json:"maxQuantity,omitempty"`
+
+	AlreadyShipped []PackResult `json:"alreadyShipped,omitempty"`
+
+	Catalog    string  `json:"catalog,omitempty"`
+	ScaleSizes float64 `json:"scaleSizes,omitempty"`
+	Exclude    []int   `json:"exclude,omitempty"`
+
+	MaxWastePerSize map[int]int   `json:"maxWastePerSize,omitempty"`
+	MaxPacks        int           `json:"maxPacks,omitempty"`
+	MaxWaste        int           `json:"maxWaste,omitempty"`
+	Slots           map[int]int   `json:"slots,omitempty"`
+	MaxSlots        int           `json:"maxSlots,omitempty"`
+	Budget          float64       `json:"budget,omitempty"`
+	Weights         *ScoreWeights `json:"weights,omitempty"`
+	WastePenalty    wastePenalty  `json:"wastePenalty,omitempty"`
+
+	MasterCartonSize int    `json:"masterCartonSize,omitempty"`
+	Assignment       string `json:"assignment,omitempty"`
+
+	SpreadTies bool   `json:"spreadTies,omitempty"`
+	Seed       *int64 `json:"seed,omitempty"`
+
+	IncludeUnused  bool `json:"includeUnused,omitempty"`
+	CompareNaive   bool `json:"compareNaive,omitempty"`
+	SuggestUpsell  bool `json:"suggestUpsell,omitempty"`
+	EchoRequest    bool `json:"echoRequest,omitempty"`
+	BothObjectives bool `json:"bothObjectives,omitempty"`
+
+	Tolerances  []float64 `json:"tolerances,omitempty"`
+	TopK        int       `json:"topK,omitempty"`
+	UpgradePath int       `json:"upgradePath,omitempty"`
+
+	TreatZeroAsEmpty *bool `json:"treatZeroAsEmpty,omitempty"`
+	MinOrderQuantity *int  `json:"minOrderQuantity,omitempty"`
+
+	StrictSmallOrders bool `json:"strictSmallOrders,omitempty"`
+	Diagnostics       bool `json:"diagnostics,omitempty"`
+
+	OrderRef string `json:"orderRef,omitempty"`
+}
+
+// catalogName is the name of the catalog the request optimizes against.
+func (r optimizeRequest) catalogName() string {
+	if r.Catalog == "" {
+		return defaultCatalogName
+	}
+	return r.Catalog
+}
+
+// optimizeResponse lists the representations of an optimize result. The
+// manifest and the compact plan code can also be picked with ?format=.
+var optimizeResponse = produces(jsonContentType, protobufContentType, htmlContentType, manifestContentType, compactContentType).withFormats(map[string]string{
+	"json":     jsonContentType,
+	"manifest": manifestContentType,
+	"compact":  compactContentType,
+})
+
 // HTTP handler for pack optimization
 func optimizeHandler(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w, r)
@@ -129,32 +839,259 @@ func optimizeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	mediaType, ok := optimizeResponse.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	var request optimizeRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if tenant := r.PathValue("tenant"); tenant != "" {
+		if request.Catalog != "" && request.Catalog != tenant {
+			writeError(w, "catalog cannot be combined with a tenant path", http.StatusBadRequest)
+			return
+		}
+		request.Catalog = tenant
+	}
 
-	var request struct {
-		Quantity int `json:"quantity"`
+	quantity := int(request.Quantity)
+	if request.MinQuantity != 0 || request.MaxQuantity != 0 {
+		if quantity != 0 {
+			writeError(w, "quantity cannot be combined with minQuantity and maxQuantity", http.StatusBadRequest)
+			return
+		}
+		if request.MinQuantity <= 0 || request.MaxQuantity < request.MinQuantity {
+			writeError(w, "minQuantity must be positive and maxQuantity at least minQuantity", http.StatusBadRequest)
+			return
+		}
+		quantity = request.MinQuantity
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	allowZero := zeroAsEmpty(request.TreatZeroAsEmpty)
+	if quantity < 0 || quantity == 0 && !allowZero {
+		writeError(w, "Quantity must be positive", http.StatusBadRequest)
+		return
+	}
+	shipped, err := shippedItems(request.AlreadyShipped)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if shipped > 0 && request.MaxQuantity != 0 {
+		writeError(w, "alreadyShipped cannot be combined with minQuantity and maxQuantity", http.StatusBadRequest)
+		return
+	}
+	// Only what is left of the order is optimized, and a shipment that
+	// covers it leaves an empty plan.
+	orderQuantity := quantity
+	quantity = max(quantity-shipped, 0)
+	if request.WasteDelta < 0 {
+		writeError(w, "wasteDelta must not be negative", http.StatusBadRequest)
 		return
 	}
+	if request.SearchMargin < 0 {
+		writeError(w, "searchMargin must not be negative", http.StatusBadRequest)
+		return
+	}
+	if err := validateTolerances(request.Tolerances); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateTopK(request.TopK); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateUpgradePath(request.UpgradePath); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	costs, currency, err := request.Costs.split()
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minOrder, err := resolveMinOrder(request.MinOrderQuantity)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := OptimizeOptions{
+		Mode:     request.Mode,
+		Costs:    costs,
+		Currency: currency,
+		Policy:   request.Policy,
+		Volumes:  request.Volumes,
+
+		UnderfillTiebreak:  request.UnderfillTiebreak,
+		PenalizeSmallPacks: request.PenalizeSmallPacks,
+		PreferEvenCounts:   request.PreferEvenCounts,
+		WasteDelta:         request.WasteDelta,
+		SearchMargin:       request.SearchMargin,
+		MaxQuantity:        request.MaxQuantity,
+		MaxWastePerSize:    request.MaxWastePerSize,
+		MaxPacks:           request.MaxPacks,
+		MaxWaste:           request.MaxWaste,
+		Slots:              request.Slots,
+		MaxSlots:           request.MaxSlots,
+		Budget:             request.Budget,
+		Context:            r.Context(),
+		Weights:            request.Weights,
+		WastePenalty:       float64(request.WastePenalty),
+		TreatZeroAsEmpty:   allowZero || shipped > 0,
+		MinOrderQuantity:   minOrder,
+		StrictSmallOrders:  request.StrictSmallOrders,
+		Diagnostics:        request.Diagnostics,
+	}
+	if request.SpreadTies {
+		opts.SpreadTies = true
+		opts.Seed = effectiveSeed(request.Seed)
+	}
 
-	if request.Quantity <= 0 {
-		http.Error(w, "Quantity must be positive", http.StatusBadRequest)
+	catalog, ok := namedCatalogs.lookup(request.catalogName())
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", request.Catalog), http.StatusNotFound)
 		return
 	}
+	var permalink string
+	if r.URL.Query().Get("permalink") == "true" {
+		// The link pins the seed spreadTies picked, so it reproduces this
+		// very result.
+		linked := request
+		if request.SpreadTies {
+			linked.Seed = &opts.Seed
+		}
+		permalink = permalinkPath + encodePermalink(linked, catalog)
+	}
+	if request.ScaleSizes != 0 {
+		if catalog, err = scaleCatalog(catalog, request.ScaleSizes); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if len(request.Exclude) > 0 {
+		if catalog, err = excludeSizes(catalog, request.Exclude); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrInfeasible) {
+				status = http.StatusUnprocessableEntity
+			}
+			writeError(w, err.Error(), status)
+			return
+		}
+		opts = excludeOptions(opts, request.Exclude)
+	}
+	opts = catalog.Defaults.apply(opts)
+	optimize := func(orderQuantity int) (*OptimizationResult, error) {
+		return OptimizeCatalog(orderQuantity, catalog, opts)
+	}
 
-	result, err := OptimizePacks(request.Quantity)
+	result, err := optimize(quantity)
+	// An empty plan is the answer itself; business rules such as a minimum
+	// order apply to orders, not to the lines treatZeroAsEmpty lets through.
+	if err == nil && quantity > 0 {
+		result, err = postProcess(result, resultPostProcessors, optimize)
+	}
+	if errors.Is(err, ErrInfeasible) {
+		writeError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if shipped > 0 {
+		noteShipment(result, orderQuantity, shipped)
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	if r.URL.Query().Get("tree") == "true" {
+		tree, err := buildPackingTree(result, request.MasterCartonSize, request.Assignment)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result.Tree = tree
+	}
+	if r.URL.Query().Get("teach") == "true" && quantity > 0 {
+		result.Lesson = buildLesson(result, catalog)
+	}
+	result.Permalink = permalink
+	if r.URL.Query().Get("ranges") == "true" {
+		if result.Ranges, err = itemRanges(result); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if r.URL.Query().Get("includeTime") == "true" {
+		result.ServerTime = serverTime()
+	}
+	if request.IncludeUnused || request.CompareNaive || request.SuggestUpsell || request.EchoRequest || request.BothObjectives || len(request.Tolerances) > 0 || request.TopK > 0 || request.UpgradePath > 0 {
+		if request.EchoRequest {
+			result.Request = echoRequest(quantity, request.catalogName(), catalog, opts)
+			result.Request.ScaleSizes = request.ScaleSizes
+		}
+		if request.CompareNaive {
+			result.Savings = naiveSavings(result, catalog, costs)
+		}
+		if request.SuggestUpsell {
+			if result.Upsell, err = suggestUpsell(solveContext(opts), result, catalog); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if len(request.Tolerances) > 0 && quantity > 0 {
+			if result.Tolerances, err = toleranceLadder(quantity, catalog, opts, request.Tolerances); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if request.TopK > 0 && quantity > 0 {
+			if result.Alternatives, result.AlternativesTruncated, err = topAlternatives(quantity, result, catalog, opts, request.TopK); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if request.UpgradePath > 0 && quantity > 0 {
+			if result.UpgradePath, err = upgradePath(result, catalog, opts, request.UpgradePath); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if request.BothObjectives && quantity > 0 {
+			if result.Objectives, err = bothObjectives(quantity, catalog, opts); err != nil {
+				writeError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if request.IncludeUnused {
+			includeUnusedSizes(result, catalog)
+		}
+	}
+
+	optimizeHistory.add(request, result)
+
+	switch mediaType {
+	case compactContentType:
+		w.Header().Set("Content-Type", compactContentType+"; charset=utf-8")
+		fmt.Fprintln(w, encodeCompactPlan(result, request.OrderRef))
+	case manifestContentType:
+		w.Header().Set("Content-Type", manifestContentType)
+		json.NewEncoder(w).Encode(buildManifest(result, request.OrderRef))
+	case protobufContentType:
+		w.Header().Set("Content-Type", protobufContentType)
+		w.Write(marshalResultProto(result))
+	case htmlContentType:
+		w.Header().Set("Content-Type", htmlContentType+"; charset=utf-8")
+		writeResultHTML(w, result)
+	default:
+		w.Header().Set("Content-Type", jsonContentType)
+		json.NewEncoder(w).Encode(result)
+	}
 }
 
 // Health check endpoint
@@ -165,52 +1102,258 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
+		Status   string                   `json:"status"`
+		Message  string                   `json:"message"`
+		Catalogs map[string]catalogHealth `json:"catalogs"`
 	}{
-		Status:  "healthy",
-		Message: "Pack Optimizer API is running",
+		Status:   "healthy",
+		Message:  "Pack Optimizer API is running",
+		Catalogs: map[string]catalogHealth{},
+	}
+
+	status := http.StatusOK
+	for name, catalog := range namedCatalogs.all() {
+		health := catalogHealth{Healthy: true}
+		if err := validateCatalog(catalog); err != nil {
+			health = catalogHealth{Error: err.Error()}
+			response.Status = "degraded"
+			response.Message = "One or more catalogs cannot be used to optimize"
+			status = http.StatusServiceUnavailable
+		}
+		response.Catalogs[name] = health
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(response)
 }
 
+// decodePackSizes decodes the raw packSizes field of a POST /packages body.
+// Each element is either a plain size or a {"size", "label"} object; labels
+// are returned keyed by size. A missing field, an explicit null and an empty
+// array are all rejected with distinct messages so a malformed request can
+// never clear the catalog.
+func decodePackSizes(raw json.RawMessage) ([]int, map[int]string, error) {
+	if raw == nil {
+		return nil, nil, fmt.Errorf("packSizes is required")
+	}
+	if string(raw) == "null" {
+		return nil, nil, fmt.Errorf("packSizes must not be null")
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, nil, fmt.Errorf("packSizes must be an array of integers")
+	}
+	if len(elements) == 0 {
+		return nil, nil, fmt.Errorf("packSizes must contain at least one pack size")
+	}
+
+	packSizes := make([]int, 0, len(elements))
+	labels := make(map[int]string)
+	for i, element := range elements {
+		var entry struct {
+			Size  json.RawMessage `json:"size"`
+			Label string          `json:"label"`
+		}
+		if _, ok := jsonNumber(element); ok {
+			entry.Size = element
+		} else {
+			dec := json.NewDecoder(bytes.NewReader(element))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&entry); err != nil || entry.Size == nil {
+				return nil, nil, fmt.Errorf("packSizes entries must be integers or {\"size\", \"label\"} objects")
+			}
+		}
+
+		size, err := parsePackSize(i, entry.Size)
+		if err != nil {
+			return nil, nil, err
+		}
+		packSizes = append(packSizes, size)
+		if entry.Label != "" {
+			labels[size] = entry.Label
+		}
+	}
+	return packSizes, labels, nil
+}
+
+// jsonNumber returns raw as a json.Number if it is a JSON number literal.
+func jsonNumber(raw json.RawMessage) (json.Number, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", false
+	}
+	number, ok := value.(json.Number)
+	return number, ok
+}
+
+// parsePackSize reads the pack size at index i of packSizes, rejecting
+// fractions, exponents and values that do not fit in an int instead of
+// letting them truncate.
+func parsePackSize(i int, raw json.RawMessage) (int, error) {
+	number, ok := jsonNumber(raw)
+	if !ok {
+		return 0, fmt.Errorf("packSizes[%d] must be an integer, got %s", i, raw)
+	}
+	size, err := strconv.ParseInt(number.String(), 10, 0)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("packSizes[%d] is out of range: %s", i, number)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("packSizes[%d] must be an integer, got %s", i, number)
+	}
+	return int(size), nil
+}
+
+// validatePackSizes checks that a catalog holds only positive, unique sizes.
+func validatePackSizes(packSizes []int) error {
+	for _, size := range packSizes {
+		if size <= 0 {
+			return fmt.Errorf("All pack sizes must be positive integers")
+		}
+	}
+
+	uniquePackSizes := make(map[int]struct{})
+	// Check for uniqueness
+	for _, size := range packSizes {
+		if _, exists := uniquePackSizes[size]; exists {
+			return fmt.Errorf("All package sizes must be unique")
+		}
+		uniquePackSizes[size] = struct{}{}
+	}
+	return nil
+}
+
+// applyLabels copies catalog labels onto the packs of a result.
+func applyLabels(result *OptimizationResult, labels map[int]string) {
+	for i := range result.Packs {
+		result.Packs[i].Label = labels[result.Packs[i].PackSize]
+	}
+}
+
+// includeUnusedSizes lists every size of catalog in result.Packs, adding a
+// zero-quantity line for each size the result does not use. Lines stay in
+// descending size order and TotalPacks is unchanged.
+func includeUnusedSizes(result *OptimizationResult, catalog Catalog) {
+	used := make(map[int]PackResult, len(result.Packs))
+	for _, pack := range result.Packs {
+		used[pack.PackSize] = pack
+	}
+	for _, size := range catalog.PackSizes {
+		if _, ok := used[size]; !ok {
+			used[size] = PackResult{PackSize: size, Label: catalog.Labels[size]}
+		}
+	}
+
+	packs := make([]PackResult, 0, len(used))
+	for _, pack := range used {
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].PackSize > packs[j].PackSize })
+	result.Packs = packs
+}
+
+// HTTP handler for the pack sizes configuration. Under /t/{tenant}/ it
+// manages the tenant's catalog instead of the default one.
 func packageHandler(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w, r)
+	name := r.PathValue("tenant")
+	if name == "" {
+		name = defaultCatalogName
+	}
+
 	if r.Method == http.MethodPost {
 		var request struct {
-			PackSizes []int `json:"packSizes"`
+			PackSizes json.RawMessage `json:"packSizes"`
+			PackSeries
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		if err := decodeJSONBody(r, &request); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		for _, size := range request.PackSizes {
-			if size <= 0 {
-				http.Error(w, "All pack sizes must be positive integers", http.StatusBadRequest)
+		// A geometric catalog may be given as base, ratio and count instead
+		// of listing its sizes.
+		var packSizes []int
+		labels := map[int]string{}
+		var err error
+		if request.PackSeries != (PackSeries{}) {
+			if request.PackSizes != nil {
+				writeError(w, "Give either packSizes or base, ratio and count, not both", http.StatusBadRequest)
 				return
 			}
+			packSizes, err = request.PackSeries.expand()
+		} else {
+			packSizes, labels, err = decodePackSizes(request.PackSizes)
+		}
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		uniquePackSizes := make(map[int]struct{})
-		// Check for uniqueness
-		for _, size := range request.PackSizes {
-			if _, exists := uniquePackSizes[size]; exists {
-				http.Error(w, "All package sizes must be unique", http.StatusBadRequest)
-				return
-			}
-			uniquePackSizes[size] = struct{}{}
+		// The memory ceiling is checked before the warnings, whose tables
+		// span the largest size.
+		catalog := Catalog{PackSizes: packSizes, Labels: labels}
+		if err := validateCatalog(catalog); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := namedCatalogs.put(name, catalog); err != nil {
+			writeError(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		response := struct {
+			Message  string   `json:"message"`
+			Warnings []string `json:"warnings,omitempty"`
+		}{
+			Message:  "Pack sizes updated successfully",
+			Warnings: catalogWarnings(packSizes),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		var request struct {
+			Add    []int `json:"add"`
+			Remove []int `json:"remove"`
 		}
 
-		PackSizes = request.PackSizes
+		if err := decodeJSONBody(r, &request); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		catalog, ok, err := namedCatalogs.update(name, func(current Catalog) (Catalog, error) {
+			return patchCatalog(current, request.Add, request.Remove)
+		})
+		if !ok {
+			writeError(w, fmt.Sprintf("Unknown catalog %q", name), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
 		response := struct {
-			Message string `json:"message"`
+			PackSizes []int          `json:"packSizes"`
+			Labels    map[int]string `json:"labels,omitempty"`
+			Message   string         `json:"message"`
+			Warnings  []string       `json:"warnings,omitempty"`
 		}{
-			Message: "Pack sizes updated successfully",
+			PackSizes: catalog.PackSizes,
+			Labels:    catalog.Labels,
+			Message:   "Pack sizes updated successfully",
+			Warnings:  catalogWarnings(catalog.PackSizes),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -220,11 +1363,18 @@ func packageHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Handle GET to retrieve current pack sizes
 	if r.Method == http.MethodGet {
+		catalog, ok := namedCatalogs.lookup(name)
+		if !ok {
+			writeError(w, fmt.Sprintf("Unknown catalog %q", name), http.StatusNotFound)
+			return
+		}
 		response := struct {
-			PackSizes []int  `json:"packSizes"`
-			Message   string `json:"message"`
+			PackSizes []int          `json:"packSizes"`
+			Labels    map[int]string `json:"labels,omitempty"`
+			Message   string         `json:"message"`
 		}{
-			PackSizes: PackSizes,
+			PackSizes: catalog.PackSizes,
+			Labels:    catalog.Labels,
 			Message:   "Current pack sizes configuration",
 		}
 
@@ -233,14 +1383,71 @@ func packageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
 
+// newRouter registers every endpoint. Endpoints answering only JSON refuse
+// clients that do not accept it with 406; unknown paths get a JSON 404.
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/optimize", jsonBody.wrap(optimizeDedupe.wrap(optimizeLimiter.wrap(optimizeHandler))))
+	mux.HandleFunc(permalinkPath+"{code}", optimizeLimiter.wrap(permalinkHandler))
+	mux.HandleFunc("/optimize/distributed", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(distributedHandler))))
+	mux.HandleFunc("/optimize/batch", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(batchHandler))))
+	mux.HandleFunc("/optimize/infeasible-range", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(infeasibleRangeHandler))))
+	mux.HandleFunc("/optimize/percentiles", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(percentilesHandler))))
+	mux.HandleFunc("/optimize/merge", jsonResponse.wrap(jsonBody.wrap(mergeHandler)))
+	mux.HandleFunc("/optimize/upload", formBody.wrap(optimizeLimiter.wrap(uploadHandler)))
+	mux.HandleFunc("/optimize/min-waste", jsonResponse.wrap(optimizeLimiter.wrap(minWasteHandler)))
+	mux.HandleFunc("/breakdown", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(breakdownHandler))))
+	mux.HandleFunc("/breakdown/decode", jsonResponse.wrap(jsonBody.wrap(decodePlanHandler)))
+	mux.HandleFunc("/health", jsonResponse.wrap(healthHandler))
+	mux.HandleFunc("/packages", jsonResponse.wrap(catalogLock.wrap(jsonBody.wrap(packageHandler))))
+	mux.HandleFunc("/packages/zero-waste", jsonResponse.wrap(jsonBody.wrap(optimizeLimiter.wrap(zeroWasteHandler))))
+	mux.HandleFunc("/packages/impact", jsonResponse.wrap(jsonBody.wrap(impactHandler)))
+	mux.HandleFunc("/packages/recommend", jsonResponse.wrap(jsonBody.wrap(recommendHandler)))
+	mux.HandleFunc("/packages/import", jsonResponse.wrap(catalogLock.wrap(jsonBody.wrap(importHandler))))
+	mux.HandleFunc("/packages/reset", jsonResponse.wrap(catalogLock.wrap(resetHandler)))
+	mux.HandleFunc("/packages/coverage", jsonResponse.wrap(optimizeLimiter.wrap(coverageHandler)))
+	mux.HandleFunc("/packages/worst-waste", jsonResponse.wrap(optimizeLimiter.wrap(worstWasteHandler)))
+	mux.HandleFunc("/packages/stats", jsonResponse.wrap(statsHandler))
+	mux.HandleFunc("/packages/{name}", jsonResponse.wrap(catalogLock.wrap(jsonBody.wrap(namedPackageHandler))))
+	mux.HandleFunc("/t/{tenant}/optimize", jsonBody.wrap(optimizeDedupe.wrap(optimizeLimiter.wrap(optimizeHandler))))
+	mux.HandleFunc("/t/{tenant}/packages", jsonResponse.wrap(catalogLock.wrap(jsonBody.wrap(packageHandler))))
+	mux.HandleFunc("/capabilities", jsonResponse.wrap(capabilitiesHandler))
+	mux.HandleFunc("/history", jsonResponse.wrap(historyHandler))
+	mux.HandleFunc("/debug/vars", jsonResponse.wrap(expvar.Handler().ServeHTTP))
+	mux.HandleFunc("/", notFoundHandler)
+	return mux
+}
 
 func main() {
-	http.HandleFunc("/optimize", optimizeHandler)
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/packages", packageHandler)
+	if !validPolicy(fulfillmentPolicy) {
+		log.Fatalf("invalid FULFILLMENT_POLICY %q, want one of %v", fulfillmentPolicy, supportedPolicies)
+	}
+	if !validTiebreak(underfillTiebreak) {
+		log.Fatalf("invalid UNDERFILL_TIEBREAK %q, want one of %v", underfillTiebreak, supportedTiebreaks)
+	}
+	if minOrderQuantity < 0 {
+		log.Fatalf("invalid MIN_ORDER_QUANTITY %d, want a non-negative integer", minOrderQuantity)
+	}
+	if maxSearchStates <= 0 {
+		log.Fatalf("invalid MAX_SEARCH_STATES %d, want a positive integer", maxSearchStates)
+	}
+	if percentPrecision < 0 {
+		log.Fatalf("invalid PERCENT_PRECISION %d, want a non-negative integer", percentPrecision)
+	}
+	if costPrecision < 0 || costPrecision > maxCostPrecision {
+		log.Fatalf("invalid COST_PRECISION %d, want an integer from 0 to %d", costPrecision, maxCostPrecision)
+	}
+	if err := httpTuning.validate(); err != nil {
+		log.Fatal(err)
+	}
+	processors, err := parsePostProcessors(os.Getenv("POST_PROCESSORS"))
+	if err != nil {
+		log.Fatalf("invalid POST_PROCESSORS: %v", err)
+	}
+	resultPostProcessors = processors
 
 	port := "8080"
 	if p := os.Getenv("PORT"); p != "" {
@@ -250,12 +1457,40 @@ func main() {
 	fmt.Printf("🚀 Pack Optimizer API server starting on port %s\n", port)
 	fmt.Println("📋 Available endpoints:")
 	fmt.Println("  POST /optimize - Optimize pack combinations")
+	fmt.Println("  POST /optimize/distributed - Split an order across fulfillment centers")
+	fmt.Println("  POST /optimize/batch - Optimize many quantities from one shared table")
+	fmt.Println("  POST /optimize/infeasible-range - Order quantities in a range no mix satisfies under constraints")
+	fmt.Println("  GET /optimize/permalink/{code} - Recompute the optimize result a permalink encodes")
+	fmt.Println("  POST /optimize/percentiles - Optimize the order sizes at percentiles of a dataset")
+	fmt.Println("  POST /optimize/merge - Merge separately computed plans into one")
+	fmt.Println("  POST /optimize/upload - Optimize a CSV of quantities uploaded as a form file")
+	fmt.Println("  GET /optimize/min-waste?quantity=N - Minimum possible waste for an order")
+	fmt.Println("  POST /breakdown - Fewest packs summing to exactly totalItems")
+	fmt.Println("  POST /breakdown/decode - Decode a compact plan code")
 	fmt.Println("  GET /packages - Get pack sizes configuration")
 	fmt.Println("  POST /packages - Update pack sizes configuration")
+	fmt.Println("  PATCH /packages - Add or remove individual pack sizes")
+	fmt.Println("  POST /packages/zero-waste - Find a minimal zero-waste catalog")
+	fmt.Println("  POST /packages/impact - Waste added by retiring each pack size")
+	fmt.Println("  POST /packages/recommend - Recommend k pack sizes for historical orders")
+	fmt.Println("  POST /packages/import - Load a catalog from a file in CATALOG_IMPORT_DIR")
+	fmt.Println("  POST /packages/reset - Restore the default pack sizes")
+	fmt.Println("  GET /packages/coverage - Fraction of quantities up to max filled exactly")
+	fmt.Println("  GET /packages/worst-waste - Quantity up to max filled with the most waste")
+	fmt.Println("  GET /packages/stats - Count, min, max, GCD and nesting of the catalog's sizes")
+	fmt.Println("  GET /packages/{name} - Get a named catalog")
+	fmt.Println("  PUT /packages/{name} - Store a named catalog")
+	fmt.Println("  DELETE /packages/{name} - Delete a named catalog")
+	fmt.Println("  POST /t/{tenant}/optimize - Optimize against a tenant's catalog")
+	fmt.Println("  GET /t/{tenant}/packages - Get a tenant's catalog")
+	fmt.Println("  POST /t/{tenant}/packages - Update a tenant's catalog")
 	fmt.Println("  GET /health - Health check")
-
+	fmt.Println("  GET /capabilities - Supported modes, options and formats")
+	fmt.Println("  GET /history - Recent optimize requests and results")
+	fmt.Println("  GET /debug/vars - Runtime metrics, including largeTables")
 
 	fmt.Printf("🌐 Server URL: http://localhost:%s\n", port)
 
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	server := newServer(":"+port, accessLog.wrap(withDeadline(requestTimeout, withSignature(signingSecret, newRouter()))), httpTuning)
+	log.Fatal(server.ListenAndServe())
 }
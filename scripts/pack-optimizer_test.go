@@ -1,10 +1,42 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 )
 
+// withPackSizes swaps in an unlabeled pack size configuration for the
+// duration of a test.
+func withPackSizes(t *testing.T, sizes []int) {
+	t.Helper()
+	savedSizes, savedLabels := PackSizes, PackLabels
+	PackSizes, PackLabels = sizes, map[int]string{}
+	t.Cleanup(func() { PackSizes, PackLabels = savedSizes, savedLabels })
+}
+
+// postOptimize sends body to POST /optimize and returns the recorder.
+func postOptimize(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	return rec
+}
+
+// postPackages sends body to POST /packages and returns the recorder.
+func postPackages(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/packages", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	packageHandler(rec, req)
+	return rec
+}
+
 func TestOptimizePacks(t *testing.T) {
 	testCases := []struct {
 		input         int
@@ -52,6 +84,50 @@ func TestOptimizePacks(t *testing.T) {
 	}
 }
 
+// assertCompactPacks fails if result lists a zero-quantity pack line or its
+// TotalPacks differs from the sum of the listed quantities.
+func assertCompactPacks(t *testing.T, result *OptimizationResult) {
+	t.Helper()
+	listed := 0
+	for _, pack := range result.Packs {
+		if pack.Quantity == 0 {
+			t.Errorf("order %d lists a zero-quantity line for size %d: %+v", result.OrderQuantity, pack.PackSize, result.Packs)
+		}
+		listed += pack.Quantity
+	}
+	if listed != result.TotalPacks {
+		t.Errorf("order %d TotalPacks = %d, want the listed sum %d", result.OrderQuantity, result.TotalPacks, listed)
+	}
+}
+
+func TestBuildResultOmitsZeroQuantities(t *testing.T) {
+	sizes := []int{5000, 2000, 1000, 500, 250}
+	result := buildResult(750, 750, sizes, map[int]int{5000: 0, 2000: 0, 500: 1, 250: 1})
+	assertCompactPacks(t, result)
+	if len(result.Packs) != 2 || result.TotalPacks != 2 {
+		t.Errorf("buildResult kept %+v (%d packs), want 500 and 250 only", result.Packs, result.TotalPacks)
+	}
+}
+
+func TestOptimizePacksNeverListsZeroQuantities(t *testing.T) {
+	for _, mode := range supportedModes {
+		opts := OptimizeOptions{Mode: mode}
+		if mode == ModeCheapest {
+			opts.Costs = map[int]float64{250: 1, 500: 1.8, 1000: 3.5, 2000: 6, 5000: 14}
+		}
+		if mode == ModeMinVolume {
+			opts.Volumes = map[int]float64{250: 1, 500: 2.1, 1000: 3.9, 2000: 8, 5000: 19}
+		}
+		for _, quantity := range []int{1, 250, 251, 501, 999, 4999, 5001, 12001, 23456} {
+			result, err := OptimizePacksWithOptions(quantity, opts)
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(%d, %s) returned error: %v", quantity, mode, err)
+			}
+			assertCompactPacks(t, result)
+		}
+	}
+}
+
 func TestOptimizePacksInvalidInput(t *testing.T) {
 	testCases := []int{0, -1, -100}
 
@@ -68,6 +144,173 @@ func TestOptimizePacksInvalidInput(t *testing.T) {
 	}
 }
 
+func TestPackageHandlerRejectsMissingPackSizes(t *testing.T) {
+	testCases := []struct {
+		body        string
+		wantMessage string
+		description string
+	}{
+		{`{}`, "packSizes is required", "Missing field"},
+		{`{"packSizes": null}`, "packSizes must not be null", "Null field"},
+		{`{"packSizes": []}`, "packSizes must contain at least one pack size", "Empty array"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500})
+
+			req := httptest.NewRequest(http.MethodPost, "/packages", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			packageHandler(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("POST /packages %s status = %d, want %d", tc.body, rec.Code, http.StatusBadRequest)
+			}
+			if got := errorMessage(t, rec); got != tc.wantMessage {
+				t.Errorf("POST /packages %s message = %q, want %q", tc.body, got, tc.wantMessage)
+			}
+			if len(PackSizes) != 2 {
+				t.Errorf("POST /packages %s changed pack sizes to %v", tc.body, PackSizes)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerRejectsMalformedBodies(t *testing.T) {
+	testCases := []struct {
+		body        string
+		wantMessage string
+		description string
+	}{
+		{`{"qty": 1}`, `Invalid JSON: unknown field "qty"`, "Unknown field"},
+		{`{"quantity": 1}{"quantity": 2}`, "Invalid JSON: unexpected data after the JSON object", "Second object"},
+		{`{"quantity": 1} []`, "Invalid JSON: unexpected data after the JSON object", "Trailing array"},
+		{`{"quantity": `, "Invalid JSON", "Truncated object"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			optimizeHandler(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("POST /optimize %s status = %d, want %d", tc.body, rec.Code, http.StatusBadRequest)
+			}
+			if got := errorMessage(t, rec); got != tc.wantMessage {
+				t.Errorf("POST /optimize %s message = %q, want %q", tc.body, got, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerAllowsTrailingWhitespace(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader("{\"quantity\": 1}\n"))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("POST /optimize with trailing newline status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestPackageHandlerRejectsUnknownFields(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	req := httptest.NewRequest(http.MethodPost, "/packages", strings.NewReader(`{"sizes": [100]}`))
+	rec := httptest.NewRecorder()
+	packageHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /packages with unknown field status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPackageHandlerLabels(t *testing.T) {
+	testCases := []struct {
+		body        string
+		wantSizes   []int
+		wantLabels  map[int]string
+		description string
+	}{
+		{
+			`{"packSizes": [{"size": 250, "label": "Small"}, {"size": 1000, "label": "Bulk"}]}`,
+			[]int{250, 1000},
+			map[int]string{250: "Small", 1000: "Bulk"},
+			"Labeled entries",
+		},
+		{`{"packSizes": [250, 1000]}`, []int{250, 1000}, map[int]string{}, "Legacy integers"},
+		{`{"packSizes": [250, {"size": 1000, "label": "Bulk"}]}`, []int{250, 1000}, map[int]string{1000: "Bulk"}, "Mixed entries"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{500})
+
+			if rec := postPackages(t, tc.body); rec.Code != http.StatusOK {
+				t.Fatalf("POST /packages %s status = %d, body %q", tc.body, rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(PackSizes, tc.wantSizes) {
+				t.Errorf("PackSizes = %v, want %v", PackSizes, tc.wantSizes)
+			}
+			if !reflect.DeepEqual(PackLabels, tc.wantLabels) {
+				t.Errorf("PackLabels = %v, want %v", PackLabels, tc.wantLabels)
+			}
+
+			result, err := OptimizePacks(1250)
+			if err != nil {
+				t.Fatalf("OptimizePacks(1250) returned error: %v", err)
+			}
+			for _, pack := range result.Packs {
+				if pack.Label != tc.wantLabels[pack.PackSize] {
+					t.Errorf("pack %d label = %q, want %q", pack.PackSize, pack.Label, tc.wantLabels[pack.PackSize])
+				}
+			}
+		})
+	}
+}
+
+func TestPackageHandlerRejectsMalformedEntries(t *testing.T) {
+	withPackSizes(t, []int{500})
+
+	for _, body := range []string{`{"packSizes": ["250"]}`, `{"packSizes": [{"size": 250, "color": "red"}]}`, `{"packSizes": [true]}`} {
+		if rec := postPackages(t, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /packages %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestPackageHandlerRejectsNonIntegerSizes(t *testing.T) {
+	withPackSizes(t, []int{500})
+
+	testCases := []struct {
+		description string
+		body        string
+		wantMessage string
+	}{
+		{"fraction", `{"packSizes": [250.5, 500]}`, "packSizes[0] must be an integer, got 250.5"},
+		{"fraction in object", `{"packSizes": [250, {"size": 500.25}]}`, "packSizes[1] must be an integer, got 500.25"},
+		{"exponent", `{"packSizes": [250, 5e2]}`, "packSizes[1] must be an integer, got 5e2"},
+		{"beyond int range", `{"packSizes": [250, 99999999999999999999]}`, "packSizes[1] is out of range: 99999999999999999999"},
+		{"string size in object", `{"packSizes": [{"size": "250"}]}`, `packSizes[0] must be an integer, got "250"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postPackages(t, tc.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("POST /packages %s status = %d, want %d", tc.body, rec.Code, http.StatusBadRequest)
+			}
+			if got := errorMessage(t, rec); got != tc.wantMessage {
+				t.Errorf("POST /packages %s error = %q, want %q", tc.body, got, tc.wantMessage)
+			}
+			if !reflect.DeepEqual(PackSizes, []int{500}) {
+				t.Errorf("PackSizes = %v after a rejected update, want [500]", PackSizes)
+			}
+		})
+	}
+}
+
 func BenchmarkOptimizePacks(b *testing.B) {
 	testCases := []int{1, 250, 501, 1000, 12001}
 
@@ -79,3 +322,137 @@ func BenchmarkOptimizePacks(b *testing.B) {
 		})
 	}
 }
+
+func TestOptimizeQuantityRange(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	testCases := []struct {
+		description string
+		body        string
+		wantStatus  int
+		wantTotal   int
+		wantWaste   int
+	}{
+		{"range 480-520 picks 500", `{"minQuantity": 480, "maxQuantity": 520}`, http.StatusOK, 500, 20},
+		{"lower bound reachable", `{"minQuantity": 500, "maxQuantity": 520}`, http.StatusOK, 500, 0},
+		{"nothing packable in range", `{"minQuantity": 510, "maxQuantity": 520}`, http.StatusUnprocessableEntity, 0, 0},
+		{"inverted range", `{"minQuantity": 520, "maxQuantity": 480}`, http.StatusBadRequest, 0, 0},
+		{"missing maxQuantity", `{"minQuantity": 480}`, http.StatusBadRequest, 0, 0},
+		{"combined with quantity", `{"quantity": 500, "minQuantity": 480, "maxQuantity": 520}`, http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /optimize %s status = %d, want %d: %s", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var result OptimizationResult
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.TotalItems != tc.wantTotal || result.Waste != tc.wantWaste {
+				t.Errorf("POST /optimize %s = %d items, %d waste, want %d items, %d waste",
+					tc.body, result.TotalItems, result.Waste, tc.wantTotal, tc.wantWaste)
+			}
+		})
+	}
+}
+
+func TestOptimizeIncludeUnused(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	testCases := []struct {
+		description string
+		body        string
+		wantPacks   []PackResult
+		wantTotal   int
+	}{
+		{
+			"every size listed with zeros",
+			`{"quantity": 501, "includeUnused": true}`,
+			[]PackResult{{5000, 0, ""}, {2000, 0, ""}, {1000, 0, ""}, {500, 1, ""}, {250, 1, ""}},
+			2,
+		},
+		{
+			"default omits zeros",
+			`{"quantity": 501}`,
+			[]PackResult{{500, 1, ""}, {250, 1, ""}},
+			2,
+		},
+		{
+			"large order keeps counts",
+			`{"quantity": 12001, "includeUnused": true}`,
+			[]PackResult{{5000, 2, ""}, {2000, 1, ""}, {1000, 0, ""}, {500, 0, ""}, {250, 1, ""}},
+			4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize %s status = %d: %s", tc.body, rec.Code, rec.Body.String())
+			}
+			var result OptimizationResult
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) || result.TotalPacks != tc.wantTotal {
+				t.Errorf("POST /optimize %s = %+v (%d packs), want %+v (%d packs)",
+					tc.body, result.Packs, result.TotalPacks, tc.wantPacks, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestOptimizeKeepsPostedPackSizeOrder(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	posted := []int{500, 250, 5000, 1000, 2000}
+	if rec := serveJSON(t, http.MethodPost, "/packages", `{"packSizes": [500, 250, 5000, 1000, 2000]}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	assertPostedOrder := func(t *testing.T, after string) {
+		t.Helper()
+		if !reflect.DeepEqual(PackSizes, posted) {
+			t.Fatalf("PackSizes = %v after %s, want the posted %v", PackSizes, after, posted)
+		}
+		var catalog struct {
+			PackSizes []int `json:"packSizes"`
+		}
+		rec := serveJSON(t, http.MethodGet, "/packages", "")
+		if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+			t.Fatalf("decoding GET /packages: %v", err)
+		}
+		if !reflect.DeepEqual(catalog.PackSizes, posted) {
+			t.Fatalf("GET /packages = %v after %s, want the posted %v", catalog.PackSizes, after, posted)
+		}
+	}
+
+	requests := []struct {
+		method, target, body string
+	}{
+		{http.MethodPost, "/optimize", `{"quantity": 12001}`},
+		{http.MethodPost, "/optimize", `{"quantity": 751, "mode": "fewestLines", "includeUnused": true}`},
+		{http.MethodPost, "/optimize?tree=true", `{"quantity": 251, "masterCartonSize": 1000, "topK": 3, "tolerances": [0.1]}`},
+		{http.MethodPost, "/optimize/batch", `{"quantities": [1, 501, 9999]}`},
+		{http.MethodPost, "/breakdown", `{"totalItems": 750}`},
+		{http.MethodGet, "/optimize/min-waste?quantity=501", ""},
+	}
+	for round := 0; round < 3; round++ {
+		for _, req := range requests {
+			if rec := serveJSON(t, req.method, req.target, req.body); rec.Code != http.StatusOK {
+				t.Fatalf("%s %s status = %d: %s", req.method, req.target, rec.Code, rec.Body.String())
+			}
+			assertPostedOrder(t, req.method+" "+req.target)
+		}
+		if _, err := OptimizePacks(1234); err != nil {
+			t.Fatalf("OptimizePacks(1234) returned error: %v", err)
+		}
+		assertPostedOrder(t, "OptimizePacks")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Values of OptimizationResult.BindingConstraint: the constraint that moved
+// the solution away from the unconstrained optimum, or BindingNone when
+// constraints were set but the optimum already met them.
+const (
+	BindingNone            = "none"
+	BindingMaxPacks        = "maxPacks"
+	BindingMaxWastePerSize = "maxWastePerSize"
+	BindingMaxWaste        = "maxWaste"
+	BindingMaxQuantity     = "maxQuantity"
+)
+
+// validatePackLimit checks that opts.MaxPacks is a usable pack limit.
+func validatePackLimit(opts OptimizeOptions) error {
+	if opts.MaxPacks < 0 {
+		return fmt.Errorf("maxPacks must not be negative")
+	}
+	if opts.MaxPacks > 0 && perPackMode(opts.Mode) {
+		return fmt.Errorf("maxPacks is not supported with mode %q", opts.Mode)
+	}
+	return nil
+}
+
+// hasConstraints reports whether opts limits the solutions the objective
+// may pick from, so the result should name its binding constraint.
+func hasConstraints(opts OptimizeOptions) bool {
+	return opts.MaxPacks > 0 || opts.MaxWastePerSize != nil || opts.MaxSlots > 0 || opts.Budget > 0 ||
+		opts.MaxWaste > 0 || opts.MaxQuantity > 0
+}
+
+// capBinding names the option that set opts.MaxQuantity, for a result the
+// cap moved: maxWaste when limitWaste tightened it, maxQuantity otherwise.
+func capBinding(opts OptimizeOptions) string {
+	if opts.wasteLimited {
+		return BindingMaxWaste
+	}
+	return BindingMaxQuantity
+}
+
+// preferCapped runs a near-optimal preference over the totals lo..hi that
+// opts.MaxQuantity leaves, and reports whether the cap moved its pick: the
+// preference is rerun over the whole window when the cap cut it.
+func preferCapped(prefer func(sizes []int, lo, hi int) (int, map[int]int), sizes []int, lo, hi int, opts OptimizeOptions) (int, map[int]int, bool) {
+	capped := capAtMaxQuantity(hi, opts)
+	amount, counts := prefer(sizes, lo, capped)
+	if capped == hi {
+		return amount, counts, false
+	}
+	uncapped, _ := prefer(sizes, lo, hi)
+	return amount, counts, uncapped != amount
+}
+
+// limitPacks returns a copy of dp in which totals needing more than maxPacks
+// packs are unreachable. The fewest-pack mix of a total within the limit
+// only passes through totals within it, so the copy backtracks like dp.
+func limitPacks(dp []dpEntry, maxPacks int) []dpEntry {
+	limited := make([]dpEntry, len(dp))
+	copy(limited, dp)
+	for i := range limited {
+		if limited[i].packs > maxPacks {
+			limited[i].packs = math.MaxInt32
+		}
+	}
+	return limited
+}
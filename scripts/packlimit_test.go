@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMaxPacks(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+		wantBinding string
+	}{
+		{"limit binds", 12001, OptimizeOptions{MaxPacks: 3},
+			[]PackResult{{PackSize: 5000, Quantity: 3}}, BindingMaxPacks},
+		{"limit met by the optimum", 12001, OptimizeOptions{MaxPacks: 4},
+			[]PackResult{{PackSize: 5000, Quantity: 2}, {PackSize: 2000, Quantity: 1}, {PackSize: 250, Quantity: 1}}, BindingNone},
+		{"one pack", 501, OptimizeOptions{MaxPacks: 1},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, BindingMaxPacks},
+		{"fewest lines yields to the limit", 12001, OptimizeOptions{MaxPacks: 3, Mode: ModeFewestLines},
+			[]PackResult{{PackSize: 5000, Quantity: 3}}, BindingMaxPacks},
+		{"waste cap binds within the limit", 4800, OptimizeOptions{MaxPacks: 3, MaxWastePerSize: map[int]int{5000: 0}},
+			[]PackResult{{PackSize: 2000, Quantity: 2}, {PackSize: 1000, Quantity: 1}}, BindingMaxWastePerSize},
+		{"no constraints", 501, OptimizeOptions{},
+			[]PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, catalog, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.BindingConstraint != tc.wantBinding {
+				t.Errorf("bindingConstraint = %q, want %q", result.BindingConstraint, tc.wantBinding)
+			}
+		})
+	}
+}
+
+func TestMaxPacksInfeasibleAndInvalid(t *testing.T) {
+	testCases := []struct {
+		description string
+		catalog     Catalog
+		quantity    int
+		opts        OptimizeOptions
+		infeasible  bool
+	}{
+		{"exact order needs more packs", Catalog{PackSizes: []int{250, 500}}, 750, OptimizeOptions{MaxPacks: 1, Policy: PolicyExact}, true},
+		{"single size", Catalog{PackSizes: []int{250}}, 1000, OptimizeOptions{MaxPacks: 3}, true},
+		{"negative limit", Catalog{PackSizes: []int{250, 500}}, 750, OptimizeOptions{MaxPacks: -1}, false},
+		{"cheapest mode", Catalog{PackSizes: []int{250, 500}}, 750, OptimizeOptions{MaxPacks: 1, Mode: ModeCheapest, Costs: map[int]float64{250: 1, 500: 2}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := OptimizeCatalog(tc.quantity, tc.catalog, tc.opts)
+			if err == nil {
+				t.Fatalf("OptimizeCatalog(%d) returned no error", tc.quantity)
+			}
+			if errors.Is(err, ErrInfeasible) != tc.infeasible {
+				t.Errorf("OptimizeCatalog(%d) error = %v, want infeasible %v", tc.quantity, err, tc.infeasible)
+			}
+		})
+	}
+}
+
+func TestOptimizeReportsBindingConstraint(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 12001, "maxPacks": 3}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.BindingConstraint != BindingMaxPacks || result.TotalPacks != 3 || result.TotalItems != 15000 {
+		t.Errorf("result = %d packs, %d items, bindingConstraint %q; want 3 packs, 15000 items, %q",
+			result.TotalPacks, result.TotalItems, result.BindingConstraint, BindingMaxPacks)
+	}
+}
+
+func TestWasteLimitBinding(t *testing.T) {
+	// Weighted by packs, 900 ships as one 1000 pack wasting 100 rather than
+	// nine 100s; a waste limit below 100 forces the nine.
+	catalog := Catalog{PackSizes: []int{100, 1000}}
+	weights := &ScoreWeights{Waste: 1, Packs: 100}
+	nine := []PackResult{{PackSize: 100, Quantity: 9}}
+	one := []PackResult{{PackSize: 1000, Quantity: 1}}
+
+	testCases := []struct {
+		description string
+		opts        OptimizeOptions
+		want        []PackResult
+		wantBinding string
+	}{
+		{"maxWaste binds", OptimizeOptions{Weights: weights, MaxWaste: 50}, nine, BindingMaxWaste},
+		{"maxQuantity binds", OptimizeOptions{Weights: weights, MaxQuantity: 950}, nine, BindingMaxQuantity},
+		{"tighter maxWaste named", OptimizeOptions{Weights: weights, MaxWaste: 50, MaxQuantity: 990}, nine, BindingMaxWaste},
+		{"maxWaste met by the optimum", OptimizeOptions{Weights: weights, MaxWaste: 100}, one, BindingNone},
+		{"maxWaste binds a preference", OptimizeOptions{PreferEvenCounts: true, WasteDelta: 100, MaxWaste: 50},
+			[]PackResult{{PackSize: 100, Quantity: 9}}, BindingMaxWaste},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(900, catalog, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(900) returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.BindingConstraint != tc.wantBinding {
+				t.Errorf("bindingConstraint = %q, want %q", result.BindingConstraint, tc.wantBinding)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "math"
+
+// percentPrecision is the number of decimal places reported in percentages.
+var percentPrecision = envInt("PERCENT_PRECISION", 2)
+
+// percentage reports part as a percentage of whole, from 0 to 100 rather
+// than a fraction, rounded to percentPrecision decimal places. Every
+// percentage in a response goes through it so they all read the same way.
+// A zero whole is 0%.
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	scale := math.Pow(10, float64(percentPrecision))
+	return math.Round(100*float64(part)/float64(whole)*scale) / scale
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestPercentage(t *testing.T) {
+	saved := percentPrecision
+	t.Cleanup(func() { percentPrecision = saved })
+
+	testCases := []struct {
+		description string
+		part, whole int
+		precision   int
+		want        float64
+	}{
+		{"whole percentage", 6, 10, 2, 60},
+		{"small share", 4, 1000, 2, 0.4},
+		{"repeating decimal", 1, 3, 2, 33.33},
+		{"rounded up", 2, 3, 2, 66.67},
+		{"more decimals", 2, 3, 4, 66.6667},
+		{"no decimals", 2, 3, 0, 67},
+		{"everything", 250, 250, 2, 100},
+		{"empty whole", 0, 0, 2, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			percentPrecision = tc.precision
+			if got := percentage(tc.part, tc.whole); got != tc.want {
+				t.Errorf("percentage(%d, %d) at %d decimals = %v, want %v", tc.part, tc.whole, tc.precision, got, tc.want)
+			}
+		})
+	}
+}
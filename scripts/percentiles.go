@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+)
+
+// PercentileResult is the plan for the order size at one percentile of a
+// dataset, or the reason it could not be optimized.
+type PercentileResult struct {
+	Percentile float64             `json:"percentile"`
+	Quantity   int                 `json:"quantity"`
+	Result     *OptimizationResult `json:"result,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// PercentilesResult is the response of POST /optimize/percentiles, in the
+// order the percentiles were requested.
+type PercentilesResult struct {
+	Orders      int                `json:"orders"`
+	Percentiles []PercentileResult `json:"percentiles"`
+}
+
+// validatePercentiles checks the dataset and percentiles of a request:
+// positive order quantities, within maxBatchSize, and percentiles above 0
+// and at most 100.
+func validatePercentiles(orders []int, percentiles []float64) error {
+	if len(orders) == 0 {
+		return fmt.Errorf("orders must contain at least one order quantity")
+	}
+	if maxBatchSize > 0 && len(orders) > maxBatchSize {
+		return fmt.Errorf("orders may hold at most %d quantities", maxBatchSize)
+	}
+	for i, quantity := range orders {
+		if quantity <= 0 {
+			return fmt.Errorf("orders[%d] must be a positive quantity", i)
+		}
+	}
+	if len(percentiles) == 0 {
+		return fmt.Errorf("percentiles must contain at least one percentile")
+	}
+	for _, p := range percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("percentile %g must be above 0 and at most 100", p)
+		}
+	}
+	return nil
+}
+
+// orderPercentile is the nearest-rank percentile p of sorted: the smallest
+// order at least p% of the orders do not exceed. It is always an order of
+// the dataset, so it can be optimized as is.
+func orderPercentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// OptimizePercentiles optimizes the order sizes at each percentile of
+// orders against catalog, from one table shared like OptimizeBatch.
+func OptimizePercentiles(orders []int, percentiles []float64, catalog Catalog, opts OptimizeOptions) ([]PercentileResult, error) {
+	sorted := slices.Clone(orders)
+	slices.Sort(sorted)
+
+	quantities := make([]int, len(percentiles))
+	for i, p := range percentiles {
+		quantities[i] = orderPercentile(sorted, p)
+	}
+	items, err := OptimizeBatch(quantities, catalog, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]PercentileResult, len(items))
+	for i, item := range items {
+		results[i] = PercentileResult{Percentile: percentiles[i], Quantity: item.Quantity, Result: item.Result, Error: item.Error}
+	}
+	return results, nil
+}
+
+// HTTP handler optimizing the percentile order sizes of a dataset
+func percentilesHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Orders      []int     `json:"orders"`
+		Percentiles []float64 `json:"percentiles"`
+		Mode        string    `json:"mode"`
+		Policy      string    `json:"policy"`
+		Catalog     string    `json:"catalog"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validatePercentiles(request.Orders, request.Percentiles); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	catalogName := request.Catalog
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", request.Catalog), http.StatusNotFound)
+		return
+	}
+
+	results, err := OptimizePercentiles(request.Orders, request.Percentiles, catalog, catalog.Defaults.apply(OptimizeOptions{
+		Mode:    request.Mode,
+		Policy:  request.Policy,
+		Context: r.Context(),
+	}))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PercentilesResult{Orders: len(request.Orders), Percentiles: results})
+}
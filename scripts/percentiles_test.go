@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOrderPercentile(t *testing.T) {
+	sorted := []int{100, 200, 300, 400, 500, 600, 700, 800, 900, 12001}
+
+	testCases := []struct {
+		percentile float64
+		want       int
+	}{
+		{50, 500},
+		{90, 900},
+		{99, 12001},
+		{100, 12001},
+		{0.1, 100},
+	}
+
+	for _, tc := range testCases {
+		if got := orderPercentile(sorted, tc.percentile); got != tc.want {
+			t.Errorf("orderPercentile(p%g) = %d, want %d", tc.percentile, got, tc.want)
+		}
+	}
+}
+
+func TestPercentilesHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := serveJSON(t, http.MethodPost, "/optimize/percentiles",
+		`{"orders": [12001, 300, 100, 900, 500, 700, 200, 800, 400, 600], "percentiles": [50, 90, 99]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/percentiles status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var response PercentilesResult
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if response.Orders != 10 || len(response.Percentiles) != 3 {
+		t.Fatalf("response = %+v, want 10 orders and 3 percentiles", response)
+	}
+
+	want := []struct {
+		quantity int
+		packs    []PackResult
+	}{
+		{500, []PackResult{{PackSize: 500, Quantity: 1}}},
+		{900, []PackResult{{PackSize: 1000, Quantity: 1}}},
+		{12001, []PackResult{{PackSize: 5000, Quantity: 2}, {PackSize: 2000, Quantity: 1}, {PackSize: 250, Quantity: 1}}},
+	}
+	for i, w := range want {
+		got := response.Percentiles[i]
+		if got.Quantity != w.quantity || got.Result == nil || !reflect.DeepEqual(got.Result.Packs, w.packs) {
+			t.Errorf("p%g = %+v, want quantity %d packed as %+v", got.Percentile, got, w.quantity, w.packs)
+		}
+	}
+}
+
+func TestPercentilesHandlerInvalid(t *testing.T) {
+	for _, body := range []string{
+		`{"orders": [], "percentiles": [50]}`,
+		`{"orders": [100, 0], "percentiles": [50]}`,
+		`{"orders": [100], "percentiles": []}`,
+		`{"orders": [100], "percentiles": [0]}`,
+		`{"orders": [100], "percentiles": [101]}`,
+	} {
+		if rec := serveJSON(t, http.MethodPost, "/optimize/percentiles", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize/percentiles %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// permalinkVersion prefixes every permalink code. The payload is the
+// request in the public JSON of POST /optimize, whose field names are kept
+// stable; a change to how codes are read must bump the version.
+const permalinkVersion = "v1"
+
+// permalinkPath is where permalinks are served; the code follows it.
+const permalinkPath = "/optimize/permalink/"
+
+// errMalformedPermalink reports a permalink code that does not decode.
+var errMalformedPermalink = errors.New("malformed permalink")
+
+// permalinkPayload is what a permalink encodes: the optimize request and a
+// hash of the catalog it ran against, so a link to a catalog that has since
+// changed is refused rather than silently answered differently.
+type permalinkPayload struct {
+	Request     optimizeRequest `json:"request"`
+	CatalogHash string          `json:"catalogHash"`
+}
+
+// catalogHash identifies the contents of catalog, whatever the order its
+// sizes were given in.
+func catalogHash(catalog Catalog) string {
+	data, _ := json.Marshal(Catalog{PackSizes: sortedDescending(catalog.PackSizes), Labels: catalog.Labels, Defaults: catalog.Defaults})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodePermalink returns the code of request against catalog: the version,
+// a dot and the URL-safe base64 of the payload.
+func encodePermalink(request optimizeRequest, catalog Catalog) string {
+	data, _ := json.Marshal(permalinkPayload{Request: request, CatalogHash: catalogHash(catalog)})
+	return permalinkVersion + "." + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePermalink reverses encodePermalink.
+func decodePermalink(code string) (permalinkPayload, error) {
+	var payload permalinkPayload
+	version, encoded, ok := strings.Cut(code, ".")
+	if !ok {
+		return payload, fmt.Errorf("%w: missing version", errMalformedPermalink)
+	}
+	if version != permalinkVersion {
+		return payload, fmt.Errorf("unsupported permalink version %q", version)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return payload, fmt.Errorf("%w: not URL-safe base64", errMalformedPermalink)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return payload, fmt.Errorf("%w: invalid payload", errMalformedPermalink)
+	}
+	return payload, nil
+}
+
+// HTTP handler recomputing the optimize result a permalink encodes. The
+// request is replayed through optimizeHandler, so the response honors
+// Accept and ?format= like POST /optimize.
+func permalinkHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := decodePermalink(r.PathValue("code"))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	catalog, ok := namedCatalogs.lookup(payload.Request.catalogName())
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", payload.Request.catalogName()), http.StatusNotFound)
+		return
+	}
+	if catalogHash(catalog) != payload.CatalogHash {
+		writeError(w, fmt.Sprintf("Catalog %q has changed since the permalink was created", payload.Request.catalogName()), http.StatusConflict)
+		return
+	}
+
+	body, _ := json.Marshal(payload.Request)
+	replay := r.Clone(r.Context())
+	replay.Method = http.MethodPost
+	replay.Body = io.NopCloser(bytes.NewReader(body))
+	replay.ContentLength = int64(len(body))
+	replay.Header.Set("Content-Type", jsonContentType)
+	optimizeHandler(w, replay)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPermalinkRoundTrip(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}, Labels: map[int]string{250: "Small"}}
+	seed := int64(7)
+	request := optimizeRequest{
+		Quantity:   12001,
+		Mode:       ModeFewestLines,
+		Costs:      costTable{250: {Amount: 1.1, Currency: "USD"}},
+		Exclude:    []int{1000},
+		SpreadTies: true,
+		Seed:       &seed,
+		OrderRef:   "SO-1042",
+	}
+
+	code := encodePermalink(request, catalog)
+	if !strings.HasPrefix(code, permalinkVersion+".") {
+		t.Fatalf("code %q lacks the version prefix", code)
+	}
+	payload, err := decodePermalink(code)
+	if err != nil {
+		t.Fatalf("decodePermalink(%q) returned error: %v", code, err)
+	}
+	if !reflect.DeepEqual(payload.Request, request) {
+		t.Errorf("decoded request = %+v, want %+v", payload.Request, request)
+	}
+	if payload.CatalogHash != catalogHash(catalog) {
+		t.Errorf("catalogHash = %q, want %q", payload.CatalogHash, catalogHash(catalog))
+	}
+	if reordered := (Catalog{PackSizes: []int{1000, 250, 500}, Labels: catalog.Labels}); catalogHash(reordered) != payload.CatalogHash {
+		t.Error("catalogHash depends on the order of the sizes")
+	}
+	if encodePermalink(request, catalog) != code {
+		t.Error("encodePermalink is not deterministic")
+	}
+}
+
+func TestDecodePermalinkV1(t *testing.T) {
+	// A code issued by version 1 must keep decoding as long as v1 is served.
+	payload, err := decodePermalink("v1.eyJyZXF1ZXN0Ijp7InF1YW50aXR5IjoxMjAwMX0sImNhdGFsb2dIYXNoIjoiMDAwMSJ9")
+	if err != nil {
+		t.Fatalf("decodePermalink returned error: %v", err)
+	}
+	if payload.Request.Quantity != 12001 || payload.CatalogHash != "0001" {
+		t.Errorf("payload = %+v, want quantity 12001 and hash 0001", payload)
+	}
+}
+
+func TestDecodePermalinkInvalid(t *testing.T) {
+	for _, code := range []string{"", "v1", "v2.e30", "v1.!!", "v1.bm90IGpzb24", "v1.eyJib2d1cyI6MX0"} {
+		if _, err := decodePermalink(code); err == nil {
+			t.Errorf("decodePermalink(%q) returned no error", code)
+		}
+	}
+	if _, err := decodePermalink("v1.!!"); !errors.Is(err, errMalformedPermalink) {
+		t.Errorf("decodePermalink(bad base64) error = %v, want errMalformedPermalink", err)
+	}
+}
+
+func TestPermalinkHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	withNamedCatalogs(t)
+
+	rec := serveJSON(t, http.MethodPost, "/optimize?permalink=true", `{"quantity": 7250, "mode": "fewestLines"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize?permalink=true status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var original OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&original); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !strings.HasPrefix(original.Permalink, permalinkPath) {
+		t.Fatalf("permalink = %q, want a path under %s", original.Permalink, permalinkPath)
+	}
+
+	rec = serveJSON(t, http.MethodGet, original.Permalink, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET permalink status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var replayed OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&replayed); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !reflect.DeepEqual(replayed.Packs, original.Packs) || replayed.TotalItems != original.TotalItems {
+		t.Errorf("replayed packs = %+v, want %+v", replayed.Packs, original.Packs)
+	}
+
+	if rec := serveJSON(t, http.MethodGet, permalinkPath+"v1.!!", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed permalink status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if rec := postPackages(t, `{"packSizes": [250, 500]}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages status = %d", rec.Code)
+	}
+	if rec := serveJSON(t, http.MethodGet, original.Permalink, ""); rec.Code != http.StatusConflict {
+		t.Errorf("permalink to a changed catalog status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestPermalinkPinsSpreadTiesSeed(t *testing.T) {
+	withPackSizes(t, []int{100, 200, 300, 400})
+
+	rec := serveJSON(t, http.MethodPost, "/optimize?permalink=true", `{"quantity": 800, "spreadTies": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var original OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&original); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	payload, err := decodePermalink(strings.TrimPrefix(original.Permalink, permalinkPath))
+	if err != nil {
+		t.Fatalf("decodePermalink returned error: %v", err)
+	}
+	if payload.Request.Seed == nil || *payload.Request.Seed != original.Diagnostics.Seed {
+		t.Errorf("permalink seed = %v, want the seed used, %d", payload.Request.Seed, original.Diagnostics.Seed)
+	}
+}
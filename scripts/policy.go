@@ -0,0 +1,121 @@
+package main
+
+import (
+	"math"
+	"os"
+	"slices"
+)
+
+// Fulfillment policies decide which totals may fill an order.
+const (
+	// PolicyOverfill ships the smallest reachable total at or above the
+	// order. It is the default.
+	PolicyOverfill = "overfill"
+	// PolicyExact only accepts a total equal to the order and fails with
+	// ErrInfeasible otherwise.
+	PolicyExact = "exact"
+	// PolicyAllowUnderfill ships the reachable total nearest the order,
+	// breaking ties between an overfilled and an underfilled total by the
+	// underfill tiebreak. An underfilled result reports the missing units as
+	// Shortfall.
+	PolicyAllowUnderfill = "allow-underfill"
+)
+
+// supportedPolicies lists every accepted fulfillment policy.
+var supportedPolicies = []string{PolicyOverfill, PolicyExact, PolicyAllowUnderfill}
+
+// fulfillmentPolicy is the server default, applied to every optimize request
+// that does not set its own policy. A request's policy always takes
+// precedence.
+var fulfillmentPolicy = envPolicy()
+
+func envPolicy() string {
+	policy := os.Getenv("FULFILLMENT_POLICY")
+	if policy == "" {
+		return PolicyOverfill
+	}
+	return policy
+}
+
+// Underfill tiebreaks decide which of two totals equally near the order
+// PolicyAllowUnderfill ships.
+const (
+	// TiebreakOver ships the overfilled total. It is the default.
+	TiebreakOver = "over"
+	// TiebreakUnder ships the underfilled total.
+	TiebreakUnder = "under"
+	// TiebreakFewerPacks ships the total needing fewer packs, and the
+	// overfilled one when both need as many.
+	TiebreakFewerPacks = "fewer-packs"
+)
+
+// supportedTiebreaks lists every accepted underfill tiebreak.
+var supportedTiebreaks = []string{TiebreakOver, TiebreakUnder, TiebreakFewerPacks}
+
+// underfillTiebreak is the server default, applied to every optimize request
+// that does not set its own tiebreak.
+var underfillTiebreak = envTiebreak()
+
+func envTiebreak() string {
+	tiebreak := os.Getenv("UNDERFILL_TIEBREAK")
+	if tiebreak == "" {
+		return TiebreakOver
+	}
+	return tiebreak
+}
+
+// validTiebreak reports whether tiebreak names a supported underfill
+// tiebreak.
+func validTiebreak(tiebreak string) bool {
+	return slices.Contains(supportedTiebreaks, tiebreak)
+}
+
+// validPolicy reports whether policy names a supported fulfillment policy.
+func validPolicy(policy string) bool {
+	return slices.Contains(supportedPolicies, policy)
+}
+
+// selectAmount picks the total to ship for orderQuantity from a filled pack
+// table under policy, using tiebreak when PolicyAllowUnderfill finds two
+// equally near totals. It returns -1 when the policy admits no reachable
+// total.
+func selectAmount(dp []dpEntry, orderQuantity int, policy, tiebreak string) int {
+	maxSize := len(dp) - 1
+	reachable := func(amount int) bool {
+		return amount > 0 && amount <= maxSize && dp[amount].packs != math.MaxInt32
+	}
+
+	// An exactly fillable order ships as-is under every policy.
+	if reachable(orderQuantity) {
+		return orderQuantity
+	}
+
+	switch policy {
+	case PolicyExact:
+		return -1
+	case PolicyAllowUnderfill:
+		for distance := 1; distance <= maxSize; distance++ {
+			over, under := orderQuantity+distance, orderQuantity-distance
+			switch {
+			case reachable(over) && reachable(under):
+				if tiebreak == TiebreakUnder || tiebreak == TiebreakFewerPacks && dp[under].packs < dp[over].packs {
+					return under
+				}
+				return over
+			case reachable(over):
+				return over
+			case reachable(under):
+				return under
+			}
+		}
+		return -1
+	}
+
+	// Find minimal totalItems ≥ orderQuantity
+	for i := orderQuantity; i <= maxSize; i++ {
+		if dp[i].packs != math.MaxInt32 {
+			return i
+		}
+	}
+	return -1
+}
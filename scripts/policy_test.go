@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// withFulfillmentPolicy swaps the server default policy for a test.
+func withFulfillmentPolicy(t *testing.T, policy string) {
+	t.Helper()
+	saved := fulfillmentPolicy
+	fulfillmentPolicy = policy
+	t.Cleanup(func() { fulfillmentPolicy = saved })
+}
+
+func TestFulfillmentPolicyServerDefault(t *testing.T) {
+	testCases := []struct {
+		policy        string
+		quantity      int
+		wantStatus    int
+		wantItems     int
+		wantWaste     int
+		wantShortfall int
+	}{
+		{PolicyOverfill, 260, http.StatusOK, 500, 240, 0},
+		{PolicyOverfill, 750, http.StatusOK, 750, 0, 0},
+		{PolicyExact, 750, http.StatusOK, 750, 0, 0},
+		{PolicyExact, 260, http.StatusUnprocessableEntity, 0, 0, 0},
+		{PolicyAllowUnderfill, 260, http.StatusOK, 250, 0, 10},
+		{PolicyAllowUnderfill, 400, http.StatusOK, 500, 100, 0},
+		{PolicyAllowUnderfill, 100, http.StatusOK, 250, 150, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %d", tc.policy, tc.quantity), func(t *testing.T) {
+			withPackSizes(t, []int{250, 500})
+			withFulfillmentPolicy(t, tc.policy)
+
+			rec := postOptimize(t, fmt.Sprintf(`{"quantity": %d}`, tc.quantity))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /optimize %d under %s status = %d, want %d (body %q)",
+					tc.quantity, tc.policy, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.TotalItems != tc.wantItems || result.Waste != tc.wantWaste || result.Shortfall != tc.wantShortfall {
+				t.Errorf("POST /optimize %d under %s = %d items, %d waste, %d shortfall; want %d, %d, %d",
+					tc.quantity, tc.policy, result.TotalItems, result.Waste, result.Shortfall,
+					tc.wantItems, tc.wantWaste, tc.wantShortfall)
+			}
+		})
+	}
+}
+
+func TestFulfillmentPolicyRequestOverridesServerDefault(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	withFulfillmentPolicy(t, PolicyExact)
+
+	rec := postOptimize(t, `{"quantity": 260, "policy": "overfill"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"totalItems":500`) {
+		t.Errorf("request policy overfill under server exact = %d %q, want 500 items", rec.Code, rec.Body.String())
+	}
+
+	withFulfillmentPolicy(t, PolicyOverfill)
+	rec = postOptimize(t, `{"quantity": 260, "policy": "exact"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("request policy exact under server overfill status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestFulfillmentPolicyRejectsUnknown(t *testing.T) {
+	rec := postOptimize(t, `{"quantity": 260, "policy": "round-down"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown policy status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// withUnderfillTiebreak swaps the server default underfill tiebreak for a
+// test.
+func withUnderfillTiebreak(t *testing.T, tiebreak string) {
+	t.Helper()
+	saved := underfillTiebreak
+	underfillTiebreak = tiebreak
+	t.Cleanup(func() { underfillTiebreak = saved })
+}
+
+func TestUnderfillTiebreak(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		tiebreak    string
+		wantItems   int
+	}{
+		// 800 sits 100 from both 700 (one pack) and 900 (three packs).
+		{"default prefers over", []int{300, 700}, 800, "", 900},
+		{"over", []int{300, 700}, 800, TiebreakOver, 900},
+		{"under", []int{300, 700}, 800, TiebreakUnder, 700},
+		{"fewer packs picks under", []int{300, 700}, 800, TiebreakFewerPacks, 700},
+		// 900 sits 100 from both 800 (two packs) and 1000 (one pack).
+		{"fewer packs picks over", []int{400, 1000}, 900, TiebreakFewerPacks, 1000},
+		{"under despite more packs", []int{400, 1000}, 900, TiebreakUnder, 800},
+		// 375 sits 125 from both 250 and 500, one pack each.
+		{"fewer packs tie prefers over", []int{250, 500}, 375, TiebreakFewerPacks, 500},
+		{"nearest wins regardless", []int{250, 500}, 260, TiebreakOver, 250},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := solve(tc.quantity, sortedDescending(tc.sizes), OptimizeOptions{
+				Policy:            PolicyAllowUnderfill,
+				UnderfillTiebreak: tc.tiebreak,
+			})
+			if err != nil {
+				t.Fatalf("solve(%d, %v) returned error: %v", tc.quantity, tc.sizes, err)
+			}
+			if result.TotalItems != tc.wantItems {
+				t.Errorf("solve(%d, %v, %q) = %d items, want %d", tc.quantity, tc.sizes, tc.tiebreak, result.TotalItems, tc.wantItems)
+			}
+		})
+	}
+}
+
+func TestUnderfillTiebreakServerDefault(t *testing.T) {
+	withPackSizes(t, []int{300, 700})
+	withUnderfillTiebreak(t, TiebreakUnder)
+
+	rec := postOptimize(t, `{"quantity": 800, "policy": "allow-underfill"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"totalItems":700`) {
+		t.Errorf("server tiebreak under = %d %q, want 700 items", rec.Code, rec.Body.String())
+	}
+
+	rec = postOptimize(t, `{"quantity": 800, "policy": "allow-underfill", "underfillTiebreak": "over"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"totalItems":900`) {
+		t.Errorf("request tiebreak over under server under = %d %q, want 900 items", rec.Code, rec.Body.String())
+	}
+
+	rec = postOptimize(t, `{"quantity": 800, "underfillTiebreak": "nearest"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown tiebreak status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExactlyReachableOrdersHaveNoWaste(t *testing.T) {
+	const bound = 3000
+	catalogs := [][]int{{250, 500}, {250, 500, 1000, 2000, 5000}, {23, 31, 53}}
+
+	for _, sizes := range catalogs {
+		sizes := sortedDescending(sizes)
+		reachable := reachableTable(sizes, bound)
+		for _, policy := range supportedPolicies {
+			for quantity := 1; quantity <= bound; quantity++ {
+				if !reachable[quantity] {
+					continue
+				}
+				result, err := solve(quantity, sizes, OptimizeOptions{Policy: policy})
+				if err != nil {
+					t.Fatalf("solve(%d, %v, %s) returned error: %v", quantity, sizes, policy, err)
+				}
+				if result.Waste != 0 || result.Shortfall != 0 || result.TotalItems != quantity {
+					t.Fatalf("solve(%d, %v, %s) = %d items, waste %d, shortfall %d, want an exact fill",
+						quantity, sizes, policy, result.TotalItems, result.Waste, result.Shortfall)
+				}
+			}
+		}
+	}
+}
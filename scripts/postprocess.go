@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PostProcessor applies a business rule to an optimization result before it
+// is serialized. It may return result modified or a replacement, and may call
+// optimize to re-solve for another quantity against the same catalog and
+// options. The returned result must stay consistent: TotalItems and
+// TotalPacks must match Packs, and Waste and Shortfall must be measured
+// against the original OrderQuantity.
+type PostProcessor func(result *OptimizationResult, optimize func(orderQuantity int) (*OptimizationResult, error)) (*OptimizationResult, error)
+
+// postProcessorFactories builds the registered post-processors from the
+// argument given after their name in POST_PROCESSORS.
+var postProcessorFactories = map[string]func(arg string) (PostProcessor, error){
+	"minimum-order": minimumOrderBump,
+}
+
+// resultPostProcessors run, in order, on every /optimize result. main sets
+// them from POST_PROCESSORS.
+var resultPostProcessors []PostProcessor
+
+// parsePostProcessors reads a comma-separated list of name:arg entries, such
+// as "minimum-order:100".
+func parsePostProcessors(config string) ([]PostProcessor, error) {
+	var processors []PostProcessor
+	for _, entry := range strings.Split(config, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(entry, ":")
+		factory, ok := postProcessorFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown post-processor %q", name)
+		}
+		processor, err := factory(arg)
+		if err != nil {
+			return nil, fmt.Errorf("post-processor %q: %w", name, err)
+		}
+		processors = append(processors, processor)
+	}
+	return processors, nil
+}
+
+// postProcess runs processors over result and checks the invariants of what
+// they return.
+func postProcess(result *OptimizationResult, processors []PostProcessor, optimize func(int) (*OptimizationResult, error)) (*OptimizationResult, error) {
+	for _, processor := range processors {
+		var err error
+		if result, err = processor(result, optimize); err != nil {
+			return nil, err
+		}
+		counts := make(map[int]int, len(result.Packs))
+		for _, pack := range result.Packs {
+			counts[pack.PackSize] += pack.Quantity
+		}
+		checkInvariants(result, counts)
+	}
+	return result, nil
+}
+
+// minimumOrderBump ships at least arg items: smaller orders are packed as if
+// arg had been ordered, with the extra reported as waste.
+func minimumOrderBump(arg string) (PostProcessor, error) {
+	minimum, err := strconv.Atoi(arg)
+	if err != nil || minimum <= 0 {
+		return nil, fmt.Errorf("minimum must be a positive integer, got %q", arg)
+	}
+	return func(result *OptimizationResult, optimize func(int) (*OptimizationResult, error)) (*OptimizationResult, error) {
+		if result.TotalItems >= minimum {
+			return result, nil
+		}
+		bumped, err := optimize(minimum)
+		if err != nil {
+			return nil, err
+		}
+		rebaseOrder(bumped, result.OrderQuantity)
+		return bumped, nil
+	}, nil
+}
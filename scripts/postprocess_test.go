@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMinimumOrderBump(t *testing.T) {
+	withPackSizes(t, []int{40, 100})
+	processors, err := parsePostProcessors("minimum-order:100")
+	if err != nil {
+		t.Fatalf("parsePostProcessors returned error: %v", err)
+	}
+	saved := resultPostProcessors
+	resultPostProcessors = processors
+	t.Cleanup(func() { resultPostProcessors = saved })
+
+	testCases := []struct {
+		description string
+		quantity    int
+		wantPacks   []PackResult
+		wantWaste   int
+	}{
+		{"small order is bumped", 30, []PackResult{{PackSize: 100, Quantity: 1}}, 70},
+		{"order at the minimum is kept", 100, []PackResult{{PackSize: 100, Quantity: 1}}, 0},
+		{"larger order is kept", 180, []PackResult{{PackSize: 100, Quantity: 1}, {PackSize: 40, Quantity: 2}}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]int{"quantity": tc.quantity})
+			rec := postOptimize(t, string(body))
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if result.OrderQuantity != tc.quantity || !reflect.DeepEqual(result.Packs, tc.wantPacks) || result.Waste != tc.wantWaste {
+				t.Errorf("order %d = %+v, want packs %+v with waste %d", tc.quantity, result, tc.wantPacks, tc.wantWaste)
+			}
+		})
+	}
+}
+
+func TestParsePostProcessorsRejectsBadConfig(t *testing.T) {
+	for _, config := range []string{"bogus", "minimum-order", "minimum-order:-5", "minimum-order:abc"} {
+		if _, err := parsePostProcessors(config); err == nil {
+			t.Errorf("parsePostProcessors(%q) should fail", config)
+		}
+	}
+	if processors, err := parsePostProcessors(""); err != nil || len(processors) != 0 {
+		t.Errorf("parsePostProcessors(\"\") = %d processors, %v, want none", len(processors), err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protobufContentType is the media type of the binary OptimizationResult.
+const protobufContentType = "application/x-protobuf"
+
+// Field numbers from optimization.proto.
+const (
+	protoFieldOrderQuantity protowire.Number = 1
+	protoFieldTotalItems    protowire.Number = 2
+	protoFieldTotalPacks    protowire.Number = 3
+	protoFieldPacks         protowire.Number = 4
+	protoFieldWaste         protowire.Number = 5
+	protoFieldShortfall     protowire.Number = 6
+	protoFieldTotalCost     protowire.Number = 7
+	protoFieldCurrency      protowire.Number = 8
+	protoFieldWarnings      protowire.Number = 9
+
+	protoFieldPackSize     protowire.Number = 1
+	protoFieldPackQuantity protowire.Number = 2
+	protoFieldPackLabel    protowire.Number = 3
+)
+
+// marshalResultProto encodes result as the OptimizationResult message
+// defined in optimization.proto.
+func marshalResultProto(result *OptimizationResult) []byte {
+	var b []byte
+	b = appendProtoInt(b, protoFieldOrderQuantity, result.OrderQuantity)
+	b = appendProtoInt(b, protoFieldTotalItems, result.TotalItems)
+	b = appendProtoInt(b, protoFieldTotalPacks, result.TotalPacks)
+	for _, pack := range result.Packs {
+		var msg []byte
+		msg = appendProtoInt(msg, protoFieldPackSize, pack.PackSize)
+		msg = appendProtoInt(msg, protoFieldPackQuantity, pack.Quantity)
+		msg = appendProtoString(msg, protoFieldPackLabel, pack.Label)
+		b = protowire.AppendTag(b, protoFieldPacks, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg)
+	}
+	b = appendProtoInt(b, protoFieldWaste, result.Waste)
+	b = appendProtoInt(b, protoFieldShortfall, result.Shortfall)
+	// total_cost is optional, so a zero cost is still sent.
+	if result.TotalCost != nil {
+		b = protowire.AppendTag(b, protoFieldTotalCost, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(*result.TotalCost))
+	}
+	b = appendProtoString(b, protoFieldCurrency, result.Currency)
+	for _, warning := range result.Warnings {
+		b = protowire.AppendTag(b, protoFieldWarnings, protowire.BytesType)
+		b = protowire.AppendString(b, warning)
+	}
+	return b
+}
+
+// appendProtoInt appends an int64 field, omitting zero values as proto3 does.
+func appendProtoInt(b []byte, num protowire.Number, v int) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(v)))
+}
+
+// appendProtoString appends a string field, omitting empty values as proto3
+// does.
+func appendProtoString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
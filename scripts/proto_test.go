@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoScalarTypes maps the scalar types optimization.proto uses to their
+// descriptor types.
+var protoScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"double": descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+}
+
+// loadProtoFile builds the descriptor of a .proto file holding only messages
+// of scalar, optional, repeated and message fields, as optimization.proto
+// does, so the encoder is checked against the file itself without protoc.
+func loadProtoFile(t *testing.T, path string) protoreflect.FileDescriptor {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	file := &descriptorpb.FileDescriptorProto{Name: proto.String(path), Syntax: proto.String("proto3")}
+	var message *descriptorpb.DescriptorProto
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "syntax "):
+		case strings.HasPrefix(line, "package "):
+			file.Package = proto.String(strings.TrimSuffix(strings.TrimPrefix(line, "package "), ";"))
+		case strings.HasPrefix(line, "message "):
+			message = &descriptorpb.DescriptorProto{Name: proto.String(strings.Fields(line)[1])}
+			file.MessageType = append(file.MessageType, message)
+		case line == "}":
+			message = nil
+		default:
+			words := strings.Fields(strings.TrimSuffix(line, ";"))
+			label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+			optional := false
+			switch words[0] {
+			case "repeated":
+				label, words = descriptorpb.FieldDescriptorProto_LABEL_REPEATED, words[1:]
+			case "optional":
+				optional, words = true, words[1:]
+			}
+			number, err := strconv.Atoi(words[len(words)-1])
+			if message == nil || len(words) != 4 || words[2] != "=" || err != nil {
+				t.Fatalf("%s: unsupported line %q", path, line)
+			}
+			field := &descriptorpb.FieldDescriptorProto{
+				Name:   proto.String(words[1]),
+				Number: proto.Int32(int32(number)),
+				Label:  label.Enum(),
+			}
+			if typ, ok := protoScalarTypes[words[0]]; ok {
+				field.Type = typ.Enum()
+			} else {
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+				field.TypeName = proto.String("." + file.GetPackage() + "." + words[0])
+			}
+			if optional {
+				field.Proto3Optional = proto.Bool(true)
+				field.OneofIndex = proto.Int32(int32(len(message.OneofDecl)))
+				message.OneofDecl = append(message.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + words[1])})
+			}
+			message.Field = append(message.Field, field)
+		}
+	}
+
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatalf("building the descriptor of %s: %v", path, err)
+	}
+	return fd
+}
+
+// protoFields returns the fields set on msg keyed by their JSON names, and
+// fails the test for fields the descriptor does not know, such as a number
+// or wire type out of sync with it.
+func protoFields(t *testing.T, msg protoreflect.Message) map[string]any {
+	t.Helper()
+	if unknown := msg.GetUnknown(); len(unknown) > 0 {
+		t.Errorf("%s holds %d bytes of fields optimization.proto does not declare", msg.Descriptor().FullName(), len(unknown))
+	}
+	fields := make(map[string]any)
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			items := make([]any, v.List().Len())
+			for i := range items {
+				if item := v.List().Get(i); fd.Message() != nil {
+					items[i] = protoFields(t, item.Message())
+				} else {
+					items[i] = item.Interface()
+				}
+			}
+			fields[fd.JSONName()] = items
+		case fd.Message() != nil:
+			fields[fd.JSONName()] = protoFields(t, v.Message())
+		default:
+			fields[fd.JSONName()] = v.Interface()
+		}
+		return true
+	})
+	return fields
+}
+
+// unmarshalResultProto decodes b as the OptimizationResult message of
+// optimization.proto.
+func unmarshalResultProto(t *testing.T, b []byte) *OptimizationResult {
+	t.Helper()
+	desc := loadProtoFile(t, "optimization.proto").Messages().ByName("OptimizationResult")
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(b, msg); err != nil {
+		t.Fatalf("decoding protobuf response: %v", err)
+	}
+
+	data, err := json.Marshal(protoFields(t, msg))
+	if err != nil {
+		t.Fatalf("encoding decoded fields: %v", err)
+	}
+	result := &OptimizationResult{Packs: []PackResult{}}
+	if err := json.Unmarshal(data, result); err != nil {
+		t.Fatalf("decoding fields %s: %v", data, err)
+	}
+	return result
+}
+
+func TestOptimizeHandlerProtobuf(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		body        string
+	}{
+		{"single pack", defaultPackSizes, `{"quantity": 1}`},
+		{"mixed packs", defaultPackSizes, `{"quantity": 501}`},
+		{"large order", defaultPackSizes, `{"quantity": 12001}`},
+		{"shortfall", defaultPackSizes, `{"quantity": 260, "policy": "allow-underfill"}`},
+		{"cost", []int{250, 500}, `{"quantity": 750, "costs": {"250": {"amount": 0.1, "currency": "USD"}, "500": {"amount": 0.25, "currency": "USD"}}}`},
+		{"zero cost", []int{250, 500}, `{"quantity": 750, "costs": {"250": 0, "500": 0}}`},
+		{"warnings", []int{250}, `{"quantity": 251}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, tc.sizes)
+
+			jsonRec := postOptimize(t, tc.body)
+			if jsonRec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize status = %d: %s", jsonRec.Code, jsonRec.Body.String())
+			}
+			var want OptimizationResult
+			if err := json.Unmarshal(jsonRec.Body.Bytes(), &want); err != nil {
+				t.Fatalf("decoding JSON response: %v", err)
+			}
+
+			protoReq := httptest.NewRequest(http.MethodPost, "/optimize", strings.NewReader(tc.body))
+			protoReq.Header.Set("Accept", protobufContentType)
+			protoRec := httptest.NewRecorder()
+			optimizeHandler(protoRec, protoReq)
+
+			if got := protoRec.Header().Get("Content-Type"); got != protobufContentType {
+				t.Errorf("Content-Type = %q, want %q", got, protobufContentType)
+			}
+			got := unmarshalResultProto(t, protoRec.Body.Bytes())
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("protobuf result = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
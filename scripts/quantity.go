@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fieldError is a request field validation failure whose message is safe to
+// return to the client as-is.
+type fieldError struct {
+	message string
+}
+
+func (e *fieldError) Error() string { return e.message }
+
+// quantityValue is an order quantity given either as a JSON integer or as a
+// string adding non-negative integers, such as "250+100+50" from a
+// spreadsheet formula. Only "+" is supported; nothing is evaluated.
+type quantityValue int
+
+func (q *quantityValue) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) == 0 || data[0] != '"' {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return &fieldError{"quantity must be an integer or a sum such as \"250+100\""}
+		}
+		*q = quantityValue(n)
+		return nil
+	}
+
+	var expr string
+	if err := json.Unmarshal(data, &expr); err != nil {
+		return err
+	}
+	n, err := parseQuantitySum(expr)
+	if err != nil {
+		return err
+	}
+	*q = quantityValue(n)
+	return nil
+}
+
+// parseQuantitySum evaluates an expression of non-negative integers joined by
+// "+", allowing spaces around terms.
+func parseQuantitySum(expr string) (int, error) {
+	total := 0
+	for _, term := range strings.Split(expr, "+") {
+		term = strings.TrimSpace(term)
+		if term == "" || strings.TrimLeft(term, "0123456789") != "" {
+			return 0, &fieldError{"quantity expression may only add non-negative integers, got " + strconv.Quote(expr)}
+		}
+		n, err := strconv.Atoi(term)
+		if err != nil || n > math.MaxInt-total {
+			return 0, &fieldError{"quantity expression is too large: " + strconv.Quote(expr)}
+		}
+		total += n
+	}
+	return total, nil
+}
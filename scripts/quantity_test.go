@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestOptimizeHandlerQuantityExpressions(t *testing.T) {
+	testCases := []struct {
+		body        string
+		wantStatus  int
+		wantOrder   int
+		description string
+	}{
+		{`{"quantity": "250+100"}`, http.StatusOK, 350, "Sum of two terms"},
+		{`{"quantity": " 250 + 100 + 50 "}`, http.StatusOK, 400, "Spaces around terms"},
+		{`{"quantity": "501"}`, http.StatusOK, 501, "Single term string"},
+		{`{"quantity": 501}`, http.StatusOK, 501, "Plain integer"},
+		{`{"quantity": "abc"}`, http.StatusBadRequest, 0, "Non-numeric"},
+		{`{"quantity": "250*2"}`, http.StatusBadRequest, 0, "Multiplication"},
+		{`{"quantity": "250-100"}`, http.StatusBadRequest, 0, "Subtraction"},
+		{`{"quantity": "250++100"}`, http.StatusBadRequest, 0, "Empty term"},
+		{`{"quantity": "-5"}`, http.StatusBadRequest, 0, "Negative term"},
+		{`{"quantity": "99999999999999999999"}`, http.StatusBadRequest, 0, "Overflow"},
+		{`{"quantity": 2.5}`, http.StatusBadRequest, 0, "Fractional number"},
+		{`{"quantity": "0+0"}`, http.StatusBadRequest, 0, "Sums to zero"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("POST /optimize %s status = %d, want %d (body %q)", tc.body, rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				if msg := errorMessage(t, rec); msg == "Invalid JSON" {
+					t.Errorf("POST /optimize %s error = %q, want a specific message", tc.body, msg)
+				}
+				return
+			}
+
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.OrderQuantity != tc.wantOrder {
+				t.Errorf("POST /optimize %s order quantity = %d, want %d", tc.body, result.OrderQuantity, tc.wantOrder)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "fmt"
+
+// ItemRange is the contiguous, 1-based, inclusive range of item indexes one
+// pack of a plan holds, for assigning serial numbers.
+type ItemRange struct {
+	PackSize   int `json:"packSize"`
+	StartIndex int `json:"startIndex"`
+	EndIndex   int `json:"endIndex"`
+}
+
+// maxRangePacks bounds the packs itemRanges lists. A single-size catalog is
+// solved by division, so its pack count is not bounded by the DP memory
+// ceiling.
+const maxRangePacks = 100_000
+
+// itemRanges numbers the items of result pack by pack, in the order of its
+// pack lines, so the ranges tile 1..TotalItems without gaps or overlaps.
+func itemRanges(result *OptimizationResult) ([]ItemRange, error) {
+	if result.TotalPacks > maxRangePacks {
+		return nil, fmt.Errorf("ranges lists packs one by one and supports at most %d packs, the result has %d", maxRangePacks, result.TotalPacks)
+	}
+	ranges := make([]ItemRange, 0, result.TotalPacks)
+	next := 1
+	for _, pack := range result.Packs {
+		for range pack.Quantity {
+			ranges = append(ranges, ItemRange{PackSize: pack.PackSize, StartIndex: next, EndIndex: next + pack.PackSize - 1})
+			next += pack.PackSize
+		}
+	}
+	return ranges, nil
+}
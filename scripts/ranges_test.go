@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestItemRanges(t *testing.T) {
+	for _, quantity := range []int{1, 251, 501, 12001} {
+		result, err := OptimizeCatalog(quantity, Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}, OptimizeOptions{})
+		if err != nil {
+			t.Fatalf("OptimizeCatalog(%d) returned error: %v", quantity, err)
+		}
+
+		ranges, err := itemRanges(result)
+		if err != nil {
+			t.Fatalf("itemRanges(%d) returned error: %v", quantity, err)
+		}
+		if len(ranges) != result.TotalPacks {
+			t.Fatalf("order %d: %d ranges for %d packs", quantity, len(ranges), result.TotalPacks)
+		}
+		next := 1
+		counts := make(map[int]int)
+		for _, rng := range ranges {
+			if rng.StartIndex != next || rng.EndIndex-rng.StartIndex+1 != rng.PackSize {
+				t.Fatalf("order %d: range %+v does not continue from item %d with its pack size", quantity, rng, next)
+			}
+			next = rng.EndIndex + 1
+			counts[rng.PackSize]++
+		}
+		if next-1 != result.TotalItems {
+			t.Errorf("order %d: ranges end at item %d, want %d", quantity, next-1, result.TotalItems)
+		}
+		for _, pack := range result.Packs {
+			if counts[pack.PackSize] != pack.Quantity {
+				t.Errorf("order %d: %d ranges of size %d, want %d", quantity, counts[pack.PackSize], pack.PackSize, pack.Quantity)
+			}
+		}
+	}
+}
+
+func TestOptimizeRanges(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?ranges=true", strings.NewReader(`{"quantity": 501}`))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize?ranges=true status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	want := []ItemRange{{PackSize: 500, StartIndex: 1, EndIndex: 500}, {PackSize: 250, StartIndex: 501, EndIndex: 750}}
+	if !reflect.DeepEqual(result.Ranges, want) {
+		t.Errorf("ranges = %+v, want %+v", result.Ranges, want)
+	}
+}
+
+func TestOptimizeRangesPackLimit(t *testing.T) {
+	withPackSizes(t, []int{1})
+
+	for _, tc := range []struct {
+		quantity int
+		want     int
+	}{
+		{maxRangePacks, http.StatusOK},
+		{maxRangePacks + 1, http.StatusBadRequest},
+		{300_000_000, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/optimize?ranges=true", strings.NewReader(fmt.Sprintf(`{"quantity": %d}`, tc.quantity)))
+		rec := httptest.NewRecorder()
+		optimizeHandler(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("POST /optimize?ranges=true for %d packs status = %d, want %d", tc.quantity, rec.Code, tc.want)
+		}
+	}
+}
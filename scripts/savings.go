@@ -0,0 +1,39 @@
+package main
+
+// Savings compares a result with the naive approach of shipping the order in
+// the largest pack size alone. Positive values favor the optimizer; a value
+// may be negative when the optimizer trades packs for less waste.
+type Savings struct {
+	NaivePacks   int      `json:"naivePacks"`
+	NaiveWaste   int      `json:"naiveWaste"`
+	WasteAvoided int      `json:"wasteAvoided"`
+	PacksSaved   int      `json:"packsSaved"`
+	CostSaved    *float64 `json:"costSaved,omitempty"`
+}
+
+// naiveSavings compares result with filling its order quantity using only the
+// largest size of catalog. CostSaved is set when the result is priced with
+// costs.
+func naiveSavings(result *OptimizationResult, catalog Catalog, costs map[int]float64) *Savings {
+	largest := 0
+	for _, size := range catalog.PackSizes {
+		largest = max(largest, size)
+	}
+	if largest == 0 {
+		return nil
+	}
+
+	naivePacks := (result.OrderQuantity + largest - 1) / largest
+	naiveWaste := naivePacks*largest - result.OrderQuantity
+	savings := &Savings{
+		NaivePacks:   naivePacks,
+		NaiveWaste:   naiveWaste,
+		WasteAvoided: naiveWaste - result.Waste,
+		PacksSaved:   naivePacks - result.TotalPacks,
+	}
+	if price, ok := costs[largest]; ok && result.TotalCost != nil {
+		saved := roundCost(float64(naivePacks)*price-*result.TotalCost, result.Currency)
+		savings.CostSaved = &saved
+	}
+	return savings
+}
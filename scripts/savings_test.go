@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNaiveSavings(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	costs := map[int]float64{250: 1, 500: 1.8, 1000: 3.5, 2000: 6, 5000: 14}
+	costSaved := func(v float64) *float64 { return &v }
+
+	testCases := []struct {
+		description string
+		quantity    int
+		costs       map[int]float64
+		want        Savings
+	}{
+		{"small order avoids most waste", 501, nil, Savings{NaivePacks: 1, NaiveWaste: 4499, WasteAvoided: 4250, PacksSaved: -1}},
+		{"large order", 12001, nil, Savings{NaivePacks: 3, NaiveWaste: 2999, WasteAvoided: 2750, PacksSaved: -1}},
+		{"naive already optimal", 10000, nil, Savings{NaivePacks: 2, NaiveWaste: 0, WasteAvoided: 0, PacksSaved: 0}},
+		{"priced", 501, costs, Savings{NaivePacks: 1, NaiveWaste: 4499, WasteAvoided: 4250, PacksSaved: -1, CostSaved: costSaved(11.2)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, catalog, OptimizeOptions{Costs: tc.costs})
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			got := naiveSavings(result, catalog, tc.costs)
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Errorf("naiveSavings(%d) = %+v, want %+v", tc.quantity, *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerCompareNaive(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 501, "compareNaive": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Savings == nil || result.Savings.WasteAvoided != 4250 {
+		t.Errorf("POST /optimize savings = %+v, want 4250 waste avoided", result.Savings)
+	}
+
+	rec = postOptimize(t, `{"quantity": 501}`)
+	result = OptimizationResult{}
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Savings != nil {
+		t.Errorf("POST /optimize without compareNaive returned savings %+v", result.Savings)
+	}
+}
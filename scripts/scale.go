@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// scaleCatalog returns catalog with every pack size multiplied by factor,
+// for trying out a uniform change of box sizes without storing it. Labels
+// and the per-size waste caps of its defaults follow their sizes. Every
+// scaled size must be a whole number, and the scaled catalog must pass
+// validateCatalog.
+func scaleCatalog(catalog Catalog, factor float64) (Catalog, error) {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return Catalog{}, fmt.Errorf("scaleSizes must be a positive number")
+	}
+
+	scaled := Catalog{
+		PackSizes: make([]int, len(catalog.PackSizes)),
+		Labels:    make(map[int]string, len(catalog.Labels)),
+	}
+	resized := make(map[int]int, len(catalog.PackSizes))
+	for i, size := range catalog.PackSizes {
+		value := float64(size) * factor
+		rounded := math.Round(value)
+		if math.Abs(value-rounded) > 1e-9*max(1, value) {
+			return Catalog{}, fmt.Errorf("scaleSizes %g turns pack size %d into %g, which is not a whole number", factor, size, value)
+		}
+		if rounded+1 > float64(maxDPSize) {
+			return Catalog{}, fmt.Errorf("scaleSizes %g turns pack size %d into %.0f, beyond the memory ceiling of %d items", factor, size, rounded, maxDPSize)
+		}
+		scaled.PackSizes[i] = int(rounded)
+		resized[size] = int(rounded)
+		if label, ok := catalog.Labels[size]; ok {
+			scaled.Labels[int(rounded)] = label
+		}
+	}
+	if catalog.Defaults != nil {
+		defaults := *catalog.Defaults
+		if caps := defaults.MaxWastePerSize; caps != nil {
+			defaults.MaxWastePerSize = make(map[int]int, len(caps))
+			for size, limit := range caps {
+				defaults.MaxWastePerSize[resized[size]] = limit
+			}
+		}
+		scaled.Defaults = &defaults
+	}
+	if err := validateCatalog(scaled); err != nil {
+		return Catalog{}, err
+	}
+	return scaled, nil
+}
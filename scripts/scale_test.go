@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestScaleCatalog(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}, Labels: map[int]string{500: "Medium"}}
+
+	testCases := []struct {
+		description string
+		factor      float64
+		want        []int
+		wantErr     bool
+	}{
+		{"doubled", 2, []int{500, 1000, 2000}, false},
+		{"fractional factor with whole sizes", 1.5, []int{375, 750, 1500}, false},
+		{"shrunk", 0.2, []int{50, 100, 200}, false},
+		{"fractional sizes", 1.001, nil, true},
+		{"scaled below one item", 0.001, nil, true},
+		{"beyond the memory ceiling", float64(maxDPSize), nil, true},
+		{"negative factor", -2, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			scaled, err := scaleCatalog(catalog, tc.factor)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("scaleCatalog(%g) = %v, want an error", tc.factor, scaled.PackSizes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scaleCatalog(%g) returned error: %v", tc.factor, err)
+			}
+			if !reflect.DeepEqual(scaled.PackSizes, tc.want) {
+				t.Errorf("scaleCatalog(%g) = %v, want %v", tc.factor, scaled.PackSizes, tc.want)
+			}
+			if label := scaled.Labels[tc.want[1]]; label != "Medium" {
+				t.Errorf("label of scaled size %d = %q, want %q", tc.want[1], label, "Medium")
+			}
+		})
+	}
+}
+
+func TestOptimizeScaleSizes(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 1200, "scaleSizes": 2}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	// Doubled, the catalog is 500, 1000, 2000, 4000 and 10000, so 1200 needs
+	// 1000 + 500 where the stored catalog ships 1000 + 250.
+	want := []PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 500, Quantity: 1}}
+	if !reflect.DeepEqual(result.Packs, want) || result.TotalItems != 1500 {
+		t.Errorf("packs = %+v (%d items), want %+v (1500 items)", result.Packs, result.TotalItems, want)
+	}
+	if !reflect.DeepEqual(PackSizes, []int{250, 500, 1000, 2000, 5000}) {
+		t.Errorf("PackSizes = %v after a scaled optimize, want the stored sizes", PackSizes)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 1200, "scaleSizes": 0.3333}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("non-integer scaled sizes status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
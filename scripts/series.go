@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxSeriesCount bounds the sizes one pack series may expand to.
+const maxSeriesCount = 64
+
+// PackSeries defines a geometric catalog compactly: Count sizes starting at
+// Base, each Ratio times the one before, e.g. 250, 500, 1000, 2000 for base
+// 250, ratio 2 and count 4.
+type PackSeries struct {
+	Base  int     `json:"base"`
+	Ratio float64 `json:"ratio"`
+	Count int     `json:"count"`
+}
+
+// expand returns the sizes of s in series order. Every size must be a whole
+// number within the memory ceiling and the sizes must be unique, so a ratio
+// of 1 or one that rounds two terms together is rejected.
+func (s PackSeries) expand() ([]int, error) {
+	switch {
+	case s.Base <= 0:
+		return nil, fmt.Errorf("series base must be a positive integer")
+	case s.Ratio <= 0 || math.IsNaN(s.Ratio) || math.IsInf(s.Ratio, 0):
+		return nil, fmt.Errorf("series ratio must be positive")
+	case s.Count <= 0 || s.Count > maxSeriesCount:
+		return nil, fmt.Errorf("series count must be between 1 and %d", maxSeriesCount)
+	}
+
+	sizes := make([]int, s.Count)
+	seen := make(map[int]bool, s.Count)
+	for i := range sizes {
+		value := float64(s.Base) * math.Pow(s.Ratio, float64(i))
+		rounded := math.Round(value)
+		if math.Abs(value-rounded) > 1e-9*max(1, value) || rounded < 1 {
+			return nil, fmt.Errorf("series term %d is %g, which is not a positive whole number", i+1, value)
+		}
+		if rounded+1 > float64(maxDPSize) {
+			return nil, fmt.Errorf("series term %d is %.0f, beyond the memory ceiling of %d items", i+1, rounded, maxDPSize)
+		}
+		size := int(rounded)
+		if seen[size] {
+			return nil, fmt.Errorf("series term %d repeats pack size %d; pack sizes must be unique", i+1, size)
+		}
+		seen[size] = true
+		sizes[i] = size
+	}
+	return sizes, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPackSeriesExpand(t *testing.T) {
+	testCases := []struct {
+		series PackSeries
+		want   []int
+	}{
+		{PackSeries{Base: 250, Ratio: 2, Count: 4}, []int{250, 500, 1000, 2000}},
+		{PackSeries{Base: 100, Ratio: 1.5, Count: 3}, []int{100, 150, 225}},
+		{PackSeries{Base: 1000, Ratio: 0.5, Count: 3}, []int{1000, 500, 250}},
+		{PackSeries{Base: 7, Ratio: 3, Count: 1}, []int{7}},
+	}
+
+	for _, tc := range testCases {
+		got, err := tc.series.expand()
+		if err != nil {
+			t.Errorf("%+v.expand() returned error: %v", tc.series, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%+v.expand() = %v, want %v", tc.series, got, tc.want)
+		}
+	}
+}
+
+func TestPackSeriesExpandInvalid(t *testing.T) {
+	for _, series := range []PackSeries{
+		{Base: 0, Ratio: 2, Count: 4},
+		{Base: 250, Ratio: 0, Count: 4},
+		{Base: 250, Ratio: 2, Count: 0},
+		{Base: 250, Ratio: 2, Count: maxSeriesCount + 1},
+		{Base: 250, Ratio: 1, Count: 2},
+		{Base: 250, Ratio: 1.5, Count: 3},
+		{Base: 3, Ratio: 0.5, Count: 2},
+		{Base: 250, Ratio: 10, Count: 20},
+	} {
+		if sizes, err := series.expand(); err == nil {
+			t.Errorf("%+v.expand() = %v, want an error", series, sizes)
+		}
+	}
+}
+
+func TestPackageHandlerSeries(t *testing.T) {
+	withPackSizes(t, []int{500})
+
+	if rec := postPackages(t, `{"base": 250, "ratio": 2, "count": 4}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /packages series status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if want := []int{250, 500, 1000, 2000}; !reflect.DeepEqual(PackSizes, want) {
+		t.Errorf("PackSizes = %v, want %v", PackSizes, want)
+	}
+
+	for _, body := range []string{
+		`{"base": 250, "ratio": 1, "count": 4}`,
+		`{"base": 250, "ratio": 2, "count": 4, "packSizes": [250]}`,
+	} {
+		if rec := postPackages(t, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /packages %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if want := []int{250, 500, 1000, 2000}; !reflect.DeepEqual(PackSizes, want) {
+		t.Errorf("rejected series changed PackSizes to %v", PackSizes)
+	}
+}
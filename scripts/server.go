@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// serverTuning holds the limits of the HTTP server. Zero durations disable
+// the timeout, as on http.Server.
+type serverTuning struct {
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+}
+
+// httpTuning is the server tuning read from the environment. The write
+// timeout leaves room for REQUEST_TIMEOUT to answer slow requests with 503
+// before the connection is cut.
+var httpTuning = serverTuning{
+	ReadTimeout:    envDuration("READ_TIMEOUT", 15*time.Second),
+	WriteTimeout:   envDuration("WRITE_TIMEOUT", 30*time.Second),
+	IdleTimeout:    envDuration("IDLE_TIMEOUT", 120*time.Second),
+	MaxHeaderBytes: envInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes),
+}
+
+// validate checks that no limit is negative.
+func (t serverTuning) validate() error {
+	for name, d := range map[string]time.Duration{"READ_TIMEOUT": t.ReadTimeout, "WRITE_TIMEOUT": t.WriteTimeout, "IDLE_TIMEOUT": t.IdleTimeout} {
+		if d < 0 {
+			return fmt.Errorf("invalid %s %s, want a non-negative duration", name, d)
+		}
+	}
+	if t.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("invalid MAX_HEADER_BYTES %d, want a positive integer", t.MaxHeaderBytes)
+	}
+	return nil
+}
+
+// newServer returns the server listening on addr with handler, configured
+// by tuning. Besides HTTP/1.1 it speaks HTTP/2, over TLS when served with
+// a certificate and unencrypted (h2c, with prior knowledge) otherwise, so
+// dashboards can multiplex many optimize calls over one connection. Every
+// handler is safe to run concurrently: catalogs are read under catalogStore's
+// lock and each optimization works on its own copy.
+func newServer(addr string, handler http.Handler, tuning serverTuning) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &http.Server{
+		Addr:           addr,
+		Handler:        handler,
+		ReadTimeout:    tuning.ReadTimeout,
+		WriteTimeout:   tuning.WriteTimeout,
+		IdleTimeout:    tuning.IdleTimeout,
+		MaxHeaderBytes: tuning.MaxHeaderBytes,
+		Protocols:      protocols,
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewServer(t *testing.T) {
+	handler := http.NewServeMux()
+	tuning := serverTuning{
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   20 * time.Second,
+		IdleTimeout:    time.Minute,
+		MaxHeaderBytes: 8 << 10,
+	}
+
+	server := newServer(":9090", handler, tuning)
+
+	if server.Addr != ":9090" || server.Handler != handler {
+		t.Errorf("server = %q with handler %v, want :9090 with the given handler", server.Addr, server.Handler)
+	}
+	if server.ReadTimeout != 5*time.Second || server.WriteTimeout != 20*time.Second || server.IdleTimeout != time.Minute {
+		t.Errorf("timeouts = read %s, write %s, idle %s; want 5s, 20s, 1m0s", server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+	}
+	if server.MaxHeaderBytes != 8<<10 {
+		t.Errorf("MaxHeaderBytes = %d, want %d", server.MaxHeaderBytes, 8<<10)
+	}
+}
+
+func TestServerTuningDefaults(t *testing.T) {
+	if err := httpTuning.validate(); err != nil {
+		t.Fatalf("default tuning is invalid: %v", err)
+	}
+	if httpTuning.WriteTimeout != 0 && httpTuning.WriteTimeout <= requestTimeout {
+		t.Errorf("WriteTimeout %s does not exceed REQUEST_TIMEOUT %s", httpTuning.WriteTimeout, requestTimeout)
+	}
+
+	for _, tuning := range []serverTuning{
+		{ReadTimeout: -time.Second, MaxHeaderBytes: 1},
+		{IdleTimeout: -time.Second, MaxHeaderBytes: 1},
+		{MaxHeaderBytes: 0},
+	} {
+		if err := tuning.validate(); err == nil {
+			t.Errorf("validate(%+v) returned no error", tuning)
+		}
+	}
+}
+
+func TestConcurrentOptimizeOverHTTP2(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config = newServer("", newRouter(), httpTuning)
+	ts.Start()
+	defer ts.Close()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	defer client.CloseIdleConnections()
+
+	// Stay below MAX_CONCURRENT_OPTIMIZATIONS so no request is shed.
+	const requests = 32
+	want := map[int]int{1: 250, 251: 500, 501: 750, 12001: 12250}
+	quantities := []int{1, 251, 501, 12001}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := range requests {
+		wg.Add(1)
+		go func(quantity int) {
+			defer wg.Done()
+			body := strings.NewReader(fmt.Sprintf(`{"quantity": %d}`, quantity))
+			resp, err := client.Post(ts.URL+"/optimize", "application/json", body)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer resp.Body.Close()
+			if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK {
+				errs <- fmt.Errorf("quantity %d: %s status %d", quantity, resp.Proto, resp.StatusCode)
+				return
+			}
+			var result OptimizationResult
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				errs <- err
+				return
+			}
+			if result.TotalItems != want[quantity] {
+				errs <- fmt.Errorf("quantity %d: totalItems = %d, want %d", quantity, result.TotalItems, want[quantity])
+			}
+		}(quantities[i%len(quantities)])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
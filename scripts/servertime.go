@@ -0,0 +1,9 @@
+package main
+
+import "time"
+
+// serverTime is the current time in UTC as RFC 3339, for clients correlating
+// their clock with the server's logs.
+func serverTime() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptimizeIncludeTime(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?includeTime=true", strings.NewReader(`{"quantity": 251}`))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize?includeTime=true status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	stamp, err := time.Parse(time.RFC3339, result.ServerTime)
+	if err != nil {
+		t.Fatalf("serverTime %q is not RFC 3339: %v", result.ServerTime, err)
+	}
+	if _, offset := stamp.Zone(); offset != 0 {
+		t.Errorf("serverTime %q is not UTC", result.ServerTime)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 251}`); strings.Contains(rec.Body.String(), "serverTime") {
+		t.Errorf("POST /optimize body = %s, want no serverTime by default", rec.Body.String())
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// shippedItems totals the items in the packs of a partial shipment. Any
+// size counts, whether or not the catalog still holds it.
+func shippedItems(packs []PackResult) (int, error) {
+	shipped := 0
+	for i, pack := range packs {
+		if pack.PackSize <= 0 || pack.Quantity <= 0 {
+			return 0, fmt.Errorf("alreadyShipped[%d] must have a positive packSize and quantity", i)
+		}
+		if pack.Quantity > (math.MaxInt-shipped)/pack.PackSize {
+			return 0, fmt.Errorf("alreadyShipped is too large")
+		}
+		shipped += pack.PackSize * pack.Quantity
+	}
+	return shipped, nil
+}
+
+// noteShipment records on result, the plan for what is left of an order of
+// orderQuantity, the shipped items that were subtracted, and warns when the
+// shipment already exceeded the order.
+func noteShipment(result *OptimizationResult, orderQuantity, shipped int) {
+	result.AlreadyShipped = shipped
+	if over := shipped - orderQuantity; over > 0 {
+		result.OverShipped = over
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Already shipped %d items, %d more than the order of %d; nothing is left to ship", shipped, over, orderQuantity))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAlreadyShipped(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	testCases := []struct {
+		description     string
+		body            string
+		wantOrder       int
+		wantPacks       []PackResult
+		wantShipped     int
+		wantOverShipped int
+	}{
+		{"partial shipment", `{"quantity": 12001, "alreadyShipped": [{"packSize": 5000, "quantity": 2}]}`,
+			2001, []PackResult{{PackSize: 2000, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 10000, 0},
+		{"shipped in a retired size", `{"quantity": 1000, "alreadyShipped": [{"packSize": 300, "quantity": 1}, {"packSize": 200, "quantity": 1}]}`,
+			500, []PackResult{{PackSize: 500, Quantity: 1}}, 500, 0},
+		{"shipped in full", `{"quantity": 750, "alreadyShipped": [{"packSize": 500, "quantity": 1}, {"packSize": 250, "quantity": 1}]}`,
+			0, []PackResult{}, 750, 0},
+		{"over-shipped", `{"quantity": 501, "alreadyShipped": [{"packSize": 1000, "quantity": 1}]}`,
+			0, []PackResult{}, 1000, 499},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var result OptimizationResult
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.OrderQuantity != tc.wantOrder || !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("result = order %d, packs %+v; want order %d, packs %+v", result.OrderQuantity, result.Packs, tc.wantOrder, tc.wantPacks)
+			}
+			if result.AlreadyShipped != tc.wantShipped || result.OverShipped != tc.wantOverShipped {
+				t.Errorf("alreadyShipped = %d, overShipped = %d; want %d and %d", result.AlreadyShipped, result.OverShipped, tc.wantShipped, tc.wantOverShipped)
+			}
+			if (tc.wantOverShipped > 0) != (len(result.Warnings) > 0) {
+				t.Errorf("warnings = %q, want one only for an over-shipment", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestAlreadyShippedRejectsBadPacks(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	for _, body := range []string{
+		`{"quantity": 1000, "alreadyShipped": [{"packSize": 0, "quantity": 1}]}`,
+		`{"quantity": 1000, "alreadyShipped": [{"packSize": 250, "quantity": -1}]}`,
+		`{"quantity": 1000, "alreadyShipped": [{"packSize": 4611686018427387904, "quantity": 4}]}`,
+		`{"minQuantity": 1000, "maxQuantity": 1200, "alreadyShipped": [{"packSize": 250, "quantity": 1}]}`,
+	} {
+		if rec := postOptimize(t, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithSignature(t *testing.T) {
+	const secret = "s3cret"
+	const body = `{"quantity": 1}`
+	valid := hex.EncodeToString(signBody([]byte(secret), []byte(body)))
+	forged := hex.EncodeToString(signBody([]byte("other"), []byte(body)))
+
+	testCases := []struct {
+		description string
+		secret      string
+		method      string
+		signature   string
+		wantStatus  int
+	}{
+		{"valid signature", secret, http.MethodPost, valid, http.StatusOK},
+		{"valid prefixed signature", secret, http.MethodPost, "sha256=" + valid, http.StatusOK},
+		{"wrong secret", secret, http.MethodPost, forged, http.StatusUnauthorized},
+		{"not hex", secret, http.MethodPost, "zz", http.StatusUnauthorized},
+		{"missing", secret, http.MethodPost, "", http.StatusUnauthorized},
+		{"bodyless method is not checked", secret, http.MethodGet, "", http.StatusOK},
+		{"disabled without a secret", "", http.MethodPost, "", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var seen string
+			handler := withSignature(tc.secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading body: %v", err)
+				}
+				seen = string(got)
+			}))
+
+			req := httptest.NewRequest(tc.method, "/optimize", strings.NewReader(body))
+			if tc.signature != "" {
+				req.Header.Set(signatureHeader, tc.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantStatus == http.StatusOK && tc.method == http.MethodPost && seen != body {
+				t.Errorf("handler saw body %q, want %q", seen, body)
+			}
+		})
+	}
+}
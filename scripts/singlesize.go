@@ -0,0 +1,42 @@
+package main
+
+import "fmt"
+
+// needsPackTable reports whether solving against sizes with opts needs a DP
+// pack table. The cheapest mode runs its own cost table, and a catalog of a
+// single size is answered by division, since its reachable totals are
+// exactly the multiples of that size, unless a pack limit needs the table's
+// pack counts. Without a table the maxDPSize ceiling does not apply, so such
+// orders may hold any number of packs; views that list packs one by one,
+// the packing tree and item ranges, cap the count themselves.
+func needsPackTable(sizes []int, opts OptimizeOptions) bool {
+	return !perPackMode(opts.Mode) && (len(sizes) > 1 || opts.MaxPacks > 0)
+}
+
+// singleSizeAmount is selectAmount for a catalog holding only size: the
+// multiple of size shipped for orderQuantity under policy and tiebreak, or -1
+// when the policy admits none.
+func singleSizeAmount(orderQuantity, size int, policy, tiebreak string) int {
+	under := orderQuantity / size * size
+	if under == orderQuantity {
+		return orderQuantity
+	}
+	over := under + size
+
+	switch policy {
+	case PolicyExact:
+		return -1
+	case PolicyAllowUnderfill:
+		// The underfilled total always needs fewer packs.
+		if under > 0 && (orderQuantity-under < over-orderQuantity ||
+			orderQuantity-under == over-orderQuantity && tiebreak != TiebreakOver) {
+			return under
+		}
+	}
+	return over
+}
+
+// singleSizeWarning notes the granularity of a catalog with one size.
+func singleSizeWarning(size int) string {
+	return fmt.Sprintf("The catalog has a single pack size, %d: every order is rounded up to a multiple of %d", size, size)
+}
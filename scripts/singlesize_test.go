@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// solveWithFullTable solves like solve but always builds the DP table, as
+// multi-size catalogs do.
+func solveWithFullTable(orderQuantity int, sizes []int, opts OptimizeOptions) (*OptimizationResult, error) {
+	maxSize, err := searchWindow(orderQuantity, sizes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return solveWithTable(orderQuantity, sizes, opts, buildPackTable(sizes, maxSize))
+}
+
+func TestSingleSizeMatchesFullTable(t *testing.T) {
+	sizes := []int{250}
+	var optionSets []OptimizeOptions
+	for _, policy := range supportedPolicies {
+		for _, tiebreak := range supportedTiebreaks {
+			optionSets = append(optionSets, OptimizeOptions{Policy: policy, UnderfillTiebreak: tiebreak})
+		}
+	}
+	optionSets = append(optionSets,
+		OptimizeOptions{Mode: ModeFewestLines},
+		OptimizeOptions{PenalizeSmallPacks: true},
+		OptimizeOptions{Weights: &ScoreWeights{Waste: 1, Packs: 100}},
+		OptimizeOptions{MaxQuantity: 600},
+		OptimizeOptions{MaxWastePerSize: map[int]int{250: 100}},
+		OptimizeOptions{SpreadTies: true, Seed: 3},
+	)
+
+	for _, opts := range optionSets {
+		for _, quantity := range []int{1, 124, 125, 126, 249, 250, 251, 375, 500, 501, 12001} {
+			t.Run(fmt.Sprintf("%+v/%d", opts, quantity), func(t *testing.T) {
+				got, gotErr := solve(quantity, sizes, opts)
+				want, wantErr := solveWithFullTable(quantity, sizes, opts)
+				if (gotErr == nil) != (wantErr == nil) {
+					t.Fatalf("solve(%d) error = %v, full table error = %v", quantity, gotErr, wantErr)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("solve(%d) = %+v, full table = %+v", quantity, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestSingleSizeCeilDivision(t *testing.T) {
+	testCases := []struct {
+		quantity  int
+		wantPacks int
+	}{
+		{1, 1},
+		{250, 1},
+		{251, 2},
+		{100_000_000, 400_000},
+		{100_000_001, 400_001},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprint(tc.quantity), func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, Catalog{PackSizes: []int{250}}, OptimizeOptions{})
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if result.TotalPacks != tc.wantPacks || result.TotalItems != tc.wantPacks*250 {
+				t.Errorf("OptimizeCatalog(%d) = %d packs, %d items, want %d packs", tc.quantity, result.TotalPacks, result.TotalItems, tc.wantPacks)
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "single pack size") {
+				t.Errorf("OptimizeCatalog(%d) warnings = %v, want the single-size warning", tc.quantity, result.Warnings)
+			}
+		})
+	}
+
+	result, err := OptimizeCatalog(501, Catalog{PackSizes: []int{250, 500}}, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(501) returned error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("two-size catalog warnings = %v, want none", result.Warnings)
+	}
+}
+
+func BenchmarkSingleSizeFastPath(b *testing.B) {
+	sizes := []int{250}
+	for i := 0; i < b.N; i++ {
+		solve(500_001, sizes, OptimizeOptions{})
+	}
+}
+
+func BenchmarkSingleSizeFullTable(b *testing.B) {
+	sizes := []int{250}
+	for i := 0; i < b.N; i++ {
+		solveWithFullTable(500_001, sizes, OptimizeOptions{})
+	}
+}
+
+func TestSingleSizeRejectsOverflow(t *testing.T) {
+	_, err := OptimizeCatalog(math.MaxInt-1, Catalog{PackSizes: []int{250}}, OptimizeOptions{})
+	if !errors.Is(err, ErrWindowTooLarge) {
+		t.Errorf("OptimizeCatalog(MaxInt-1) error = %v, want %v", err, ErrWindowTooLarge)
+	}
+
+	result, err := OptimizeCatalog(math.MaxInt-250, Catalog{PackSizes: []int{250}}, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(MaxInt-250) returned error: %v", err)
+	}
+	if result.TotalItems < result.OrderQuantity || result.Shortfall != 0 {
+		t.Errorf("OptimizeCatalog(MaxInt-250) = %d items, shortfall %d; want the order covered", result.TotalItems, result.Shortfall)
+	}
+}
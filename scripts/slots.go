@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BindingMaxSlots is the BindingConstraint of a result moved away from the
+// unconstrained optimum to fit the pallet.
+const BindingMaxSlots = "maxSlots"
+
+// validateSlots checks that opts gives every size a positive slot count when
+// slots are given or required by MaxSlots.
+func validateSlots(opts OptimizeOptions, sizes []int) error {
+	if opts.MaxSlots < 0 {
+		return fmt.Errorf("maxSlots must not be negative")
+	}
+	if opts.MaxSlots > 0 {
+		if opts.Slots == nil {
+			return fmt.Errorf("maxSlots requires slots")
+		}
+		if perPackMode(opts.Mode) {
+			return fmt.Errorf("maxSlots is not supported with mode %q", opts.Mode)
+		}
+		if opts.MaxPacks > 0 || opts.MaxWastePerSize != nil {
+			return fmt.Errorf("maxSlots cannot be combined with maxPacks or maxWastePerSize")
+		}
+	}
+	if opts.Slots == nil {
+		return nil
+	}
+
+	known := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		known[size] = true
+		slots, ok := opts.Slots[size]
+		if !ok {
+			return fmt.Errorf("missing slots for pack size %d", size)
+		}
+		if slots <= 0 {
+			return fmt.Errorf("slots for pack size %d must be a positive integer", size)
+		}
+	}
+	for size := range opts.Slots {
+		if !known[size] {
+			return fmt.Errorf("slots given for unknown pack size %d", size)
+		}
+	}
+	return nil
+}
+
+// breakdownSlots is the number of pallet slots a breakdown occupies.
+func breakdownSlots(counts map[int]int, slots map[int]int) int {
+	total := 0
+	for size, qty := range counts {
+		total += qty * slots[size]
+	}
+	return total
+}
+
+// slottedBreakdown finds the least total in [lo, hi] that some breakdown
+// packs into at most maxSlots slots, and the breakdown of it using the
+// fewest slots, then the fewest packs. It returns -1 when none fits, with
+// the fewest slots any total in the range needs. sizes must be sorted in
+// descending order and hi must not exceed maxSize.
+func slottedBreakdown(sizes []int, lo, hi, maxSize int, slots map[int]int, maxSlots int) (int, map[int]int, int) {
+	type slotEntry struct {
+		slots int
+		packs int
+		pack  int
+	}
+
+	dp := make([]slotEntry, maxSize+1)
+	for i := range dp {
+		dp[i].slots = math.MaxInt32
+	}
+	dp[0] = slotEntry{}
+
+	for i := 0; i <= maxSize; i++ {
+		if dp[i].slots == math.MaxInt32 {
+			continue
+		}
+		for _, pack := range sizes {
+			next := i + pack
+			if next > maxSize {
+				continue
+			}
+			candidate := slotEntry{slots: dp[i].slots + slots[pack], packs: dp[i].packs + 1, pack: pack}
+			if candidate.slots < dp[next].slots ||
+				(candidate.slots == dp[next].slots && candidate.packs < dp[next].packs) {
+				dp[next] = candidate
+			}
+		}
+	}
+
+	fewest := math.MaxInt32
+	for i := lo; i <= hi; i++ {
+		if dp[i].slots > maxSlots {
+			fewest = min(fewest, dp[i].slots)
+			continue
+		}
+		counts := make(map[int]int)
+		for cur := i; cur > 0; cur -= dp[cur].pack {
+			counts[dp[cur].pack]++
+		}
+		return i, counts, dp[i].slots
+	}
+	return -1, nil, fewest
+}
+
+// palletsNeeded is the least number of pallets of maxSlots slots that
+// could hold slots slots. Packs cannot be split, so more may be needed.
+func palletsNeeded(slots, maxSlots int) int {
+	return (slots + maxSlots - 1) / maxSlots
+}
+
+// overflowsPallet explains an order no breakdown fits into one pallet,
+// with the pallets its fewest-slot breakdown needs at least; fewest is
+// math.MaxInt32 when no total in the window is reachable at all.
+func overflowsPallet(orderQuantity, fewest, maxSlots int) error {
+	if fewest == math.MaxInt32 {
+		return fmt.Errorf("%w: order %d cannot be filled exactly", ErrInfeasible, orderQuantity)
+	}
+	return fmt.Errorf("%w: order %d needs at least %d slots and does not fit in one pallet of %d slots; it needs at least %d pallets",
+		ErrInfeasible, orderQuantity, fewest, maxSlots, palletsNeeded(fewest, maxSlots))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxSlots(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}}
+	slots := map[int]int{250: 1, 500: 3, 1000: 4}
+
+	testCases := []struct {
+		description string
+		catalog     Catalog
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+		wantSlots   int
+		wantBinding string
+	}{
+		{"slots reported", catalog, 500, OptimizeOptions{Slots: slots},
+			[]PackResult{{PackSize: 500, Quantity: 1}}, 3, ""},
+		{"pallet forces smaller packs", catalog, 500, OptimizeOptions{Slots: slots, MaxSlots: 2},
+			[]PackResult{{PackSize: 250, Quantity: 2}}, 2, BindingMaxSlots},
+		{"optimum fits", catalog, 1500, OptimizeOptions{Slots: slots, MaxSlots: 7},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 500, Quantity: 1}}, 7, BindingNone},
+		{"fewest-slot mix, then fewest packs", catalog, 1500, OptimizeOptions{Slots: slots, MaxSlots: 6},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 250, Quantity: 2}}, 6, BindingMaxSlots},
+		{"pallet forces waste", Catalog{PackSizes: []int{250, 1000}}, 750, OptimizeOptions{Slots: map[int]int{250: 2, 1000: 3}, MaxSlots: 3},
+			[]PackResult{{PackSize: 1000, Quantity: 1}}, 3, BindingMaxSlots},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, tc.catalog, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.TotalSlots != tc.wantSlots {
+				t.Errorf("totalSlots = %d, want %d", result.TotalSlots, tc.wantSlots)
+			}
+			if result.BindingConstraint != tc.wantBinding {
+				t.Errorf("bindingConstraint = %q, want %q", result.BindingConstraint, tc.wantBinding)
+			}
+		})
+	}
+}
+
+func TestMaxSlotsInfeasibleAndInvalid(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500}}
+	slots := map[int]int{250: 1, 500: 2}
+
+	testCases := []struct {
+		description string
+		opts        OptimizeOptions
+		infeasible  bool
+	}{
+		{"order overflows the pallet", OptimizeOptions{Slots: slots, MaxSlots: 3}, true},
+		{"maxSlots without slots", OptimizeOptions{MaxSlots: 3}, false},
+		{"missing size", OptimizeOptions{Slots: map[int]int{250: 1}}, false},
+		{"unknown size", OptimizeOptions{Slots: map[int]int{250: 1, 500: 2, 1000: 4}}, false},
+		{"zero slots", OptimizeOptions{Slots: map[int]int{250: 0, 500: 2}}, false},
+		{"negative maxSlots", OptimizeOptions{Slots: slots, MaxSlots: -1}, false},
+		{"with maxPacks", OptimizeOptions{Slots: slots, MaxSlots: 3, MaxPacks: 2}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := OptimizeCatalog(2000, catalog, tc.opts)
+			if err == nil {
+				t.Fatalf("OptimizeCatalog(2000) returned no error")
+			}
+			if errors.Is(err, ErrInfeasible) != tc.infeasible {
+				t.Errorf("OptimizeCatalog(2000) error = %v, want infeasible %v", err, tc.infeasible)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerMaxSlots(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	rec := postOptimize(t, `{"quantity": 500, "slots": {"250": 1, "500": 3}, "maxSlots": 2}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.TotalSlots != 2 || result.TotalPacks != 2 || result.BindingConstraint != BindingMaxSlots {
+		t.Errorf("result = %d packs in %d slots, bindingConstraint %q; want 2 packs in 2 slots, %q",
+			result.TotalPacks, result.TotalSlots, result.BindingConstraint, BindingMaxSlots)
+	}
+
+	rec = postOptimize(t, `{"quantity": 2000, "slots": {"250": 1, "500": 2}, "maxSlots": 3}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("overflowing order status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if message := errorMessage(t, rec); !strings.Contains(message, "at least 3 pallets") {
+		t.Errorf("error = %q, want the pallets needed", message)
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+// checkSmallOrder rejects a result that wastes more items than were ordered,
+// the gross over-allocation of an order below the smallest pack, e.g. 1
+// item shipped in a 250 pack.
+func checkSmallOrder(result *OptimizationResult) error {
+	if result.Waste <= result.OrderQuantity {
+		return nil
+	}
+	return fmt.Errorf("%w: order %d would waste %d items, more than it orders; strictSmallOrders rejects it",
+		ErrInfeasible, result.OrderQuantity, result.Waste)
+}
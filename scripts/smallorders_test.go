@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStrictSmallOrders(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+		blocked     bool
+	}{
+		{"order 1 ships silently by default", 1, OptimizeOptions{}, false},
+		{"order 1 wastes more than it orders", 1, OptimizeOptions{StrictSmallOrders: true}, true},
+		{"order 200 wastes less than it orders", 200, OptimizeOptions{StrictSmallOrders: true}, false},
+		{"order 125 wastes as much as it orders", 125, OptimizeOptions{StrictSmallOrders: true}, false},
+		{"minimum order padding counts as waste", 100, OptimizeOptions{StrictSmallOrders: true, MinOrderQuantity: 250}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, catalog, tc.opts)
+			if tc.blocked {
+				if !errors.Is(err, ErrInfeasible) {
+					t.Fatalf("OptimizeCatalog(%d) error = %v, want ErrInfeasible", tc.quantity, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if result.TotalItems != 250 {
+				t.Errorf("totalItems = %d, want 250", result.TotalItems)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerStrictSmallOrders(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+
+	rec := postOptimize(t, `{"quantity": 1, "strictSmallOrders": true}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("order 1 status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if message := errorMessage(t, rec); !strings.Contains(message, "waste 249 items") {
+		t.Errorf("error = %q, want the waste", message)
+	}
+
+	rec = postOptimize(t, `{"quantity": 200, "strictSmallOrders": true}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("order 200 status = %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+// nearOptimalWindow returns the totals [lo, hi] considered near-optimal
+// around the minimal-waste total bestAmount: up to delta more waste. Exact
+// fills and underfills are never widened.
+func nearOptimalWindow(orderQuantity, bestAmount, delta int, policy string) (int, int) {
+	if policy == PolicyExact || bestAmount < orderQuantity || delta < 0 {
+		return bestAmount, bestAmount
+	}
+	return bestAmount, bestAmount + delta
+}
+
+// hasLoneSmallestPack reports whether a mix adds exactly one pack of the
+// smallest size to larger packs. sizes is sorted in descending order.
+func hasLoneSmallestPack(counts []int) bool {
+	smallest := counts[len(counts)-1]
+	packs := 0
+	for _, qty := range counts {
+		packs += qty
+	}
+	return smallest == 1 && packs > 1
+}
+
+// penalizedSmallPacksBreakdown searches the totals in [lo, hi] for a mix that
+// avoids a lone smallest-size pack, preferring fewer packs and then less
+// waste. Among mixes that all have a lone smallest pack it keeps the same
+// order. It returns -1 when no total in the window is reachable.
+func penalizedSmallPacksBreakdown(sizes []int, lo, hi int) (int, map[int]int) {
+	bestAmount, bestPacks, bestLone := -1, 0, true
+	var best []int
+
+	enumerateBreakdowns(sizes, lo, hi, maxSearchStates, func(counts []int, total int) {
+		packs := 0
+		for _, qty := range counts {
+			packs += qty
+		}
+		lone := hasLoneSmallestPack(counts)
+
+		better := bestAmount == -1
+		if !better && lone != bestLone {
+			better = !lone
+		} else if !better && packs != bestPacks {
+			better = packs < bestPacks
+		} else if !better {
+			better = total < bestAmount
+		}
+		if better {
+			bestAmount, bestPacks, bestLone = total, packs, lone
+			best = append(best[:0], counts...)
+		}
+	})
+
+	if bestAmount == -1 {
+		return -1, nil
+	}
+	return bestAmount, countsMap(sizes, best)
+}
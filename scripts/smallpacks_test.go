@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptimizePacksPenalizeSmallPacks(t *testing.T) {
+	testCases := []struct {
+		input       int
+		opts        OptimizeOptions
+		wantPacks   []PackResult
+		description string
+	}{
+		{
+			1100, OptimizeOptions{},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+			"Default adds a lone 250",
+		},
+		{
+			1100, OptimizeOptions{PenalizeSmallPacks: true},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 500, Quantity: 1}},
+			"Penalized mode uses a cleaner mix",
+		},
+		{
+			1100, OptimizeOptions{PenalizeSmallPacks: true, WasteDelta: 100},
+			[]PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 3}},
+			"Tight delta keeps the minimal waste total",
+		},
+		{
+			1, OptimizeOptions{PenalizeSmallPacks: true},
+			[]PackResult{{PackSize: 250, Quantity: 1}},
+			"A single small pack is not lone",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+			result, err := OptimizePacksWithOptions(tc.input, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(%d) returned error: %v", tc.input, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("OptimizePacksWithOptions(%d, %+v) packs = %v, want %v", tc.input, tc.opts, result.Packs, tc.wantPacks)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+// tieSeed is the server's seed for spreadTies. Requests that set no seed use
+// it when it is set and a fresh random seed otherwise; either way the seed
+// used is reported in the result's diagnostics so any selection can be
+// reproduced.
+var tieSeed = envSeed()
+
+func envSeed() *int64 {
+	value := os.Getenv("TIE_SEED")
+	if value == "" {
+		return nil
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &seed
+}
+
+// effectiveSeed returns the seed a request runs with: its own, else the
+// server's, else a random one.
+func effectiveSeed(requested *int64) int64 {
+	switch {
+	case requested != nil:
+		return *requested
+	case tieSeed != nil:
+		return *tieSeed
+	}
+	return rand.Int64()
+}
+
+// Diagnostics reports how a result was chosen. Each part is present only
+// when the request asked for it.
+type Diagnostics struct {
+	*TieDiagnostics
+	*WindowDiagnostics
+}
+
+// TieDiagnostics reports how spreadTies picked a breakdown, so it can be
+// reproduced.
+type TieDiagnostics struct {
+	Seed      int64 `json:"seed"`
+	CoOptimal int   `json:"coOptimal"`
+}
+
+// validateSpreadTies rejects spreadTies for modes with their own tie order.
+func validateSpreadTies(opts OptimizeOptions) error {
+	if opts.SpreadTies && opts.Mode != "" && opts.Mode != ModeFewestPacks {
+		return fmt.Errorf("spreadTies is not supported with mode %q", opts.Mode)
+	}
+	return nil
+}
+
+// spreadTieBreakdown picks one of the breakdowns of exactly total that use
+// the fewest packs, packs, uniformly at random from seed, so co-optimal
+// orders spread across pack sizes reproducibly. It also returns how many
+// breakdowns there were to choose from. Enumeration is deterministic, so the
+// same seed always picks the same breakdown.
+func spreadTieBreakdown(sizes []int, total, packs int, seed int64) (map[int]int, int) {
+	var candidates []map[int]int
+	enumerateBreakdowns(sizes, total, total, maxSearchStates, func(counts []int, _ int) {
+		used := 0
+		for _, qty := range counts {
+			used += qty
+		}
+		if used == packs {
+			candidates = append(candidates, countsMap(sizes, counts))
+		}
+	})
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+	return candidates[rng.IntN(len(candidates))], len(candidates)
+}
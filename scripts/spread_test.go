@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// spreadSizes fill 800 with two packs in four ways: 700+100, 600+200,
+// 500+300 and 400+400.
+var spreadSizes = []int{100, 200, 300, 400, 500, 600, 700}
+
+func TestSpreadTiesIsDeterministicPerSeed(t *testing.T) {
+	sizes := sortedDescending(spreadSizes)
+	solveSeeded := func(seed int64) *OptimizationResult {
+		t.Helper()
+		result, err := solve(800, sizes, OptimizeOptions{SpreadTies: true, Seed: seed})
+		if err != nil {
+			t.Fatalf("solve(800, seed %d) returned error: %v", seed, err)
+		}
+		return result
+	}
+
+	for seed := int64(0); seed < 10; seed++ {
+		first := solveSeeded(seed)
+		if first.TotalPacks != 2 || first.Waste != 0 {
+			t.Fatalf("seed %d picked %+v, want a two-pack exact fill", seed, first.Packs)
+		}
+		if first.Diagnostics == nil || first.Diagnostics.Seed != seed || first.Diagnostics.CoOptimal != 4 {
+			t.Errorf("seed %d diagnostics = %+v, want seed %d among 4", seed, first.Diagnostics, seed)
+		}
+		for run := 0; run < 5; run++ {
+			if again := solveSeeded(seed); !reflect.DeepEqual(again.Packs, first.Packs) {
+				t.Fatalf("seed %d picked %v, then %v", seed, first.Packs, again.Packs)
+			}
+		}
+	}
+
+	picked := map[string]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		picked[fmt.Sprint(solveSeeded(seed).Packs)] = true
+	}
+	if len(picked) < 2 {
+		t.Errorf("50 seeds all picked %v, want different seeds to differ", picked)
+	}
+}
+
+func TestSpreadTiesHandler(t *testing.T) {
+	withPackSizes(t, spreadSizes)
+	saved := tieSeed
+	t.Cleanup(func() { tieSeed = saved })
+
+	decode := func(t *testing.T, body string) OptimizationResult {
+		t.Helper()
+		rec := postOptimize(t, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST /optimize %s status = %d: %s", body, rec.Code, rec.Body.String())
+		}
+		var result OptimizationResult
+		if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return result
+	}
+
+	tieSeed = nil
+	if result := decode(t, `{"quantity": 800, "spreadTies": true, "seed": 42}`); result.Diagnostics == nil || result.Diagnostics.Seed != 42 {
+		t.Errorf("request seed 42 diagnostics = %+v", result.Diagnostics)
+	}
+
+	serverSeed := int64(7)
+	tieSeed = &serverSeed
+	if result := decode(t, `{"quantity": 800, "spreadTies": true}`); result.Diagnostics == nil || result.Diagnostics.Seed != 7 {
+		t.Errorf("server seed 7 diagnostics = %+v", result.Diagnostics)
+	}
+	if result := decode(t, `{"quantity": 800, "spreadTies": true, "seed": 42}`); result.Diagnostics.Seed != 42 {
+		t.Errorf("request seed under server seed diagnostics = %+v, want 42", result.Diagnostics)
+	}
+	if result := decode(t, `{"quantity": 800}`); result.Diagnostics != nil {
+		t.Errorf("without spreadTies diagnostics = %+v, want none", result.Diagnostics)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 800, "spreadTies": true, "mode": "fewestLines"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("spreadTies with fewestLines status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
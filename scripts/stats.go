@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// CatalogStats profiles a catalog's sizes. GCD is the step every reachable
+// total is a multiple of, so orders fill with zero waste only on it, and
+// Nested reports whether each size is a multiple of the next smaller one,
+// in which case the largest-first fill is already optimal.
+type CatalogStats struct {
+	Count  int  `json:"count"`
+	Min    int  `json:"min"`
+	Max    int  `json:"max"`
+	GCD    int  `json:"gcd"`
+	Nested bool `json:"nested"`
+}
+
+// gcd returns the greatest common divisor of two positive integers.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// catalogStats profiles sizes, which must not be empty.
+func catalogStats(sizes []int) CatalogStats {
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+
+	stats := CatalogStats{Count: len(sorted), Min: sorted[0], Max: sorted[len(sorted)-1], GCD: sorted[0], Nested: true}
+	for i, size := range sorted[1:] {
+		stats.GCD = gcd(stats.GCD, size)
+		if size%sorted[i] != 0 {
+			stats.Nested = false
+		}
+	}
+	return stats
+}
+
+// HTTP handler reporting statistics of a catalog
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	catalogName := r.URL.Query().Get("catalog")
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", catalogName), http.StatusNotFound)
+		return
+	}
+	if len(catalog.PackSizes) == 0 {
+		writeError(w, "catalog has no pack sizes", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	json.NewEncoder(w).Encode(catalogStats(catalog.PackSizes))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCatalogStats(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		want        CatalogStats
+	}{
+		// 5000 is not a multiple of 2000.
+		{"default catalog", []int{250, 500, 1000, 2000, 5000}, CatalogStats{Count: 5, Min: 250, Max: 5000, GCD: 250, Nested: false}},
+		{"nested", []int{250, 500, 1000, 2000, 4000}, CatalogStats{Count: 5, Min: 250, Max: 4000, GCD: 250, Nested: true}},
+		{"unsorted", []int{1000, 250, 500}, CatalogStats{Count: 3, Min: 250, Max: 1000, GCD: 250, Nested: true}},
+		{"not nested", []int{250, 500, 750}, CatalogStats{Count: 3, Min: 250, Max: 750, GCD: 250, Nested: false}},
+		{"coprime", []int{23, 31, 53}, CatalogStats{Count: 3, Min: 23, Max: 53, GCD: 1, Nested: false}},
+		{"single size", []int{300}, CatalogStats{Count: 1, Min: 300, Max: 300, GCD: 300, Nested: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := catalogStats(tc.sizes); got != tc.want {
+				t.Errorf("catalogStats(%v) = %+v, want %+v", tc.sizes, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := serveJSON(t, http.MethodGet, "/packages/stats", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /packages/stats status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats CatalogStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := (CatalogStats{Count: 5, Min: 250, Max: 5000, GCD: 250, Nested: false}); stats != want {
+		t.Errorf("GET /packages/stats = %+v, want %+v", stats, want)
+	}
+
+	if rec := serveJSON(t, http.MethodGet, "/packages/stats?catalog=missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /packages/stats for an unknown catalog status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
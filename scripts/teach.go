@@ -0,0 +1,82 @@
+package main
+
+// Lesson compares a result with the greedy largest-first fill, step by step,
+// for teaching why the optimizer searches instead of always taking the
+// largest pack that fits.
+type Lesson struct {
+	Greedy  []LessonStep `json:"greedy"`
+	Optimal []LessonStep `json:"optimal"`
+	// DivergesAt is the 1-based step at which the two sequences first
+	// differ, or 0 when greedy finds the optimal packs.
+	DivergesAt  int `json:"divergesAt"`
+	GreedyPacks int `json:"greedyPacks"`
+	GreedyWaste int `json:"greedyWaste"`
+}
+
+// LessonStep adds Quantity packs of PackSize, after which Filled items are
+// packed and Remaining are still to be packed.
+type LessonStep struct {
+	PackSize  int `json:"packSize"`
+	Quantity  int `json:"quantity"`
+	Filled    int `json:"filled"`
+	Remaining int `json:"remaining"`
+}
+
+// appendStep adds quantity packs of size to steps for an order of
+// orderQuantity, merging them into the last step when it uses the same size.
+func appendStep(steps []LessonStep, orderQuantity, size, quantity int) []LessonStep {
+	filled := 0
+	if n := len(steps); n > 0 {
+		filled = steps[n-1].Filled
+		if steps[n-1].PackSize == size {
+			quantity += steps[n-1].Quantity
+			filled -= steps[n-1].Quantity * size
+			steps = steps[:n-1]
+		}
+	}
+	filled += quantity * size
+	return append(steps, LessonStep{PackSize: size, Quantity: quantity, Filled: filled, Remaining: max(orderQuantity-filled, 0)})
+}
+
+// greedySteps fills orderQuantity the naive way: as many of each size as
+// fit, largest first, then one smallest pack for any remainder. sizes must
+// be sorted in descending order.
+func greedySteps(orderQuantity int, sizes []int) []LessonStep {
+	var steps []LessonStep
+	remaining := orderQuantity
+	for _, size := range sizes {
+		if n := remaining / size; n > 0 {
+			steps = appendStep(steps, orderQuantity, size, n)
+			remaining -= n * size
+		}
+	}
+	if remaining > 0 {
+		steps = appendStep(steps, orderQuantity, sizes[len(sizes)-1], 1)
+	}
+	return steps
+}
+
+// buildLesson compares result, an answer for catalog, with the greedy fill
+// of its order quantity.
+func buildLesson(result *OptimizationResult, catalog Catalog) *Lesson {
+	lesson := &Lesson{Greedy: greedySteps(result.OrderQuantity, sortedDescending(catalog.PackSizes))}
+	for _, pack := range result.Packs {
+		if pack.Quantity > 0 {
+			lesson.Optimal = appendStep(lesson.Optimal, result.OrderQuantity, pack.PackSize, pack.Quantity)
+		}
+	}
+	for _, step := range lesson.Greedy {
+		lesson.GreedyPacks += step.Quantity
+	}
+	if n := len(lesson.Greedy); n > 0 {
+		lesson.GreedyWaste = lesson.Greedy[n-1].Filled - result.OrderQuantity
+	}
+
+	for i := 0; i < max(len(lesson.Greedy), len(lesson.Optimal)); i++ {
+		if i >= len(lesson.Greedy) || i >= len(lesson.Optimal) || lesson.Greedy[i] != lesson.Optimal[i] {
+			lesson.DivergesAt = i + 1
+			break
+		}
+	}
+	return lesson
+}
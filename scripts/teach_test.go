@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildLesson(t *testing.T) {
+	testCases := []struct {
+		description    string
+		sizes          []int
+		quantity       int
+		wantGreedy     []LessonStep
+		wantOptimal    []LessonStep
+		wantDivergesAt int
+	}{
+		{"greedy takes the largest pack", []int{4, 9, 10}, 18,
+			[]LessonStep{{PackSize: 10, Quantity: 1, Filled: 10, Remaining: 8}, {PackSize: 4, Quantity: 2, Filled: 18}},
+			[]LessonStep{{PackSize: 9, Quantity: 2, Filled: 18}}, 1},
+		{"greedy overfills the remainder", []int{5, 10, 12}, 20,
+			[]LessonStep{{PackSize: 12, Quantity: 1, Filled: 12, Remaining: 8}, {PackSize: 5, Quantity: 2, Filled: 22}},
+			[]LessonStep{{PackSize: 10, Quantity: 2, Filled: 20}}, 1},
+		{"greedy is optimal", []int{250, 500, 1000, 2000, 5000}, 12001,
+			[]LessonStep{{PackSize: 5000, Quantity: 2, Filled: 10000, Remaining: 2001}, {PackSize: 2000, Quantity: 1, Filled: 12000, Remaining: 1}, {PackSize: 250, Quantity: 1, Filled: 12250}},
+			[]LessonStep{{PackSize: 5000, Quantity: 2, Filled: 10000, Remaining: 2001}, {PackSize: 2000, Quantity: 1, Filled: 12000, Remaining: 1}, {PackSize: 250, Quantity: 1, Filled: 12250}}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			catalog := Catalog{PackSizes: tc.sizes}
+			result, err := OptimizeCatalog(tc.quantity, catalog, OptimizeOptions{})
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			lesson := buildLesson(result, catalog)
+			if !reflect.DeepEqual(lesson.Greedy, tc.wantGreedy) {
+				t.Errorf("greedy = %+v, want %+v", lesson.Greedy, tc.wantGreedy)
+			}
+			if !reflect.DeepEqual(lesson.Optimal, tc.wantOptimal) {
+				t.Errorf("optimal = %+v, want %+v", lesson.Optimal, tc.wantOptimal)
+			}
+			if lesson.DivergesAt != tc.wantDivergesAt {
+				t.Errorf("divergesAt = %d, want %d", lesson.DivergesAt, tc.wantDivergesAt)
+			}
+		})
+	}
+}
+
+func TestOptimizeTeach(t *testing.T) {
+	withPackSizes(t, []int{4, 9, 10})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?teach=true", strings.NewReader(`{"quantity": 18}`))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize?teach=true status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Lesson == nil {
+		t.Fatalf("response has no lesson")
+	}
+	if result.Lesson.DivergesAt != 1 || result.Lesson.GreedyPacks != 3 || result.TotalPacks != 2 {
+		t.Errorf("lesson = %+v for %d packs, want greedy's 3 packs diverging at step 1 from 2", result.Lesson, result.TotalPacks)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"expvar"
+	"log/slog"
+	"os"
+)
+
+// largeTableThreshold is the search window, in items, above which a request
+// is reported as pathological. Zero disables the report.
+var largeTableThreshold = envInt("LARGE_TABLE_THRESHOLD", 1_000_000)
+
+// largeTables counts the search windows above largeTableThreshold. It is
+// published with the other expvar metrics on GET /debug/vars.
+var largeTables = expvar.NewInt("largeTables")
+
+// telemetryLog receives the warnings about pathological inputs.
+var telemetryLog = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// observeWindow warns about and counts a request whose search window for
+// orderQuantity exceeds largeTableThreshold, so clients sending such inputs
+// can be contacted. solve and optimizeBatch call it once per request, before
+// the memory ceiling is enforced, so rejected windows are reported too. A
+// single size answered by division builds no table and is not reported.
+func observeWindow(orderQuantity int, sizes []int, opts OptimizeOptions) {
+	if largeTableThreshold <= 0 || !needsPackTable(sizes, opts) && !perPackMode(opts.Mode) {
+		return
+	}
+	maxSize, err := windowSize(orderQuantity, sizes, opts)
+	if err != nil || maxSize <= largeTableThreshold {
+		return
+	}
+	largeTables.Add(1)
+	telemetryLog.Warn("large DP table",
+		"quantity", orderQuantity,
+		"packSizes", sizes,
+		"maxSize", maxSize,
+		"threshold", largeTableThreshold,
+	)
+}
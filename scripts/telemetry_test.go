@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestObserveWindowWarnsAboveThreshold(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+	savedThreshold, savedLog := largeTableThreshold, telemetryLog
+	t.Cleanup(func() { largeTableThreshold, telemetryLog = savedThreshold, savedLog })
+	largeTableThreshold = 100_000
+
+	testCases := []struct {
+		description string
+		quantity    int
+		wantWarning bool
+	}{
+		// The window is the quantity plus the largest pack, 5000.
+		{"well below", 1000, false},
+		{"at the threshold", 95_000, false},
+		{"just above", 95_001, true},
+		{"far above", 1_000_000, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var out bytes.Buffer
+			telemetryLog = slog.New(slog.NewJSONHandler(&out, nil))
+			before := largeTables.Value()
+
+			if _, err := OptimizePacks(tc.quantity); err != nil {
+				t.Fatalf("OptimizePacks(%d) returned error: %v", tc.quantity, err)
+			}
+
+			counted := largeTables.Value() - before
+			if tc.wantWarning {
+				if counted != 1 {
+					t.Errorf("largeTables grew by %d, want 1", counted)
+				}
+				line := out.String()
+				for _, want := range []string{`"level":"WARN"`, `"quantity":`, `"packSizes":[5000,2000,1000,500,250]`} {
+					if !strings.Contains(line, want) {
+						t.Errorf("warning %q missing %s", line, want)
+					}
+				}
+			} else if counted != 0 || out.Len() != 0 {
+				t.Errorf("quantity %d below threshold logged %q and counted %d", tc.quantity, out.String(), counted)
+			}
+		})
+	}
+}
+
+func TestDebugVarsPublishesLargeTables(t *testing.T) {
+	rec := serveJSON(t, http.MethodGet, "/debug/vars", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"largeTables"`) {
+		t.Errorf("GET /debug/vars = %d, missing largeTables", rec.Code)
+	}
+}
+
+func TestObserveWindowOncePerRequest(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	savedThreshold, savedLog := largeTableThreshold, telemetryLog
+	t.Cleanup(func() { largeTableThreshold, telemetryLog = savedThreshold, savedLog })
+	largeTableThreshold = 10
+	telemetryLog = slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	// Weights, a pack limit and topK each revisit the search window.
+	before := largeTables.Value()
+	if _, err := OptimizeCatalog(1000, Catalog{PackSizes: []int{250, 500}}, OptimizeOptions{Weights: &ScoreWeights{}}); err != nil {
+		t.Fatalf("OptimizeCatalog(1000) returned error: %v", err)
+	}
+	if counted := largeTables.Value() - before; counted != 1 {
+		t.Errorf("weighted solve grew largeTables by %d, want 1", counted)
+	}
+
+	before = largeTables.Value()
+	rec := postOptimize(t, `{"quantity": 1001, "maxPacks": 3, "topK": 3, "suggestUpsell": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if counted := largeTables.Value() - before; counted != 1 {
+		t.Errorf("POST /optimize grew largeTables by %d, want 1", counted)
+	}
+}
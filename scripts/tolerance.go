@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToleranceResult is the best solution for an order when up to Tolerance of
+// the order quantity, MaxWaste units, may be wasted. Result is nil when no
+// total within the tolerance can be packed.
+type ToleranceResult struct {
+	Tolerance float64             `json:"tolerance"`
+	MaxWaste  int                 `json:"maxWaste"`
+	Result    *OptimizationResult `json:"result"`
+}
+
+// validateTolerances checks that every tolerance is a non-negative fraction
+// of the order quantity.
+func validateTolerances(tolerances []float64) error {
+	for _, tolerance := range tolerances {
+		if tolerance < 0 || math.IsNaN(tolerance) || math.IsInf(tolerance, 0) {
+			return fmt.Errorf("tolerances must be non-negative fractions of the quantity, got %v", tolerance)
+		}
+	}
+	return nil
+}
+
+// toleranceLadder finds, for each tolerance, the fewest packs of catalog
+// filling orderQuantity with at most tolerance·orderQuantity waste, preferring
+// less waste on ties. Like the solve, totals start at the order bumped to
+// opts.MinOrderQuantity, are capped by MaxQuantity and MaxWaste, stay at that
+// order under PolicyExact and use at most MaxPacks packs; the rungs rank by
+// pack count whatever the mode. Every rung reads the same DP table. Totals
+// beyond the search window never win: dropping a pack from such a mix lowers
+// both waste and packs.
+func toleranceLadder(orderQuantity int, catalog Catalog, opts OptimizeOptions, tolerances []float64) ([]ToleranceResult, error) {
+	// The table only knows each total's fewest-pack mix, which may break a
+	// per-mix constraint another mix of the total meets.
+	if opts.MaxWastePerSize != nil || opts.MaxSlots > 0 || opts.Budget > 0 {
+		return nil, fmt.Errorf("tolerances cannot be combined with maxWastePerSize, maxSlots or budget")
+	}
+	sizes := sortedDescending(catalog.PackSizes)
+	target := max(orderQuantity, opts.MinOrderQuantity)
+	opts = limitWaste(orderQuantity, opts)
+	maxSize, err := searchWindow(target, sizes, OptimizeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	dp, err := fillPackTable(solveContext(opts), sizes, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	hi := capAtMaxQuantity(maxSize, opts)
+	if policy := opts.Policy; policy == PolicyExact || policy == "" && fulfillmentPolicy == PolicyExact {
+		hi = target
+	}
+
+	ladder := make([]ToleranceResult, len(tolerances))
+	for i, tolerance := range tolerances {
+		// The epsilon keeps a tolerance such as 0.29 of 100 from flooring to
+		// 28 through float error.
+		maxWaste := int(math.Floor(tolerance*float64(orderQuantity) + 1e-9))
+		ladder[i] = ToleranceResult{Tolerance: tolerance, MaxWaste: maxWaste}
+
+		best := -1
+		for total := target; total <= hi && total-orderQuantity <= maxWaste; total++ {
+			packs := dp[total].packs
+			if packs == math.MaxInt32 || opts.MaxPacks > 0 && packs > opts.MaxPacks {
+				continue
+			}
+			if best == -1 || packs < dp[best].packs {
+				best = total
+			}
+		}
+		if best != -1 {
+			result := buildResult(orderQuantity, best, sizes, packCounts(dp, best))
+			if target != orderQuantity {
+				result.BumpedTo = target
+			}
+			applyLabels(result, catalog.Labels)
+			ladder[i].Result = result
+		}
+	}
+	return ladder, nil
+}
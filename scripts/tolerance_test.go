@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestToleranceLadder(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		want        []ToleranceResult
+	}{
+		{
+			// 9750 fills exactly with five packs; 5% covers 10000, two packs.
+			"fewer packs at a higher tolerance",
+			9750,
+			[]ToleranceResult{
+				{Tolerance: 0, MaxWaste: 0, Result: &OptimizationResult{OrderQuantity: 9750, TotalItems: 9750, TotalPacks: 5, Packs: []PackResult{{PackSize: 5000, Quantity: 1}, {PackSize: 2000, Quantity: 2}, {PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}}},
+				{Tolerance: 0.01, MaxWaste: 97, Result: &OptimizationResult{OrderQuantity: 9750, TotalItems: 9750, TotalPacks: 5, Packs: []PackResult{{PackSize: 5000, Quantity: 1}, {PackSize: 2000, Quantity: 2}, {PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}}},
+				{Tolerance: 0.05, MaxWaste: 487, Result: &OptimizationResult{OrderQuantity: 9750, TotalItems: 10000, TotalPacks: 2, Packs: []PackResult{{PackSize: 5000, Quantity: 2}}, Waste: 250}},
+			},
+		},
+		{
+			// No total from 9800 to 9898 is a multiple of 250.
+			"nothing within the tighter tolerances",
+			9800,
+			[]ToleranceResult{
+				{Tolerance: 0, MaxWaste: 0},
+				{Tolerance: 0.01, MaxWaste: 98},
+				{Tolerance: 0.05, MaxWaste: 490, Result: &OptimizationResult{OrderQuantity: 9800, TotalItems: 10000, TotalPacks: 2, Packs: []PackResult{{PackSize: 5000, Quantity: 2}}, Waste: 200}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := toleranceLadder(tc.quantity, catalog, OptimizeOptions{}, []float64{0, 0.01, 0.05})
+			if err != nil {
+				t.Fatalf("toleranceLadder(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("toleranceLadder(%d) = %+v, want %+v", tc.quantity, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToleranceLadderFollowsConstraints(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	tolerances := []float64{0, 0.05, 1, 10}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+		allowed     func(result *OptimizationResult) bool
+	}{
+		{"minimum order", 100, OptimizeOptions{MinOrderQuantity: 600},
+			func(r *OptimizationResult) bool { return r.TotalItems >= 600 && r.BumpedTo == 600 }},
+		{"maxPacks", 9750, OptimizeOptions{MaxPacks: 3},
+			func(r *OptimizationResult) bool { return r.TotalPacks <= 3 }},
+		{"maxWaste", 9750, OptimizeOptions{MaxWaste: 100},
+			func(r *OptimizationResult) bool { return r.Waste <= 100 }},
+		{"maxQuantity", 9750, OptimizeOptions{MaxQuantity: 9800},
+			func(r *OptimizationResult) bool { return r.TotalItems <= 9800 }},
+		{"exact", 9750, OptimizeOptions{Policy: PolicyExact},
+			func(r *OptimizationResult) bool { return r.TotalItems == 9750 }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ladder, err := toleranceLadder(tc.quantity, catalog, tc.opts, tolerances)
+			if err != nil {
+				t.Fatalf("toleranceLadder(%d) returned error: %v", tc.quantity, err)
+			}
+			found := false
+			for _, rung := range ladder {
+				if rung.Result == nil {
+					continue
+				}
+				found = true
+				if !tc.allowed(rung.Result) {
+					t.Errorf("rung %v = %+v breaks the constraint", rung.Tolerance, rung.Result)
+				}
+			}
+			if !found {
+				t.Errorf("toleranceLadder(%d) = %+v, want a rung within the constraint", tc.quantity, ladder)
+			}
+		})
+	}
+
+	if _, err := toleranceLadder(9750, catalog, OptimizeOptions{MaxSlots: 3, Slots: map[int]int{250: 1}}, tolerances); err == nil {
+		t.Errorf("toleranceLadder with maxSlots returned no error")
+	}
+}
+
+func TestOptimizeTolerances(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 9800, "tolerances": [0, 0.05]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		TotalItems int `json:"totalItems"`
+		Tolerances []struct {
+			Tolerance float64          `json:"tolerance"`
+			Result    *json.RawMessage `json:"result"`
+		} `json:"tolerances"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.TotalItems != 10000 || len(result.Tolerances) != 2 {
+		t.Fatalf("POST /optimize = %s, want 10000 items and two tolerances", rec.Body.String())
+	}
+	if result.Tolerances[0].Result != nil || result.Tolerances[1].Result == nil {
+		t.Errorf("tolerances = %s, want null at 0 and a result at 0.05", rec.Body.String())
+	}
+
+	if rec := postOptimize(t, `{"quantity": 9800, "tolerances": [-0.01]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative tolerance status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
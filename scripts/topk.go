@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// maxTopK bounds the alternatives one request may ask for.
+const maxTopK = 20
+
+// validateTopK checks a request's topK against maxTopK.
+func validateTopK(k int) error {
+	if k < 0 || k > maxTopK {
+		return fmt.Errorf("topK must be between 0 and %d", maxTopK)
+	}
+	return nil
+}
+
+// rankedMix is one candidate breakdown of topAlternatives.
+type rankedMix struct {
+	total, packs int
+	counts       []int
+}
+
+// before reports whether m ranks above other: less waste, then fewer packs.
+func (m rankedMix) before(other rankedMix) bool {
+	if m.total != other.total {
+		return m.total < other.total
+	}
+	return m.packs < other.packs
+}
+
+// topAlternatives returns up to k distinct breakdowns of orderQuantity,
+// led by primary, the plan the request was answered with, and followed by
+// the other mixes ranked by waste and then pack count, whatever the mode.
+// The search covers the totals from the effective order, after any
+// MinOrderQuantity bump, to wasteDelta above the primary total, or that
+// order alone under PolicyExact, capped like the solve by MaxQuantity and
+// MaxWaste; mixes breaking another constraint of opts are skipped. It is
+// bounded by maxSearchStates; truncated reports that the bound cut it short,
+// so better mixes than the last ones listed may exist.
+func topAlternatives(orderQuantity int, primary *OptimizationResult, catalog Catalog, opts OptimizeOptions, k int) (alternatives []*OptimizationResult, truncated bool, err error) {
+	sizes := sortedDescending(catalog.PackSizes)
+	best := make([]int, len(sizes))
+	for _, pack := range primary.Packs {
+		i := slices.Index(sizes, pack.PackSize)
+		if i == -1 {
+			return nil, false, fmt.Errorf("primary plan uses pack size %d, which the catalog lacks", pack.PackSize)
+		}
+		best[i] = pack.Quantity
+	}
+
+	target := max(orderQuantity, opts.MinOrderQuantity)
+	opts = limitWaste(orderQuantity, opts)
+	policy := opts.Policy
+	if policy == "" {
+		policy = fulfillmentPolicy
+	}
+	lo, hi := target, max(primary.TotalItems, target)+wasteDelta(opts, sizes)
+	if policy == PolicyExact {
+		hi = target
+	}
+	hi = capAtMaxQuantity(hi, opts)
+	ranked := []rankedMix{{total: primary.TotalItems, packs: primary.TotalPacks, counts: best}}
+
+	truncated = enumerateBreakdowns(sizes, lo, hi, maxSearchStates, func(counts []int, total int) {
+		if slices.Equal(counts, best) || !mixAllowed(sizes, counts, total, orderQuantity, target, opts) {
+			return
+		}
+		mix := rankedMix{total: total}
+		for _, qty := range counts {
+			mix.packs += qty
+		}
+		at := len(ranked)
+		for at > 1 && mix.before(ranked[at-1]) {
+			at--
+		}
+		if at >= k {
+			return
+		}
+		mix.counts = slices.Clone(counts)
+		ranked = slices.Insert(ranked, at, mix)
+		if len(ranked) > k {
+			ranked = ranked[:k]
+		}
+	})
+
+	alternatives = make([]*OptimizationResult, len(ranked))
+	for i, mix := range ranked {
+		counts := countsMap(sizes, mix.counts)
+		result := buildResult(orderQuantity, mix.total, sizes, counts)
+		if opts.Costs != nil {
+			cost := roundCost(breakdownCost(counts, opts.Costs), opts.Currency)
+			result.TotalCost = &cost
+			result.Currency = opts.Currency
+		}
+		applyLabels(result, catalog.Labels)
+		alternatives[i] = result
+	}
+	return alternatives, truncated, nil
+}
+
+// mixAllowed reports whether a mix of total items, aligned with sizes, meets
+// the per-mix constraints of opts for orderQuantity, bumped to target, so no
+// alternative breaks a limit the primary plan had to honour.
+func mixAllowed(sizes, counts []int, total, orderQuantity, target int, opts OptimizeOptions) bool {
+	m := countsMap(sizes, counts)
+	waste := max(total-target, 0)
+	switch {
+	case opts.MaxPacks > 0 && countPacks(m) > opts.MaxPacks:
+		return false
+	case opts.MaxWastePerSize != nil && !withinWasteCaps(sizes, counts, waste, opts.MaxWastePerSize):
+		return false
+	case opts.MaxSlots > 0 && breakdownSlots(m, opts.Slots) > opts.MaxSlots:
+		return false
+	case opts.Budget > 0 && !withinBudget(breakdownCost(m, opts.Costs), opts.Budget):
+		return false
+	case opts.StrictSmallOrders && total-orderQuantity > orderQuantity:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// alternativesFor ranks the top k alternatives behind the plan OptimizeCatalog
+// answers quantity with.
+func alternativesFor(t *testing.T, quantity int, catalog Catalog, opts OptimizeOptions, k int) (*OptimizationResult, []*OptimizationResult, bool) {
+	t.Helper()
+	primary, err := OptimizeCatalog(quantity, catalog, opts)
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(%d) returned error: %v", quantity, err)
+	}
+	alternatives, truncated, err := topAlternatives(quantity, primary, catalog, opts, k)
+	if err != nil {
+		t.Fatalf("topAlternatives(%d) returned error: %v", quantity, err)
+	}
+	return primary, alternatives, truncated
+}
+
+func TestTopAlternatives(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}}
+
+	optimum, got, _ := alternativesFor(t, 501, catalog, OptimizeOptions{}, 5)
+	want := [][]PackResult{
+		{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}},
+		{{PackSize: 250, Quantity: 3}},
+		{{PackSize: 1000, Quantity: 1}},
+		{{PackSize: 500, Quantity: 2}},
+		{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 2}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("topAlternatives(501) returned %d results, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i].Packs, want[i]) {
+			t.Errorf("alternative %d = %+v, want %+v", i, got[i].Packs, want[i])
+		}
+	}
+
+	if !reflect.DeepEqual(got[0], optimum) {
+		t.Errorf("first alternative = %+v, want the optimum %+v", got[0], optimum)
+	}
+	for i := 1; i < len(got); i++ {
+		prev, cur := got[i-1], got[i]
+		if cur.Waste < prev.Waste || cur.Waste == prev.Waste && cur.TotalPacks < prev.TotalPacks {
+			t.Errorf("alternative %d (waste %d, %d packs) improves on %d (waste %d, %d packs)", i, cur.Waste, cur.TotalPacks, i-1, prev.Waste, prev.TotalPacks)
+		}
+	}
+}
+
+func TestTopAlternativesBounds(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000}}
+
+	_, exact, _ := alternativesFor(t, 750, catalog, OptimizeOptions{Policy: PolicyExact}, 10)
+	if len(exact) != 2 {
+		t.Errorf("exact alternatives = %+v, want the two mixes of 750", exact)
+	}
+	for _, result := range exact {
+		if result.TotalItems != 750 {
+			t.Errorf("exact alternative ships %d items, want 750", result.TotalItems)
+		}
+	}
+
+	// Sizes 1 and 2 fill a million items about a million ways; the search
+	// stops at maxSearchStates instead and still leads with the optimum.
+	_, big, truncated := alternativesFor(t, 1_000_000, Catalog{PackSizes: []int{1, 2}}, OptimizeOptions{}, 3)
+	if !truncated {
+		t.Errorf("topAlternatives(1000000) searched every mix, want it truncated")
+	}
+	if len(big) == 0 || big[0].TotalPacks != 500_000 {
+		t.Errorf("large order alternatives = %+v, want the 500000-pack optimum first", big)
+	}
+}
+
+func TestTopAlternativesFollowConstraints(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+		allowed     func(result *OptimizationResult) bool
+	}{
+		{"maxPacks", 12001, OptimizeOptions{MaxPacks: 3},
+			func(r *OptimizationResult) bool { return r.TotalPacks <= 3 }},
+		{"minimum order", 100, OptimizeOptions{MinOrderQuantity: 600},
+			func(r *OptimizationResult) bool { return r.TotalItems >= 600 }},
+		{"maxWaste", 4800, OptimizeOptions{MaxWaste: 250},
+			func(r *OptimizationResult) bool { return r.Waste <= 250 }},
+		{"weights", 4800, OptimizeOptions{Weights: &ScoreWeights{Waste: 1, Packs: 1000}},
+			func(r *OptimizationResult) bool { return true }},
+		{"exact", 750, OptimizeOptions{Policy: PolicyExact},
+			func(r *OptimizationResult) bool { return r.TotalItems == 750 }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			primary, got, _ := alternativesFor(t, tc.quantity, catalog, tc.opts, 5)
+			if len(got) == 0 {
+				t.Fatalf("no alternatives for %d", tc.quantity)
+			}
+			if !reflect.DeepEqual(got[0].Packs, primary.Packs) || got[0].TotalItems != primary.TotalItems || got[0].Waste != primary.Waste {
+				t.Errorf("first alternative = %+v, want the primary plan %+v", got[0], primary)
+			}
+			for i, alternative := range got {
+				if !tc.allowed(alternative) {
+					t.Errorf("alternative %d = %+v breaks the constraint", i, alternative)
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeTopK(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+
+	rec := postOptimize(t, `{"quantity": 501, "topK": 3}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(result.Alternatives) != 3 || !reflect.DeepEqual(result.Alternatives[0].Packs, result.Packs) {
+		t.Errorf("alternatives = %+v, want 3 led by the result", result.Alternatives)
+	}
+
+	for _, bad := range []string{`{"quantity": 501, "topK": -1}`, `{"quantity": 501, "topK": 21}`} {
+		if rec := postOptimize(t, bad); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize %s status = %d, want %d", bad, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestOptimizeTopKTruncated(t *testing.T) {
+	withPackSizes(t, []int{1, 2, 3, 5, 7, 11})
+	saved := maxSearchStates
+	t.Cleanup(func() { maxSearchStates = saved })
+	maxSearchStates = 1000
+
+	testCases := []struct {
+		description   string
+		body          string
+		wantTruncated bool
+	}{
+		// A dense catalog fills 500 items in far more mixes than 1000
+		// partial states reach.
+		{"dense catalog", `{"quantity": 500, "topK": 5, "policy": "exact"}`, true},
+		{"small order", `{"quantity": 4, "topK": 5, "policy": "exact"}`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if result.AlternativesTruncated != tc.wantTruncated {
+				t.Errorf("alternativesTruncated = %v, want %v", result.AlternativesTruncated, tc.wantTruncated)
+			}
+			if len(result.Alternatives) == 0 || !reflect.DeepEqual(result.Alternatives[0].Packs, result.Packs) {
+				t.Errorf("alternatives = %+v, want a partial list led by the result", result.Alternatives)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import "fmt"
+
+// PackingTree mirrors the physical packing of a result: master cartons hold
+// packs, and packs hold items.
+type PackingTree struct {
+	CartonSize int          `json:"cartonSize"`
+	Cartons    []CartonNode `json:"cartons"`
+}
+
+// CartonNode is one master carton and the packs assigned to it.
+type CartonNode struct {
+	Items int        `json:"items"`
+	Packs []PackNode `json:"packs"`
+}
+
+// PackNode is a group of same-size packs inside a master carton.
+type PackNode struct {
+	PackSize int    `json:"packSize"`
+	Label    string `json:"label,omitempty"`
+	Quantity int    `json:"quantity"`
+	Items    int    `json:"items"`
+}
+
+// Pack assignment orders for the packing tree.
+const (
+	// AssignGrouped places every pack of one size before the next size.
+	AssignGrouped = "grouped"
+	// AssignRoundRobin takes one pack of each size in turn, largest first.
+	AssignRoundRobin = "round-robin"
+)
+
+// maxTreePacks bounds the packs a packing tree places. Each pack is placed
+// on its own, first-fit across the cartons so far, and a single-size
+// catalog is solved by division, so its pack count is not bounded by the DP
+// memory ceiling.
+const maxTreePacks = 10_000
+
+// validAssignment reports whether assignment names a supported order. The
+// empty string selects AssignGrouped.
+func validAssignment(assignment string) bool {
+	return assignment == "" || assignment == AssignGrouped || assignment == AssignRoundRobin
+}
+
+// packSequence lists the individual packs of packs, which are in decreasing
+// size order, in the order they are handled under assignment.
+func packSequence(packs []PackResult, assignment string) []PackResult {
+	var sequence []PackResult
+	if assignment != AssignRoundRobin {
+		for _, pack := range packs {
+			for n := 0; n < pack.Quantity; n++ {
+				sequence = append(sequence, pack)
+			}
+		}
+		return sequence
+	}
+
+	remaining := make([]int, len(packs))
+	for i, pack := range packs {
+		remaining[i] = pack.Quantity
+	}
+	for added := true; added; {
+		added = false
+		for i, pack := range packs {
+			if remaining[i] > 0 {
+				sequence = append(sequence, pack)
+				remaining[i]--
+				added = true
+			}
+		}
+	}
+	return sequence
+}
+
+// buildPackingTree assigns the packs of result to master cartons holding up
+// to cartonSize items each, first-fit in the order given by assignment.
+// Every pack must fit in a carton.
+func buildPackingTree(result *OptimizationResult, cartonSize int, assignment string) (*PackingTree, error) {
+	if cartonSize <= 0 {
+		return nil, fmt.Errorf("masterCartonSize must be positive")
+	}
+	if !validAssignment(assignment) {
+		return nil, fmt.Errorf("unknown assignment %q", assignment)
+	}
+	if result.TotalPacks > maxTreePacks {
+		return nil, fmt.Errorf("tree places packs one by one and supports at most %d packs, the result has %d", maxTreePacks, result.TotalPacks)
+	}
+
+	tree := &PackingTree{CartonSize: cartonSize, Cartons: []CartonNode{}}
+	for _, pack := range packSequence(result.Packs, assignment) {
+		if pack.PackSize > cartonSize {
+			return nil, fmt.Errorf("pack size %d does not fit in a master carton of %d items", pack.PackSize, cartonSize)
+		}
+		placed := false
+		for i := range tree.Cartons {
+			if tree.Cartons[i].Items+pack.PackSize <= cartonSize {
+				tree.Cartons[i].add(pack)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			tree.Cartons = append(tree.Cartons, CartonNode{Packs: []PackNode{}})
+			tree.Cartons[len(tree.Cartons)-1].add(pack)
+		}
+	}
+	return tree, nil
+}
+
+// add puts one pack of pack's size into the carton.
+func (c *CartonNode) add(pack PackResult) {
+	c.Items += pack.PackSize
+	if last := len(c.Packs) - 1; last >= 0 && c.Packs[last].PackSize == pack.PackSize {
+		c.Packs[last].Quantity++
+		c.Packs[last].Items += pack.PackSize
+		return
+	}
+	c.Packs = append(c.Packs, PackNode{PackSize: pack.PackSize, Label: pack.Label, Quantity: 1, Items: pack.PackSize})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildPackingTree(t *testing.T) {
+	result := buildResult(12001, 12250, []int{5000, 2000, 1000, 500, 250},
+		map[int]int{5000: 2, 2000: 1, 250: 1})
+
+	tree, err := buildPackingTree(result, 10000, "")
+	if err != nil {
+		t.Fatalf("buildPackingTree returned error: %v", err)
+	}
+	want := []CartonNode{
+		{Items: 10000, Packs: []PackNode{{PackSize: 5000, Quantity: 2, Items: 10000}}},
+		{Items: 2250, Packs: []PackNode{
+			{PackSize: 2000, Quantity: 1, Items: 2000},
+			{PackSize: 250, Quantity: 1, Items: 250},
+		}},
+	}
+	if !reflect.DeepEqual(tree.Cartons, want) {
+		t.Errorf("cartons = %+v, want %+v", tree.Cartons, want)
+	}
+
+	leaves, packs := 0, 0
+	for _, carton := range tree.Cartons {
+		for _, pack := range carton.Packs {
+			leaves += pack.Items
+			packs += pack.Quantity
+		}
+	}
+	if leaves != result.TotalItems || packs != result.TotalPacks {
+		t.Errorf("tree holds %d items in %d packs, want %d in %d", leaves, packs, result.TotalItems, result.TotalPacks)
+	}
+
+	if _, err := buildPackingTree(result, 4000, ""); err == nil {
+		t.Error("buildPackingTree should reject packs larger than the carton")
+	}
+}
+
+func TestOptimizeHandlerTree(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize?tree=true", strings.NewReader(`{"quantity": 12001, "masterCartonSize": 10000}`))
+	rec := httptest.NewRecorder()
+	optimizeHandler(rec, req)
+
+	var result OptimizationResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response %q: %v", rec.Body.String(), err)
+	}
+	if result.Tree == nil || len(result.Tree.Cartons) != 2 {
+		t.Errorf("POST /optimize?tree=true tree = %+v, want 2 cartons", result.Tree)
+	}
+
+	rec = postOptimize(t, `{"quantity": 12001, "masterCartonSize": 10000}`)
+	if strings.Contains(rec.Body.String(), `"tree"`) {
+		t.Errorf("POST /optimize without ?tree=true returned a tree: %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/optimize?tree=true", strings.NewReader(`{"quantity": 12001}`))
+	rec = httptest.NewRecorder()
+	optimizeHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /optimize?tree=true without masterCartonSize status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBuildPackingTreeRoundRobin(t *testing.T) {
+	result := buildResult(4000, 4250, []int{1000, 500, 250},
+		map[int]int{1000: 3, 500: 2, 250: 1})
+
+	sizesOf := func(tree *PackingTree) [][]int {
+		var cartons [][]int
+		for _, carton := range tree.Cartons {
+			var sizes []int
+			for _, pack := range carton.Packs {
+				for n := 0; n < pack.Quantity; n++ {
+					sizes = append(sizes, pack.PackSize)
+				}
+			}
+			cartons = append(cartons, sizes)
+		}
+		return cartons
+	}
+
+	testCases := []struct {
+		assignment string
+		want       [][]int
+	}{
+		{AssignGrouped, [][]int{{1000, 1000, 1000, 500}, {500, 250}}},
+		{AssignRoundRobin, [][]int{{1000, 500, 250, 1000, 500}, {1000}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.assignment, func(t *testing.T) {
+			tree, err := buildPackingTree(result, 3500, tc.assignment)
+			if err != nil {
+				t.Fatalf("buildPackingTree returned error: %v", err)
+			}
+			if got := sizesOf(tree); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s cartons = %v, want %v", tc.assignment, got, tc.want)
+			}
+			again, _ := buildPackingTree(result, 3500, tc.assignment)
+			if !reflect.DeepEqual(tree, again) {
+				t.Errorf("%s assignment is not deterministic", tc.assignment)
+			}
+		})
+	}
+
+	if _, err := buildPackingTree(result, 3500, "random"); err == nil {
+		t.Error("buildPackingTree should reject an unknown assignment")
+	}
+}
+
+func TestOptimizeHandlerTreePackLimit(t *testing.T) {
+	withPackSizes(t, []int{1})
+
+	for _, tc := range []struct {
+		quantity int
+		want     int
+	}{
+		{maxTreePacks, http.StatusOK},
+		{maxTreePacks + 1, http.StatusBadRequest},
+	} {
+		body := fmt.Sprintf(`{"quantity": %d, "masterCartonSize": 1000}`, tc.quantity)
+		req := httptest.NewRequest(http.MethodPost, "/optimize?tree=true", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		optimizeHandler(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("POST /optimize?tree=true for %d packs status = %d, want %d: %s", tc.quantity, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+}
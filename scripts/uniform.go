@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// Uniformity describes how far a plan sticks to one pack size: the size
+// used most and the share of packs, as a percentage, that it makes up.
+type Uniformity struct {
+	DominantSize  int     `json:"dominantSize"`
+	DominantShare float64 `json:"dominantShare"`
+}
+
+// validateUniform checks that ModeUniform is not combined with other
+// preferences between mixes, which would override its choice.
+func validateUniform(opts OptimizeOptions) error {
+	if opts.Mode != ModeUniform {
+		return nil
+	}
+	if opts.PenalizeSmallPacks || opts.PreferEvenCounts {
+		return fmt.Errorf("mode %q cannot be combined with penalizeSmallPacks or preferEvenCounts", ModeUniform)
+	}
+	return nil
+}
+
+// dominantSize returns the index of the size a mix uses most, the larger
+// size on a tie, and its count. counts is aligned with sizes, sorted in
+// descending order.
+func dominantSize(counts []int) (int, int) {
+	dominant := 0
+	for i, qty := range counts {
+		if qty > counts[dominant] {
+			dominant = i
+		}
+	}
+	return dominant, counts[dominant]
+}
+
+// uniformBreakdown searches the totals in [lo, hi] for the mix whose most
+// used size makes up the largest share of its packs, then the least waste,
+// then the fewest packs. It returns -1 when no total in the window is
+// reachable.
+func uniformBreakdown(sizes []int, lo, hi int) (int, map[int]int) {
+	bestAmount, bestPacks, bestDominant := -1, 0, 0
+	var best []int
+
+	enumerateBreakdowns(sizes, lo, hi, maxSearchStates, func(counts []int, total int) {
+		packs := 0
+		for _, qty := range counts {
+			packs += qty
+		}
+		_, dominant := dominantSize(counts)
+
+		// Compare dominant/packs against bestDominant/bestPacks without
+		// dividing.
+		better := bestAmount == -1
+		if !better && dominant*bestPacks != bestDominant*packs {
+			better = dominant*bestPacks > bestDominant*packs
+		} else if !better && total != bestAmount {
+			better = total < bestAmount
+		} else if !better {
+			better = packs < bestPacks
+		}
+		if better {
+			bestAmount, bestPacks, bestDominant = total, packs, dominant
+			best = append(best[:0], counts...)
+		}
+	})
+
+	if bestAmount == -1 {
+		return -1, nil
+	}
+	return bestAmount, countsMap(sizes, best)
+}
+
+// planUniformity reports the dominant size of a plan with counts for sizes.
+func planUniformity(sizes []int, counts map[int]int) *Uniformity {
+	slice := countsSlice(sizes, counts)
+	dominant, qty := dominantSize(slice)
+	return &Uniformity{DominantSize: sizes[dominant], DominantShare: percentage(qty, countPacks(counts))}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestModeUniform(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		opts        OptimizeOptions
+		want        []PackResult
+		wantWaste   int
+		wantUniform Uniformity
+	}{
+		{"single size at equal waste", []int{250, 500, 1000}, 750, OptimizeOptions{Mode: ModeUniform},
+			[]PackResult{{PackSize: 250, Quantity: 3}}, 0, Uniformity{DominantSize: 250, DominantShare: 100}},
+		{"single size at acceptable waste", []int{300, 1000}, 1300, OptimizeOptions{Mode: ModeUniform},
+			[]PackResult{{PackSize: 300, Quantity: 5}}, 200, Uniformity{DominantSize: 300, DominantShare: 100}},
+		{"tolerance too small for a single size", []int{300, 1000}, 1300, OptimizeOptions{Mode: ModeUniform, WasteDelta: 100},
+			[]PackResult{{PackSize: 1000, Quantity: 1}, {PackSize: 300, Quantity: 1}}, 0, Uniformity{DominantSize: 1000, DominantShare: 50}},
+		{"largest share when no single size fits", []int{300, 1000}, 2300, OptimizeOptions{Mode: ModeUniform, WasteDelta: 50},
+			[]PackResult{{PackSize: 1000, Quantity: 2}, {PackSize: 300, Quantity: 1}}, 0, Uniformity{DominantSize: 1000, DominantShare: 66.67}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, Catalog{PackSizes: tc.sizes}, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.want) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.want)
+			}
+			if result.Waste != tc.wantWaste {
+				t.Errorf("waste = %d, want %d", result.Waste, tc.wantWaste)
+			}
+			if result.Uniformity == nil || *result.Uniformity != tc.wantUniform {
+				t.Errorf("uniformity = %+v, want %+v", result.Uniformity, tc.wantUniform)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerModeUniform(t *testing.T) {
+	withPackSizes(t, []int{300, 1000})
+
+	rec := postOptimize(t, `{"quantity": 1300, "mode": "uniform"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Uniformity == nil || result.Uniformity.DominantSize != 300 || result.Uniformity.DominantShare != 100 {
+		t.Errorf("uniformity = %+v, want all 300 packs", result.Uniformity)
+	}
+
+	rec = postOptimize(t, `{"quantity": 1300, "mode": "uniform", "preferEvenCounts": true}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("uniform with preferEvenCounts status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// maxUpgradePath bounds the higher quantities one request may ask plans for.
+const maxUpgradePath = 50
+
+// UpgradeStep is the plan for one quantity above the order, or the reason
+// it could not be optimized. Changed marks a break point: the packs differ
+// from those of the quantity below.
+type UpgradeStep struct {
+	Quantity int                 `json:"quantity"`
+	Result   *OptimizationResult `json:"result,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Changed  bool                `json:"changed"`
+}
+
+// validateUpgradePath checks a request's upgradePath against maxUpgradePath.
+func validateUpgradePath(steps int) error {
+	if steps < 0 || steps > maxUpgradePath {
+		return fmt.Errorf("upgradePath must be between 0 and %d", maxUpgradePath)
+	}
+	return nil
+}
+
+// upgradePath plans the steps quantities following result's order against
+// catalog with opts, all read from one table like OptimizeBatch, so sales
+// can see where the plan breaks to other packs.
+func upgradePath(result *OptimizationResult, catalog Catalog, opts OptimizeOptions, steps int) ([]UpgradeStep, error) {
+	quantities := make([]int, steps)
+	for i := range quantities {
+		quantities[i] = result.OrderQuantity + i + 1
+	}
+	items, err := OptimizeBatch(quantities, catalog, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]UpgradeStep, len(items))
+	previous := result.Packs
+	for i, item := range items {
+		path[i] = UpgradeStep{Quantity: item.Quantity, Result: item.Result, Error: item.Error}
+		var packs []PackResult
+		if item.Result != nil {
+			packs = item.Result.Packs
+		}
+		path[i].Changed = !slices.Equal(packs, previous)
+		previous = packs
+	}
+	return path, nil
+}
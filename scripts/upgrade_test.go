@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestUpgradePath(t *testing.T) {
+	catalog := Catalog{PackSizes: []int{250, 500, 1000, 2000, 5000}}
+	result, err := OptimizeCatalog(249, catalog, OptimizeOptions{})
+	if err != nil {
+		t.Fatalf("OptimizeCatalog(249) returned error: %v", err)
+	}
+
+	path, err := upgradePath(result, catalog, OptimizeOptions{}, 5)
+	if err != nil {
+		t.Fatalf("upgradePath returned error: %v", err)
+	}
+	if len(path) != 5 {
+		t.Fatalf("upgradePath returned %d steps, want 5", len(path))
+	}
+
+	one250 := []PackResult{{PackSize: 250, Quantity: 1}}
+	one500 := []PackResult{{PackSize: 500, Quantity: 1}}
+	want := []struct {
+		quantity int
+		packs    []PackResult
+		waste    int
+		changed  bool
+	}{
+		{250, one250, 0, false},
+		{251, one500, 249, true},
+		{252, one500, 248, false},
+		{253, one500, 247, false},
+		{254, one500, 246, false},
+	}
+	for i, w := range want {
+		step := path[i]
+		if step.Quantity != w.quantity || step.Result == nil {
+			t.Fatalf("step %d = %+v, want quantity %d with a result", i, step, w.quantity)
+		}
+		if !reflect.DeepEqual(step.Result.Packs, w.packs) || step.Result.Waste != w.waste || step.Changed != w.changed {
+			t.Errorf("step %d = %+v waste %d changed %v, want %+v waste %d changed %v",
+				w.quantity, step.Result.Packs, step.Result.Waste, step.Changed, w.packs, w.waste, w.changed)
+		}
+	}
+}
+
+func TestOptimizeHandlerUpgradePath(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postOptimize(t, `{"quantity": 249, "upgradePath": 5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(result.UpgradePath) != 5 || result.UpgradePath[0].Result.Waste != 0 || !result.UpgradePath[1].Changed {
+		t.Errorf("upgradePath = %+v, want an exact fill at 250 and a break at 251", result.UpgradePath)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 249, "upgradePath": 51}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("upgradePath 51 status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// formBody guards the endpoints that read a multipart form upload.
+var formBody = requireContentType("multipart/form-data")
+
+// maxUploadMemory is how much of a multipart upload is buffered in memory
+// before the rest spills to temporary files.
+const maxUploadMemory = 10 << 20
+
+// csvContentType selects the CSV representation of an upload's results.
+const csvContentType = "text/csv"
+
+// UploadItem is the answer for one CSV row. Row is 1-based and counts the
+// header, so it matches the line a spreadsheet shows.
+type UploadItem struct {
+	Row int `json:"row"`
+	BatchItem
+}
+
+// UploadSummary aggregates the rows of an upload that could be optimized.
+type UploadSummary struct {
+	Rows       int `json:"rows"`
+	Failed     int `json:"failed"`
+	TotalItems int `json:"totalItems"`
+	TotalPacks int `json:"totalPacks"`
+	Waste      int `json:"waste"`
+}
+
+// UploadResult is the JSON response of POST /optimize/upload, in file order.
+type UploadResult struct {
+	Results []UploadItem  `json:"results"`
+	Summary UploadSummary `json:"summary"`
+}
+
+// csvRow is one data row of an uploaded CSV: its quantity or why it has none.
+type csvRow struct {
+	row      int
+	quantity int
+	err      string
+}
+
+// readQuantityCSV reads order quantities from a CSV with one quantity per
+// line, or from the "quantity" column when the first line is a header naming
+// one. Spreadsheet exports are accepted as they come: a leading UTF-8 byte
+// order mark is dropped, CRLF line endings are handled by encoding/csv, and
+// blank lines, including ones holding only separators, are skipped. Malformed
+// rows are returned with an error instead of failing the file.
+func readQuantityCSV(r io.Reader) ([]csvRow, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []csvRow
+	column, first := 0, true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, csvRow{row: parseErr.StartLine, err: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if blankRecord(record) {
+			continue
+		}
+
+		if first {
+			first = false
+			if i := headerColumn(record, "quantity"); i >= 0 {
+				column = i
+				continue
+			}
+		}
+		if column >= len(record) {
+			rows = append(rows, csvRow{row: line, err: "missing quantity column"})
+			continue
+		}
+		value := strings.TrimSpace(record[column])
+		quantity, err := strconv.Atoi(value)
+		if err != nil {
+			rows = append(rows, csvRow{row: line, err: fmt.Sprintf("quantity must be an integer, got %q", value)})
+			continue
+		}
+		rows = append(rows, csvRow{row: line, quantity: quantity})
+	}
+	return rows, nil
+}
+
+// utf8BOM is the byte order mark spreadsheet tools put before UTF-8 exports.
+var utf8BOM = []byte("\ufeff")
+
+// blankRecord reports whether every field of record is empty or whitespace.
+func blankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// headerColumn returns the index of the field named name, or -1.
+func headerColumn(record []string, name string) int {
+	for i, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// optimizeUpload optimizes the well-formed rows with one shared table and
+// merges their answers with the malformed rows, in file order.
+func optimizeUpload(rows []csvRow, catalog Catalog, opts OptimizeOptions) (UploadResult, error) {
+	var quantities []int
+	for _, row := range rows {
+		if row.err == "" {
+			quantities = append(quantities, row.quantity)
+		}
+	}
+	var batch []BatchItem
+	if len(quantities) > 0 {
+		var err error
+		if batch, err = OptimizeBatch(quantities, catalog, opts); err != nil {
+			return UploadResult{}, err
+		}
+	}
+
+	upload := UploadResult{Results: make([]UploadItem, len(rows))}
+	upload.Summary.Rows = len(rows)
+	for i, row := range rows {
+		item := UploadItem{Row: row.row, BatchItem: BatchItem{Error: row.err}}
+		if row.err == "" {
+			item.BatchItem, batch = batch[0], batch[1:]
+		}
+		if result := item.Result; result != nil {
+			upload.Summary.TotalItems += result.TotalItems
+			upload.Summary.TotalPacks += result.TotalPacks
+			upload.Summary.Waste += result.Waste
+		} else {
+			upload.Summary.Failed++
+		}
+		upload.Results[i] = item
+	}
+	return upload, nil
+}
+
+// writeUploadCSV writes one line per row, listing packs as "2x5000;1x250".
+func writeUploadCSV(w io.Writer, upload UploadResult) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"row", "quantity", "totalItems", "totalPacks", "waste", "packs", "error"})
+	for _, item := range upload.Results {
+		line := []string{strconv.Itoa(item.Row), "", "", "", "", "", item.Error}
+		if item.Result != nil || item.Quantity != 0 {
+			line[1] = strconv.Itoa(item.Quantity)
+		}
+		if result := item.Result; result != nil {
+			packs := make([]string, len(result.Packs))
+			for i, pack := range result.Packs {
+				packs[i] = fmt.Sprintf("%dx%d", pack.Quantity, pack.PackSize)
+			}
+			line[2] = strconv.Itoa(result.TotalItems)
+			line[3] = strconv.Itoa(result.TotalPacks)
+			line[4] = strconv.Itoa(result.Waste)
+			line[5] = strings.Join(packs, ";")
+		}
+		out.Write(line)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// uploadResponse lists the representations of an upload summary.
+var uploadResponse = produces(jsonContentType, csvContentType)
+
+// HTTP handler for optimizing a CSV of order quantities uploaded as the
+// "file" field of a multipart form
+func uploadHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	mediaType, ok := uploadResponse.negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		writeError(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	rows, err := readQuantityCSV(file)
+	if err != nil {
+		writeError(w, "Failed to read file", http.StatusBadRequest)
+		return
+	}
+	if len(rows) == 0 {
+		writeError(w, "file must contain at least one quantity", http.StatusBadRequest)
+		return
+	}
+	if maxBatchSize > 0 && len(rows) > maxBatchSize {
+		writeError(w, fmt.Sprintf("An upload may hold at most %d rows", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	catalogName := r.FormValue("catalog")
+	if catalogName == "" {
+		catalogName = defaultCatalogName
+	}
+	catalog, ok := namedCatalogs.lookup(catalogName)
+	if !ok {
+		writeError(w, fmt.Sprintf("Unknown catalog %q", catalogName), http.StatusNotFound)
+		return
+	}
+
+	upload, err := optimizeUpload(rows, catalog, catalog.Defaults.apply(OptimizeOptions{
+		Mode:   r.FormValue("mode"),
+		Policy: r.FormValue("policy"),
+
+		TreatZeroAsEmpty: treatZeroAsEmpty,
+		MinOrderQuantity: minOrderQuantity,
+		Context:          r.Context(),
+	}))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if mediaType == csvContentType {
+		w.Header().Set("Content-Type", csvContentType)
+		writeUploadCSV(w, upload)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	json.NewEncoder(w).Encode(upload)
+}
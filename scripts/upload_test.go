@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postUpload sends csvData as the file field of a multipart form to
+// POST /optimize/upload through the router.
+func postUpload(t *testing.T, csvData, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	return postUploadForm(t, csvData, accept, nil)
+}
+
+// postUploadForm is postUpload with extra form fields such as catalog.
+func postUploadForm(t *testing.T, csvData, accept string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	part, err := form.CreateFormFile("file", "orders.csv")
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	part.Write([]byte(csvData))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/optimize/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReadQuantityCSV(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		want        []csvRow
+	}{
+		{"one quantity per line", "250\n501\n", []csvRow{{row: 1, quantity: 250}, {row: 2, quantity: 501}}},
+		{"quantity column", "sku,quantity\nA,250\nB,501\n", []csvRow{{row: 2, quantity: 250}, {row: 3, quantity: 501}}},
+		{"malformed rows", "250\nabc\n\n1.5\n", []csvRow{
+			{row: 1, quantity: 250},
+			{row: 2, err: `quantity must be an integer, got "abc"`},
+			{row: 4, err: `quantity must be an integer, got "1.5"`},
+		}},
+		{"spreadsheet export", "\ufeffquantity,sku\r\n250,A\r\n\r\n,\r\n 501 ,B\r\nx1,C\r\n", []csvRow{
+			{row: 2, quantity: 250},
+			{row: 5, quantity: 501},
+			{row: 6, err: `quantity must be an integer, got "x1"`},
+		}},
+		{"BOM before a bare quantity", "\ufeff250\r\n500\r\n", []csvRow{{row: 1, quantity: 250}, {row: 2, quantity: 500}}},
+		{"header after blank lines", "\r\n,,\r\nQuantity\r\n750\r\n", []csvRow{{row: 4, quantity: 750}}},
+		{"short row", "sku,quantity\nA\n", []csvRow{{row: 2, err: "missing quantity column"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := readQuantityCSV(strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatalf("readQuantityCSV returned error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("readQuantityCSV = %+v, want %+v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("row %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUploadHandler(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postUpload(t, "quantity\n1\n501\nabc\n12001\n", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/upload status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var upload UploadResult
+	if err := json.NewDecoder(rec.Body).Decode(&upload); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	want := UploadSummary{Rows: 4, Failed: 1, TotalItems: 250 + 750 + 12250, TotalPacks: 1 + 2 + 4, Waste: 249 + 249 + 249}
+	if upload.Summary != want {
+		t.Errorf("summary = %+v, want %+v", upload.Summary, want)
+	}
+	if len(upload.Results) != 4 {
+		t.Fatalf("got %d results, want 4", len(upload.Results))
+	}
+	if bad := upload.Results[2]; bad.Row != 4 || bad.Error == "" || bad.Result != nil {
+		t.Errorf("malformed row = %+v, want an error on row 4", bad)
+	}
+	if ok := upload.Results[1]; ok.Row != 3 || ok.Quantity != 501 || ok.Result == nil || ok.Result.TotalItems != 750 {
+		t.Errorf("row for 501 = %+v, want 750 items on row 3", ok)
+	}
+}
+
+func TestUploadHandlerCSV(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	rec := postUpload(t, "501\nabc\n", csvContentType)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize/upload status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != csvContentType {
+		t.Errorf("Content-Type = %q, want %q", got, csvContentType)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV response: %v", err)
+	}
+	want := [][]string{
+		{"row", "quantity", "totalItems", "totalPacks", "waste", "packs", "error"},
+		{"1", "501", "750", "2", "249", "1x500;1x250", ""},
+		{"2", "", "", "", "", "", `quantity must be an integer, got "abc"`},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("CSV response = %v, want %v", records, want)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("CSV line %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestUploadHandlerRejectsBadRequests(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/optimize/upload", strings.NewReader(`{"quantities": [1]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("JSON body status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+
+	if rec := postUpload(t, "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty file status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "context"
+
+// Upsell suggests the quantity a customer could order instead to receive a
+// pack mix with no waste.
+type Upsell struct {
+	Quantity   int `json:"quantity"`
+	Delta      int `json:"delta"`
+	TotalPacks int `json:"totalPacks"`
+}
+
+// suggestUpsell returns the nearest quantity above result's order that
+// catalog fills exactly, found by reachability as in minimumWaste, with the
+// packs needed to fill it. It returns nil when the order already fills
+// exactly.
+func suggestUpsell(ctx context.Context, result *OptimizationResult, catalog Catalog) (*Upsell, error) {
+	sizes := sortedDescending(catalog.PackSizes)
+	delta, err := minimumWaste(ctx, result.OrderQuantity, sizes)
+	if err != nil || delta == 0 {
+		return nil, err
+	}
+
+	// minimumWaste found quantity reachable, so the table's fewest packs
+	// for it fill it exactly.
+	quantity := result.OrderQuantity + delta
+	dp, err := fillPackTable(ctx, sizes, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &Upsell{Quantity: quantity, Delta: delta, TotalPacks: dp[quantity].packs}, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSuggestUpsell(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		want        *Upsell
+	}{
+		{"240 tops up to 250", []int{250, 500}, 240, &Upsell{Quantity: 250, Delta: 10, TotalPacks: 1}},
+		{"exact order needs none", []int{250, 500}, 750, nil},
+		{"501 tops up to 750", []int{250, 500, 1000, 2000, 5000}, 501, &Upsell{Quantity: 750, Delta: 249, TotalPacks: 2}},
+		{"gaps in reachability", []int{300, 700}, 800, &Upsell{Quantity: 900, Delta: 100, TotalPacks: 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			catalog := Catalog{PackSizes: tc.sizes}
+			result, err := OptimizeCatalog(tc.quantity, catalog, OptimizeOptions{})
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			got, err := suggestUpsell(context.Background(), result, catalog)
+			if err != nil {
+				t.Fatalf("suggestUpsell(%d) returned error: %v", tc.quantity, err)
+			}
+			if (got == nil) != (tc.want == nil) || got != nil && *got != *tc.want {
+				t.Errorf("suggestUpsell(%d) = %+v, want %+v", tc.quantity, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerSuggestUpsell(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	rec := postOptimize(t, `{"quantity": 240, "suggestUpsell": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.Upsell == nil || result.Upsell.Quantity != 250 || result.Upsell.Delta != 10 {
+		t.Errorf("POST /optimize 240 upsell = %+v, want +10 to 250", result.Upsell)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// validateVolumes checks that opts gives every size a positive volume when
+// volumes are given or required by the mode.
+func validateVolumes(opts OptimizeOptions, sizes []int) error {
+	if opts.Volumes == nil {
+		if opts.Mode == ModeMinVolume {
+			return fmt.Errorf("mode %q requires volumes", ModeMinVolume)
+		}
+		return nil
+	}
+
+	known := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		known[size] = true
+		volume, ok := opts.Volumes[size]
+		if !ok {
+			return fmt.Errorf("missing volume for pack size %d", size)
+		}
+		if volume <= 0 || math.IsNaN(volume) || math.IsInf(volume, 0) {
+			return fmt.Errorf("volume for pack size %d must be a positive number", size)
+		}
+	}
+	for size := range opts.Volumes {
+		if !known[size] {
+			return fmt.Errorf("volume given for unknown pack size %d", size)
+		}
+	}
+	return nil
+}
+
+// breakdownVolume is the total volume of a breakdown, rounded to shed float
+// noise from summing many packs.
+func breakdownVolume(counts map[int]int, volumes map[int]float64) float64 {
+	total := 0.0
+	for size, qty := range counts {
+		total += float64(qty) * volumes[size]
+	}
+	return math.Round(total*1e6) / 1e6
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOptimizePacksMinVolume(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	volumes := map[int]float64{250: 1, 500: 2.2}
+
+	fewest, err := OptimizePacksWithOptions(500, OptimizeOptions{Volumes: volumes})
+	if err != nil {
+		t.Fatalf("fewestPacks returned error: %v", err)
+	}
+	if want := []PackResult{{PackSize: 500, Quantity: 1}}; !reflect.DeepEqual(fewest.Packs, want) {
+		t.Errorf("fewestPacks packs = %v, want %v", fewest.Packs, want)
+	}
+	if fewest.TotalVolume == nil || *fewest.TotalVolume != 2.2 {
+		t.Errorf("fewestPacks totalVolume = %v, want 2.2", fewest.TotalVolume)
+	}
+
+	dense, err := OptimizePacksWithOptions(500, OptimizeOptions{Mode: ModeMinVolume, Volumes: volumes})
+	if err != nil {
+		t.Fatalf("minVolume returned error: %v", err)
+	}
+	if want := []PackResult{{PackSize: 250, Quantity: 2}}; !reflect.DeepEqual(dense.Packs, want) {
+		t.Errorf("minVolume packs = %v, want %v", dense.Packs, want)
+	}
+	if dense.TotalVolume == nil || *dense.TotalVolume != 2 {
+		t.Errorf("minVolume totalVolume = %v, want 2", dense.TotalVolume)
+	}
+}
+
+func TestValidateVolumes(t *testing.T) {
+	sizes := []int{250, 500}
+	testCases := []struct {
+		description string
+		opts        OptimizeOptions
+		wantErr     bool
+	}{
+		{"no volumes", OptimizeOptions{}, false},
+		{"every size", OptimizeOptions{Volumes: map[int]float64{250: 1, 500: 2}}, false},
+		{"mode without volumes", OptimizeOptions{Mode: ModeMinVolume}, true},
+		{"missing size", OptimizeOptions{Volumes: map[int]float64{250: 1}}, true},
+		{"unknown size", OptimizeOptions{Volumes: map[int]float64{250: 1, 500: 2, 1000: 4}}, true},
+		{"zero volume", OptimizeOptions{Volumes: map[int]float64{250: 0, 500: 2}}, true},
+		{"minVolume with maxPacks", OptimizeOptions{Mode: ModeMinVolume, Volumes: map[int]float64{250: 1, 500: 2}, MaxPacks: 2}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := validateOptions(Catalog{PackSizes: sizes}, tc.opts)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validateOptions error = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerMinVolume(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	rec := postOptimize(t, `{"quantity": 500, "mode": "minVolume", "volumes": {"250": 1, "500": 2.2}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result OptimizationResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if result.TotalVolume == nil || *result.TotalVolume != 2 || result.TotalPacks != 2 {
+		t.Errorf("result = %d packs with totalVolume %v, want 2 packs with 2", result.TotalPacks, result.TotalVolume)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 500, "mode": "minVolume"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("minVolume without volumes status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import "fmt"
+
+// validateWasteCaps checks that opts.MaxWaste is not negative and that
+// opts.MaxWastePerSize only caps known sizes with non-negative limits.
+func validateWasteCaps(opts OptimizeOptions, sizes []int) error {
+	if opts.MaxWaste < 0 {
+		return fmt.Errorf("maxWaste must not be negative")
+	}
+	if opts.MaxWastePerSize == nil {
+		return nil
+	}
+	if perPackMode(opts.Mode) {
+		return fmt.Errorf("maxWastePerSize is not supported with mode %q", opts.Mode)
+	}
+	known := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		known[size] = true
+	}
+	for size, limit := range opts.MaxWastePerSize {
+		if !known[size] {
+			return fmt.Errorf("maxWastePerSize given for unknown pack size %d", size)
+		}
+		if limit < 0 {
+			return fmt.Errorf("maxWastePerSize for pack size %d must not be negative", size)
+		}
+	}
+	return nil
+}
+
+// limitWaste folds opts.MaxWaste into opts.MaxQuantity for an order of
+// orderQuantity, so no total more than MaxWaste above the order is
+// considered.
+func limitWaste(orderQuantity int, opts OptimizeOptions) OptimizeOptions {
+	if opts.MaxWaste > 0 {
+		limit := orderQuantity + opts.MaxWaste
+		if opts.MaxQuantity == 0 || limit < opts.MaxQuantity {
+			opts.MaxQuantity = limit
+			opts.wasteLimited = true
+		}
+	}
+	return opts
+}
+
+// attributeWaste splits the waste of a mix over its sizes. Packs are filled
+// largest first, so the empty space is left in the smallest packs used: each
+// pack, smallest first, absorbs up to its own size. counts and the returned
+// slice are aligned with sizes, which must be sorted in descending order.
+func attributeWaste(sizes, counts []int, waste int) []int {
+	attributed := make([]int, len(sizes))
+	for i := len(sizes) - 1; i >= 0 && waste > 0; i-- {
+		absorbed := min(counts[i]*sizes[i], waste)
+		attributed[i] = absorbed
+		waste -= absorbed
+	}
+	return attributed
+}
+
+// withinWasteCaps reports whether no size of the mix is attributed more
+// waste than caps allows.
+func withinWasteCaps(sizes, counts []int, waste int, caps map[int]int) bool {
+	for i, attributed := range attributeWaste(sizes, counts, waste) {
+		if limit, ok := caps[sizes[i]]; ok && attributed > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// cappedWasteBreakdown searches the totals in [lo, hi] for the mix with the
+// least waste, then the fewest packs, that respects caps and, when maxPacks
+// is positive, uses at most maxPacks packs. It returns -1 when no mix in the
+// window qualifies.
+func cappedWasteBreakdown(sizes []int, orderQuantity, lo, hi int, caps map[int]int, maxPacks int) (int, map[int]int) {
+	bestAmount, bestPacks := -1, 0
+	var best []int
+
+	enumerateBreakdowns(sizes, lo, hi, maxSearchStates, func(counts []int, total int) {
+		if bestAmount != -1 && total > bestAmount {
+			return
+		}
+		waste := max(total-orderQuantity, 0)
+		if !withinWasteCaps(sizes, counts, waste, caps) {
+			return
+		}
+		packs := 0
+		for _, qty := range counts {
+			packs += qty
+		}
+		if maxPacks > 0 && packs > maxPacks {
+			return
+		}
+		if bestAmount == -1 || total < bestAmount || packs < bestPacks {
+			bestAmount, bestPacks = total, packs
+			best = append(best[:0], counts...)
+		}
+	})
+
+	if bestAmount == -1 {
+		return -1, nil
+	}
+	return bestAmount, countsMap(sizes, best)
+}
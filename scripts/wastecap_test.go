@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAttributeWaste(t *testing.T) {
+	sizes := []int{5000, 2000, 1000}
+
+	testCases := []struct {
+		description string
+		counts      []int
+		waste       int
+		want        []int
+	}{
+		{"single large pack", []int{1, 0, 0}, 200, []int{200, 0, 0}},
+		{"smallest pack absorbs", []int{0, 2, 1}, 200, []int{0, 0, 200}},
+		{"spills to the next size", []int{0, 1, 1}, 1500, []int{0, 500, 1000}},
+		{"no waste", []int{1, 1, 0}, 0, []int{0, 0, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := attributeWaste(sizes, tc.counts, tc.waste); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("attributeWaste(%v, %d) = %v, want %v", tc.counts, tc.waste, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMaxWastePerSizeAvoidsLargeBoxOvershoot(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000, 2000, 5000})
+
+	base, err := OptimizePacks(4800)
+	if err != nil {
+		t.Fatalf("OptimizePacks(4800) returned error: %v", err)
+	}
+	if want := []PackResult{{PackSize: 5000, Quantity: 1}}; !reflect.DeepEqual(base.Packs, want) {
+		t.Fatalf("OptimizePacks(4800) = %+v, want %+v", base.Packs, want)
+	}
+
+	capped, err := OptimizePacksWithOptions(4800, OptimizeOptions{MaxWastePerSize: map[int]int{5000: 0}})
+	if err != nil {
+		t.Fatalf("OptimizePacksWithOptions(4800, cap) returned error: %v", err)
+	}
+	want := []PackResult{{PackSize: 2000, Quantity: 2}, {PackSize: 1000, Quantity: 1}}
+	if !reflect.DeepEqual(capped.Packs, want) || capped.Waste != 200 {
+		t.Errorf("capped 4800 = %+v (waste %d), want %+v (waste 200)", capped.Packs, capped.Waste, want)
+	}
+
+	exact, err := OptimizePacksWithOptions(5000, OptimizeOptions{MaxWastePerSize: map[int]int{5000: 0}})
+	if err != nil || !reflect.DeepEqual(exact.Packs, []PackResult{{PackSize: 5000, Quantity: 1}}) {
+		t.Errorf("capped 5000 = %+v, %v, want the exact 5000 pack", exact, err)
+	}
+}
+
+func TestMaxWastePerSizeInfeasibleAndInvalid(t *testing.T) {
+	withPackSizes(t, []int{500})
+
+	_, err := OptimizePacksWithOptions(499, OptimizeOptions{MaxWastePerSize: map[int]int{500: 0}})
+	if !errors.Is(err, ErrInfeasible) {
+		t.Errorf("capping the only size = %v, want ErrInfeasible", err)
+	}
+
+	for _, caps := range []map[int]int{{250: 0}, {500: -1}} {
+		if _, err := OptimizePacksWithOptions(499, OptimizeOptions{MaxWastePerSize: caps}); err == nil || errors.Is(err, ErrInfeasible) {
+			t.Errorf("OptimizePacksWithOptions with caps %v error = %v, want a validation error", caps, err)
+		}
+	}
+}
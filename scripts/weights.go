@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ScoreWeights trades waste against pack count: a solution scores
+// Waste·waste^p + Packs·packs, where p is the waste penalty exponent, and the
+// lowest score wins.
+type ScoreWeights struct {
+	Waste float64 `json:"waste"`
+	Packs float64 `json:"packs"`
+}
+
+// score is the weighted score of a solution leaving waste over the order in
+// packs packs, with waste raised to exponent; zero means linear.
+func (sw ScoreWeights) score(waste, packs int, exponent float64) float64 {
+	penalty := float64(waste)
+	if exponent != 0 && exponent != 1 {
+		penalty = math.Pow(penalty, exponent)
+	}
+	return sw.Waste*penalty + sw.Packs*float64(packs)
+}
+
+// wastePenalty is the wastePenalty field of a request: "linear",
+// "quadratic", or the exponent itself as a number. It decodes to the
+// exponent.
+type wastePenalty float64
+
+func (p *wastePenalty) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		switch name {
+		case "linear":
+			*p = 1
+		case "quadratic":
+			*p = 2
+		default:
+			return &fieldError{fmt.Sprintf("unknown wastePenalty %q, want \"linear\", \"quadratic\" or an exponent", name)}
+		}
+		return nil
+	}
+	var exponent float64
+	if err := json.Unmarshal(data, &exponent); err != nil {
+		return &fieldError{"wastePenalty must be \"linear\", \"quadratic\" or an exponent"}
+	}
+	*p = wastePenalty(exponent)
+	return nil
+}
+
+// validateWeights checks that opts.Weights holds finite, non-negative
+// weights and is used with an objective it can rank.
+func validateWeights(opts OptimizeOptions) error {
+	if opts.Weights == nil {
+		// Without weights the lowest waste always wins, and any penalty
+		// growing with waste keeps the same winner.
+		if opts.WastePenalty != 0 {
+			return fmt.Errorf("wastePenalty requires weights")
+		}
+		return nil
+	}
+	if perPackMode(opts.Mode) {
+		return fmt.Errorf("weights are not supported with mode %q", opts.Mode)
+	}
+	for name, weight := range map[string]float64{"waste": opts.Weights.Waste, "packs": opts.Weights.Packs} {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return fmt.Errorf("weight for %s must be a non-negative number", name)
+		}
+	}
+	if opts.WastePenalty < 0 || math.IsInf(opts.WastePenalty, 0) || math.IsNaN(opts.WastePenalty) {
+		return fmt.Errorf("wastePenalty exponent must be a positive number")
+	}
+	return nil
+}
+
+// weightedAmount returns the reachable total in [lo, hi] whose fewest-pack
+// mix minimizes the weighted score for orderQuantity, with waste raised to
+// exponent, preferring less waste on ties. lo must be reachable. Totals
+// beyond the search window never win: dropping a pack from such a mix lowers
+// both waste and packs.
+func weightedAmount(dp []dpEntry, orderQuantity, lo, hi int, weights ScoreWeights, exponent float64) int {
+	best, bestScore := lo, 0.0
+	for total := lo; total <= hi && total < len(dp); total++ {
+		if dp[total].packs == math.MaxInt32 {
+			continue
+		}
+		score := weights.score(total-orderQuantity, dp[total].packs, exponent)
+		if total == lo || score < bestScore {
+			best, bestScore = total, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWeightsSelectDifferentSolutions(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+
+	testCases := []struct {
+		description string
+		weights     ScoreWeights
+		wantPacks   []PackResult
+		wantScore   float64
+	}{
+		{"waste dominates", ScoreWeights{Waste: 1, Packs: 10}, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 269},
+		{"packs dominate", ScoreWeights{Waste: 1, Packs: 1000}, []PackResult{{PackSize: 1000, Quantity: 1}}, 1499},
+		{"waste only", ScoreWeights{Waste: 1}, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 249},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			weights := tc.weights
+			result, err := OptimizePacksWithOptions(501, OptimizeOptions{Weights: &weights})
+			if err != nil {
+				t.Fatalf("OptimizePacksWithOptions(501, %+v) returned error: %v", tc.weights, err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.wantPacks)
+			}
+			if result.Score == nil || *result.Score != tc.wantScore {
+				t.Errorf("score = %v, want %v", result.Score, tc.wantScore)
+			}
+		})
+	}
+}
+
+func TestWeightsValidation(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+
+	if rec := postOptimize(t, `{"quantity": 501, "weights": {"waste": -1, "packs": 1}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative weight status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := postOptimize(t, `{"quantity": 501, "weights": {"waste": 1}, "mode": "cheapest", "costs": {"250": 1, "500": 2}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("weights with cheapest status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	result, err := OptimizePacks(501)
+	if err != nil || result.Score != nil {
+		t.Errorf("OptimizePacks(501) score = %v, %v, want none without weights", result.Score, err)
+	}
+}
+
+func TestWastePenalty(t *testing.T) {
+	withPackSizes(t, []int{250, 500, 1000})
+
+	// For 501 with a heavy pack weight, one 1000 pack (waste 499) beats
+	// 500+250 (waste 249) linearly, 499+1000 against 249+2000; squaring the
+	// waste, 249001+1000 against 62001+2000, reverses the choice.
+	testCases := []struct {
+		description string
+		body        string
+		wantPacks   []PackResult
+		wantScore   float64
+	}{
+		{"linear by default", `{"quantity": 501, "weights": {"waste": 1, "packs": 1000}}`, []PackResult{{PackSize: 1000, Quantity: 1}}, 1499},
+		{"linear by name", `{"quantity": 501, "weights": {"waste": 1, "packs": 1000}, "wastePenalty": "linear"}`, []PackResult{{PackSize: 1000, Quantity: 1}}, 1499},
+		{"quadratic prefers less waste over fewer packs", `{"quantity": 501, "weights": {"waste": 1, "packs": 1000}, "wastePenalty": "quadratic"}`, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 64001},
+		{"exponent as a number", `{"quantity": 501, "weights": {"waste": 1, "packs": 1000}, "wastePenalty": 2}`, []PackResult{{PackSize: 500, Quantity: 1}, {PackSize: 250, Quantity: 1}}, 64001},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := postOptimize(t, tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /optimize %s status = %d: %s", tc.body, rec.Code, rec.Body.String())
+			}
+			var result OptimizationResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !reflect.DeepEqual(result.Packs, tc.wantPacks) {
+				t.Errorf("packs = %+v, want %+v", result.Packs, tc.wantPacks)
+			}
+			if result.Score == nil || *result.Score != tc.wantScore {
+				t.Errorf("score = %v, want %v", result.Score, tc.wantScore)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		`{"quantity": 501, "wastePenalty": "quadratic"}`,
+		`{"quantity": 501, "weights": {"waste": 1}, "wastePenalty": "cubic"}`,
+		`{"quantity": 501, "weights": {"waste": 1}, "wastePenalty": -2}`,
+		`{"quantity": 501, "weights": {"waste": 1}, "wastePenalty": true}`,
+	} {
+		if rec := postOptimize(t, bad); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /optimize %s status = %d, want %d", bad, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
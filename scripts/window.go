@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// WindowDiagnostics reports where the chosen total landed in the search
+// window: WindowOffset items above the order (negative for an underfill), in
+// a window topping out at WindowMax. WindowEdge is set when the near-optimal
+// totals, up to the waste delta above the chosen one, run past WindowMax, a
+// hint that the window may be too small and searchMargin should grow.
+type WindowDiagnostics struct {
+	WindowOffset int  `json:"windowOffset"`
+	WindowMax    int  `json:"windowMax"`
+	WindowEdge   bool `json:"windowEdge"`
+}
+
+// windowDiagnostics locates bestAmount in the search window of
+// orderQuantity. The window top saturates instead of overflowing, since
+// single-size catalogs are solved without computing it.
+func windowDiagnostics(orderQuantity, bestAmount int, sizes []int, opts OptimizeOptions) *WindowDiagnostics {
+	top := math.MaxInt
+	if orderQuantity <= math.MaxInt-sizes[0]-opts.SearchMargin {
+		top = orderQuantity + sizes[0] + opts.SearchMargin
+	}
+	return &WindowDiagnostics{
+		WindowOffset: bestAmount - orderQuantity,
+		WindowMax:    top,
+		WindowEdge:   bestAmount > top-wasteDelta(opts, sizes),
+	}
+}
+
+// windowEdgeWarning suggests widening a window whose top cuts off the
+// near-optimal totals.
+func windowEdgeWarning(window *WindowDiagnostics) string {
+	return fmt.Sprintf("The chosen total lies %d items above the order, near the search window's top of %d; raise searchMargin to search past it", window.WindowOffset, window.WindowMax)
+}
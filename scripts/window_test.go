@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchMarginCannotLowerWaste(t *testing.T) {
+	// Coprime catalogs leave large gaps between reachable totals, but the
+	// default window already reaches the next reachable total above any
+	// order, so widening it changes neither the waste nor the breakdown.
+	withPackSizes(t, []int{2300, 3700})
+
+	for _, quantity := range []int{1, 2301, 5000, 6001, 11111} {
+		base, err := OptimizePacks(quantity)
+		if err != nil {
+			t.Fatalf("OptimizePacks(%d) returned error: %v", quantity, err)
+		}
+		wide, err := OptimizePacksWithOptions(quantity, OptimizeOptions{SearchMargin: 50000})
+		if err != nil {
+			t.Fatalf("OptimizePacksWithOptions(%d, margin 50000) returned error: %v", quantity, err)
+		}
+		if !reflect.DeepEqual(base, wide) {
+			t.Errorf("margin changed the result for %d: %+v vs %+v", quantity, base, wide)
+		}
+	}
+}
+
+func TestSearchWindowOverflow(t *testing.T) {
+	withPackSizes(t, []int{250, math.MaxInt / 2})
+
+	testCases := []struct {
+		description string
+		quantity    int
+		opts        OptimizeOptions
+	}{
+		{"order plus largest pack", math.MaxInt - 100, OptimizeOptions{}},
+		{"order plus search margin", math.MaxInt / 2, OptimizeOptions{SearchMargin: math.MaxInt / 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := OptimizePacksWithOptions(tc.quantity, tc.opts)
+			if !errors.Is(err, ErrWindowTooLarge) {
+				t.Errorf("OptimizePacksWithOptions(%d) error = %v, want ErrWindowTooLarge", tc.quantity, err)
+			}
+		})
+	}
+
+	if rec := postOptimize(t, fmt.Sprintf(`{"quantity": %d}`, math.MaxInt-100)); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /optimize near math.MaxInt status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSearchMarginBoundedByMemoryCeiling(t *testing.T) {
+	withPackSizes(t, []int{250, 500})
+	saved := maxDPSize
+	maxDPSize = 10000
+	t.Cleanup(func() { maxDPSize = saved })
+
+	if _, err := OptimizePacksWithOptions(1000, OptimizeOptions{SearchMargin: 8000}); err != nil {
+		t.Errorf("margin within the ceiling returned error: %v", err)
+	}
+	_, err := OptimizePacksWithOptions(1000, OptimizeOptions{SearchMargin: 9000})
+	if !errors.Is(err, ErrWindowTooLarge) {
+		t.Errorf("margin above the ceiling error = %v, want ErrWindowTooLarge", err)
+	}
+
+	if rec := postOptimize(t, `{"quantity": 1000, "searchMargin": 9000}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /optimize above the ceiling status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := postOptimize(t, `{"quantity": 1000, "searchMargin": -1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /optimize with negative margin status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTinyPackSizeTableGuard(t *testing.T) {
+	withPackSizes(t, []int{1, 7})
+	savedCeiling, savedThreshold, savedLog := maxDPSize, largeTableThreshold, telemetryLog
+	t.Cleanup(func() { maxDPSize, largeTableThreshold, telemetryLog = savedCeiling, savedThreshold, savedLog })
+	maxDPSize, largeTableThreshold = 100_000, 10_000
+	telemetryLog = slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	testCases := []struct {
+		description string
+		quantity    int
+		wantWarning bool
+		wantErr     bool
+	}{
+		// The window is the quantity plus the largest pack, 7.
+		{"small table", 9_993, false, false},
+		{"large table is reported", 10_000, true, false},
+		{"at the ceiling", 99_993, true, false},
+		{"above the ceiling is rejected", 99_994, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizePacks(tc.quantity)
+			if tc.wantErr {
+				if !errors.Is(err, ErrWindowTooLarge) || !strings.Contains(err.Error(), "order 99994 needs a 100001-item search table") {
+					t.Errorf("OptimizePacks(%d) error = %v, want ErrWindowTooLarge naming the table size", tc.quantity, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OptimizePacks(%d) returned error: %v", tc.quantity, err)
+			}
+			if result.TotalItems != tc.quantity {
+				t.Errorf("OptimizePacks(%d) = %d items, want the exact order", tc.quantity, result.TotalItems)
+			}
+			if got := len(result.Warnings) > 0; got != tc.wantWarning {
+				t.Errorf("OptimizePacks(%d) warnings = %q, want a large-table warning: %t", tc.quantity, result.Warnings, tc.wantWarning)
+			}
+		})
+	}
+
+	rec := postOptimize(t, `{"quantity": 1000000}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "MAX_DP_SIZE") {
+		t.Errorf("POST /optimize above the ceiling = %d %s, want 400 pointing at MAX_DP_SIZE", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWindowDiagnostics(t *testing.T) {
+	testCases := []struct {
+		description string
+		sizes       []int
+		quantity    int
+		opts        OptimizeOptions
+		want        WindowDiagnostics
+	}{
+		{"dense catalog", []int{250, 500, 1000, 2000, 5000}, 1, OptimizeOptions{Diagnostics: true},
+			WindowDiagnostics{WindowOffset: 249, WindowMax: 5001, WindowEdge: false}},
+		{"sparse catalog at the edge", []int{900, 1000}, 1, OptimizeOptions{Diagnostics: true},
+			WindowDiagnostics{WindowOffset: 899, WindowMax: 1001, WindowEdge: true}},
+		{"margin clears the edge", []int{900, 1000}, 1, OptimizeOptions{Diagnostics: true, SearchMargin: 1000},
+			WindowDiagnostics{WindowOffset: 899, WindowMax: 2001, WindowEdge: false}},
+		{"underfill", []int{250, 500}, 260, OptimizeOptions{Diagnostics: true, Policy: PolicyAllowUnderfill},
+			WindowDiagnostics{WindowOffset: -10, WindowMax: 760, WindowEdge: false}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := OptimizeCatalog(tc.quantity, Catalog{PackSizes: tc.sizes}, tc.opts)
+			if err != nil {
+				t.Fatalf("OptimizeCatalog(%d) returned error: %v", tc.quantity, err)
+			}
+			if result.Diagnostics == nil || result.Diagnostics.WindowDiagnostics == nil {
+				t.Fatalf("diagnostics = %+v, want window diagnostics", result.Diagnostics)
+			}
+			if got := *result.Diagnostics.WindowDiagnostics; got != tc.want {
+				t.Errorf("window diagnostics = %+v, want %+v", got, tc.want)
+			}
+			if hasWarning := len(result.Warnings) > 0; hasWarning != tc.want.WindowEdge {
+				t.Errorf("warnings = %v, want a searchMargin hint %v", result.Warnings, tc.want.WindowEdge)
+			}
+		})
+	}
+}
+
+func TestOptimizeHandlerWindowDiagnostics(t *testing.T) {
+	withPackSizes(t, []int{900, 1000})
+
+	rec := postOptimize(t, `{"quantity": 1, "diagnostics": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /optimize status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Diagnostics map[string]any `json:"diagnostics"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Diagnostics["windowEdge"] != true || body.Diagnostics["windowOffset"] != float64(899) {
+		t.Errorf("diagnostics = %v, want windowEdge at offset 899", body.Diagnostics)
+	}
+	if _, ok := body.Diagnostics["seed"]; ok {
+		t.Errorf("diagnostics = %v, want no seed without spreadTies", body.Diagnostics)
+	}
+}